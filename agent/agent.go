@@ -39,11 +39,12 @@ type Agent struct {
 	Machine  machine.Machine
 	ttl      time.Duration
 
-	cache *agentCache
+	cache  *agentCache
+	health *healthChecker
 }
 
 func New(mgr unit.UnitManager, uGen *unit.UnitStateGenerator, reg registry.Registry, mach machine.Machine, ttl time.Duration) *Agent {
-	return &Agent{reg, mgr, uGen, mach, ttl, newAgentCache()}
+	return &Agent{reg, mgr, uGen, mach, ttl, newAgentCache(), newHealthChecker()}
 }
 
 func (a *Agent) MarshalJSON() ([]byte, error) {
@@ -90,6 +91,18 @@ func (a *Agent) heartbeatJobs(ttl time.Duration, stop <-chan struct{}) {
 	}
 }
 
+// CheckHealth runs the health checks of the units the Agent has started
+// until the stop channel is closed.
+func (a *Agent) CheckHealth(stop <-chan struct{}) {
+	a.health.run(stop)
+}
+
+// Healthy returns the health of the given unit as determined by its health
+// check, nil if it has none or if its result is not known yet.
+func (a *Agent) Healthy(name string) *bool {
+	return a.health.healthy(name)
+}
+
 func (a *Agent) reloadUnitFiles() error {
 	return a.um.ReloadUnitFiles()
 }
@@ -97,6 +110,7 @@ func (a *Agent) reloadUnitFiles() error {
 func (a *Agent) loadUnit(u *job.Unit) error {
 	a.cache.setTargetState(u.Name, job.JobStateLoaded)
 	a.uGen.Subscribe(u.Name)
+	a.health.watch(u)
 	return a.um.Load(u.Name, u.Unit)
 }
 
@@ -112,6 +126,7 @@ func (a *Agent) unloadUnit(unitName string) error {
 	}
 
 	a.uGen.Unsubscribe(unitName)
+	a.health.forget(unitName)
 
 	// unit should be unloaded and unit file should be removed, only if the unit
 	// could be successfully stopped. Otherwise the unit could get into a state
@@ -130,6 +145,7 @@ func (a *Agent) startUnit(unitName string) error {
 
 	machID := a.Machine.State().ID
 	a.registry.UnitHeartbeat(unitName, machID, a.ttl)
+	a.health.start(unitName)
 
 	return a.um.TriggerStart(unitName)
 }
@@ -137,6 +153,7 @@ func (a *Agent) startUnit(unitName string) error {
 func (a *Agent) stopUnit(unitName string) error {
 	a.cache.setTargetState(unitName, job.JobStateLoaded)
 	a.registry.ClearUnitHeartbeat(unitName)
+	a.health.stop(unitName)
 
 	return a.um.TriggerStop(unitName)
 }
@@ -0,0 +1,279 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/log"
+)
+
+// healthCheckTick is the time between two looks for health checks due
+const healthCheckTick = time.Second
+
+// healthChecker runs the health checks of the units an Agent has started,
+// and keeps track of their results.
+type healthChecker struct {
+	mu     sync.Mutex
+	checks map[string]*unitHealth
+
+	probe func(hc job.HealthCheck) error
+	clock clockwork.Clock
+}
+
+// unitHealth is the state of the health check of a unit.
+type unitHealth struct {
+	name  string
+	check job.HealthCheck
+
+	// started tells whether the unit is started, its health being only
+	// checked then
+	started bool
+	// next is when the check is due, running whether it currently runs
+	next    time.Time
+	running bool
+
+	// failures is the number of consecutive failed checks
+	failures int
+	healthy  *bool
+}
+
+func newHealthChecker() *healthChecker {
+	return &healthChecker{
+		checks: make(map[string]*unitHealth),
+		probe:  probeHealth,
+		clock:  clockwork.NewRealClock(),
+	}
+}
+
+// watch prepares checking the health of the given unit once it is started,
+// if it has a health check. Any previous check of the unit is dropped.
+func (hc *healthChecker) watch(u *job.Unit) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	delete(hc.checks, u.Name)
+	if check, ok := u.HealthCheck(); ok {
+		hc.checks[u.Name] = &unitHealth{name: u.Name, check: *check}
+	}
+}
+
+// forget stops checking the health of the given unit.
+func (hc *healthChecker) forget(name string) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	delete(hc.checks, name)
+}
+
+// start starts checking the health of the given unit, the first check
+// being due after one interval to let the unit start.
+func (hc *healthChecker) start(name string) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	uh, ok := hc.checks[name]
+	if !ok || uh.started {
+		return
+	}
+	uh.started = true
+	uh.next = hc.clock.Now().Add(uh.check.Interval)
+}
+
+// stop stops checking the health of the given unit, until it is started
+// again, and forgets the previous results.
+func (hc *healthChecker) stop(name string) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	if uh, ok := hc.checks[name]; ok {
+		hc.checks[name] = &unitHealth{name: name, check: uh.check}
+	}
+}
+
+// healthy returns the health of the given unit, nil if it has no health
+// check or if the check did not conclude yet.
+func (hc *healthChecker) healthy(name string) *bool {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	uh, ok := hc.checks[name]
+	if !ok || uh.healthy == nil {
+		return nil
+	}
+	healthy := *uh.healthy
+	return &healthy
+}
+
+// due returns the checks which should run at the given time, marking them
+// as running.
+func (hc *healthChecker) due(now time.Time) []*unitHealth {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	var due []*unitHealth
+	for _, uh := range hc.checks {
+		if !uh.started || uh.running || now.Before(uh.next) {
+			continue
+		}
+		uh.running = true
+		due = append(due, uh)
+	}
+	return due
+}
+
+// record records the result of the given check, ignoring it if the check
+// was dropped or reset in the meantime.
+func (hc *healthChecker) record(uh *unitHealth, err error) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	if hc.checks[uh.name] != uh {
+		return
+	}
+	uh.running = false
+	uh.next = hc.clock.Now().Add(uh.check.Interval)
+
+	var healthy bool
+	if err == nil {
+		uh.failures = 0
+		healthy = true
+	} else {
+		uh.failures++
+		log.Debugf("Health check of Unit(%s) failed %d time(s): %v", uh.name, uh.failures, err)
+		if uh.failures < uh.check.Threshold {
+			return
+		}
+	}
+
+	if uh.healthy == nil || *uh.healthy != healthy {
+		if healthy {
+			log.Infof("Unit(%s) is healthy", uh.name)
+		} else {
+			log.Infof("Unit(%s) is unhealthy after %d failed health checks: %v", uh.name, uh.failures, err)
+		}
+	}
+	uh.healthy = &healthy
+}
+
+// run runs the health checks as they are due until the stop channel is
+// closed.
+func (hc *healthChecker) run(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-hc.clock.After(healthCheckTick):
+			for _, uh := range hc.due(hc.clock.Now()) {
+				go func(uh *unitHealth) {
+					hc.record(uh, hc.probe(uh.check))
+				}(uh)
+			}
+		}
+	}
+}
+
+// healthCheckCredential returns the credential an exec health check runs
+// with, so that it does not get more privileges than the service it checks:
+// the User and Group of the service, given by name or numeric ID, and no
+// supplementary group. Nil is returned for services running as root.
+func healthCheckCredential(hc job.HealthCheck) (*syscall.Credential, error) {
+	if hc.User == "" && hc.Group == "" {
+		return nil, nil
+	}
+
+	cred := &syscall.Credential{Groups: []uint32{}}
+	if hc.User != "" {
+		u, err := user.Lookup(hc.User)
+		if err != nil {
+			if u, err = user.LookupId(hc.User); err != nil {
+				return nil, fmt.Errorf("unable to resolve User %q of health check: %v", hc.User, err)
+			}
+		}
+		uid, err := strconv.ParseUint(u.Uid, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		gid, err := strconv.ParseUint(u.Gid, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		cred.Uid, cred.Gid = uint32(uid), uint32(gid)
+	}
+	if hc.Group != "" {
+		g, err := user.LookupGroup(hc.Group)
+		if err != nil {
+			if g, err = user.LookupGroupId(hc.Group); err != nil {
+				return nil, fmt.Errorf("unable to resolve Group %q of health check: %v", hc.Group, err)
+			}
+		}
+		gid, err := strconv.ParseUint(g.Gid, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		cred.Gid = uint32(gid)
+	}
+	return cred, nil
+}
+
+// probeHealth runs the given health check once, returning an error if the
+// unit is not healthy. Each check times out after its interval.
+func probeHealth(hc job.HealthCheck) error {
+	switch hc.Type {
+	case job.HealthCheckExec:
+		cred, err := healthCheckCredential(hc)
+		if err != nil {
+			return err
+		}
+		cmd := exec.Command("/bin/sh", "-c", hc.Target)
+		if cred != nil {
+			cmd.SysProcAttr = &syscall.SysProcAttr{Credential: cred}
+		}
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		timer := time.AfterFunc(hc.Interval, func() { cmd.Process.Kill() })
+		defer timer.Stop()
+		return cmd.Wait()
+	case job.HealthCheckHTTP:
+		client := http.Client{Timeout: hc.Interval}
+		resp, err := client.Get(hc.Target)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return nil
+	case job.HealthCheckTCP:
+		conn, err := net.DialTimeout("tcp", hc.Target, hc.Interval)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	return fmt.Errorf("unknown health check type %q", hc.Type)
+}
@@ -0,0 +1,184 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/user"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/cea-hpc/fleet/job"
+)
+
+func TestHealthChecker(t *testing.T) {
+	hc := newHealthChecker()
+	fclock := clockwork.NewFakeClock()
+	hc.clock = fclock
+
+	assertHealth := func(desc string, want *bool) {
+		got := hc.healthy("foo.service")
+		if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+			t.Errorf("%s: unexpected health %v, want %v", desc, got, want)
+		}
+	}
+	assertDue := func(desc string, want int) []*unitHealth {
+		due := hc.due(fclock.Now())
+		if len(due) != want {
+			t.Fatalf("%s: expected %d checks due, got %d", desc, want, len(due))
+		}
+		return due
+	}
+	healthy, unhealthy := true, false
+
+	// units without health check are ignored
+	hc.watch(newTestUnitFromUnitContents(t, "bar.service", ""))
+	hc.start("bar.service")
+
+	hc.watch(newTestUnitFromUnitContents(t, "foo.service", "[X-Fleet]\nHealthCheck=tcp localhost:22\nHealthCheckInterval=10s\nHealthCheckThreshold=2\n"))
+	fclock.Advance(time.Minute)
+	assertDue("unit not started", 0)
+
+	// the first check is due one interval after the unit started
+	hc.start("foo.service")
+	assertDue("unit starting", 0)
+	fclock.Advance(10 * time.Second)
+	due := assertDue("unit started", 1)
+	assertDue("check running", 0)
+
+	// the unit is unhealthy after as many failed checks as the threshold
+	hc.record(due[0], errors.New("connection refused"))
+	assertHealth("one failed check", nil)
+	fclock.Advance(10 * time.Second)
+	due = assertDue("second check", 1)
+	hc.record(due[0], errors.New("connection refused"))
+	assertHealth("two failed checks", &unhealthy)
+
+	// and healthy after a successful one
+	fclock.Advance(10 * time.Second)
+	due = assertDue("third check", 1)
+	hc.record(due[0], nil)
+	assertHealth("successful check", &healthy)
+
+	// stopping the unit forgets about its health, and results of checks
+	// still running then are ignored
+	fclock.Advance(10 * time.Second)
+	due = assertDue("fourth check", 1)
+	hc.stop("foo.service")
+	hc.record(due[0], errors.New("connection refused"))
+	assertHealth("unit stopped", nil)
+	fclock.Advance(time.Minute)
+	assertDue("unit stopped", 0)
+
+	hc.start("foo.service")
+	hc.forget("foo.service")
+	fclock.Advance(time.Minute)
+	assertDue("unit unloaded", 0)
+	assertHealth("unit unloaded", nil)
+}
+
+func TestProbeHealth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	// an address nothing listens on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed listening: %v", err)
+	}
+	closed := l.Addr().String()
+	l.Close()
+
+	for i, tt := range []struct {
+		typ     string
+		target  string
+		healthy bool
+	}{
+		{job.HealthCheckExec, "exit 0", true},
+		{job.HealthCheckExec, "exit 1", false},
+		{job.HealthCheckExec, "sleep 10", false},
+		{job.HealthCheckHTTP, srv.URL + "/health", true},
+		{job.HealthCheckHTTP, srv.URL + "/other", false},
+		{job.HealthCheckTCP, srv.Listener.Addr().String(), true},
+		{job.HealthCheckTCP, closed, false},
+	} {
+		err := probeHealth(job.HealthCheck{Type: tt.typ, Target: tt.target, Interval: time.Second})
+		if tt.healthy && err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		} else if !tt.healthy && err == nil {
+			t.Errorf("case %d: unexpected success", i)
+		}
+	}
+}
+
+func TestHealthCheckCredential(t *testing.T) {
+	for i, tt := range []struct {
+		user, group string
+		uid, gid    uint32
+		root, valid bool
+	}{
+		{"", "", 0, 0, true, true},
+		{"root", "", 0, 0, false, true},
+		{"0", "", 0, 0, false, true},
+		{"", "0", 0, 0, false, true},
+		{"no-such-fleet-user", "", 0, 0, false, false},
+		{"root", "no-such-fleet-group", 0, 0, false, false},
+	} {
+		cred, err := healthCheckCredential(job.HealthCheck{Type: job.HealthCheckExec, User: tt.user, Group: tt.group})
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("case %d: unexpected nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if tt.root {
+			if cred != nil {
+				t.Errorf("case %d: expected no credential, got %#v", i, cred)
+			}
+			continue
+		}
+		if cred == nil || cred.Uid != tt.uid || cred.Gid != tt.gid || len(cred.Groups) != 0 {
+			t.Errorf("case %d: unexpected credential %#v", i, cred)
+		}
+	}
+}
+
+func TestProbeHealthAsUser(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing the user of health checks requires root")
+	}
+	if _, err := user.Lookup("nobody"); err != nil {
+		t.Skip("no nobody user")
+	}
+
+	hc := job.HealthCheck{Type: job.HealthCheckExec, Target: `test "$(id -un)" = nobody`, Interval: time.Second, User: "nobody"}
+	if err := probeHealth(hc); err != nil {
+		t.Errorf("expected health check to run as nobody: %v", err)
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"sort"
 	"time"
 
+	"github.com/jonboulle/clockwork"
+
 	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/log"
 	"github.com/cea-hpc/fleet/machine"
@@ -37,6 +39,7 @@ func NewReconciler(reg registry.Registry, rStream pkg.EventStream) *AgentReconci
 		reg:      reg,
 		rStream:  rStream,
 		tManager: newTaskManager(),
+		clock:    clockwork.NewRealClock(),
 	}
 }
 
@@ -44,6 +47,23 @@ type AgentReconciler struct {
 	reg      registry.Registry
 	rStream  pkg.EventStream
 	tManager *taskManager
+
+	// activeUnits caches the names of the Units reported active anywhere
+	// in the cluster, as listed at activeUnitsAt, so that Units waiting
+	// for those they start after do not have every reconciliation list
+	// all the UnitStates of the cluster
+	activeUnits   pkg.Set
+	activeUnitsAt time.Time
+	clock         clockwork.Clock
+}
+
+// SetTaskParallelism sets the maximum number of tasks of the same type the
+// AgentReconciler runs at once, e.g. to start many units concurrently.
+func (ar *AgentReconciler) SetTaskParallelism(n int) {
+	if n < 1 {
+		n = 1
+	}
+	ar.tManager.parallelism = n
 }
 
 // Run periodically attempts to reconcile the provided Agent until the stop
@@ -82,6 +102,11 @@ func (ar *AgentReconciler) Reconcile(a *Agent) {
 		return
 	}
 
+	if err := ar.holdUnitsStartingAfter(dAgentState, cAgentState); err != nil {
+		log.Errorf("Unable to determine state of Units to start after: %v", err)
+		return
+	}
+
 	tasks := ar.calculateTasksForUnits(dAgentState, cAgentState)
 	ar.launchTasks(tasks, a)
 }
@@ -149,13 +174,17 @@ func desiredAgentState(a *Agent, reg registry.Registry) (*AgentState, error) {
 
 	for _, u := range units {
 		u := u
-		md := u.RequiredTargetMetadata()
+		md := u.MetadataRequirement()
 
 		if u.IsGlobal() {
-			if !machine.HasMetadata(&ms, md) {
+			if !machine.MatchesMetadata(&ms, md) {
 				log.Debugf("Agent unable to run global unit %s: missing required metadata", u.Name)
 				continue
 			}
+			if ok, reason := as.MeetsMachineRequirements(&job.Job{Name: u.Name, Unit: u.Unit}); !ok {
+				log.Debugf("Agent unable to run global unit %s: %s", u.Name, reason)
+				continue
+			}
 		}
 
 		if !u.IsGlobal() {
@@ -175,6 +204,71 @@ func desiredAgentState(a *Agent, reg registry.Registry) (*AgentState, error) {
 	return &as, nil
 }
 
+// holdUnitsStartingAfter lowers the target state of the desired Units which
+// are not running yet to loaded, as long as any of the Units they must start
+// after is not reported active anywhere in the cluster. Units already running
+// are left alone. The active Units are listed at most once per reconcile
+// interval.
+func (ar *AgentReconciler) holdUnitsStartingAfter(dState *AgentState, cState unitStates) error {
+	var held []*job.Unit
+	for _, u := range dState.Units {
+		if u.TargetState != job.JobStateLaunched || len(u.StartAfter()) == 0 {
+			continue
+		}
+		if us, ok := cState[u.Name]; ok && us.state == job.JobStateLaunched {
+			continue
+		}
+		held = append(held, u)
+	}
+	if len(held) == 0 {
+		return nil
+	}
+
+	active, err := ar.activeUnitNames()
+	if err != nil {
+		return err
+	}
+
+	for _, u := range held {
+		for _, name := range u.StartAfter() {
+			if active.Contains(name) {
+				continue
+			}
+			log.Debugf("Holding start of Job(%s) until Unit(%s) is active", u.Name, name)
+			hu := *u
+			hu.TargetState = job.JobStateLoaded
+			dState.Units[u.Name] = &hu
+			break
+		}
+	}
+	return nil
+}
+
+// activeUnitNames returns the names of the Units reported active anywhere in
+// the cluster, listing the UnitStates of the Registry again only if the last
+// listing is older than the reconcile interval.
+func (ar *AgentReconciler) activeUnitNames() (pkg.Set, error) {
+	now := ar.clock.Now()
+	if ar.activeUnits != nil && now.Sub(ar.activeUnitsAt) < reconcileInterval {
+		return ar.activeUnits, nil
+	}
+
+	states, err := ar.reg.UnitStates()
+	if err != nil {
+		log.Errorf("Failed fetching UnitStates from Registry: %v", err)
+		return nil, err
+	}
+	active := pkg.NewUnsafeSet()
+	for _, us := range states {
+		if us.ActiveState == "active" {
+			active.Add(us.UnitName)
+		}
+	}
+
+	ar.activeUnits, ar.activeUnitsAt = active, now
+	return active, nil
+}
+
 // calculateTasksForUnits compares the desired and current state of an Agent.
 // The generated tasks represent what, in order, should be done to make the
 //  desired state match the current state.
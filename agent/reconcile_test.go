@@ -17,10 +17,14 @@ package agent
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
 
 	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/machine"
 	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/resource"
 	"github.com/cea-hpc/fleet/unit"
 )
 
@@ -320,6 +324,41 @@ func TestAbleToRun(t *testing.T) {
 			want:   job.JobActionUnschedule,
 		},
 
+		// machine advertises the required capabilities
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Capabilities: machine.Capabilities{"GRPC": true}}),
+			job:    newTestJobWithXFleetValues(t, "MachineCapabilities=GRPC"),
+			want:   job.JobActionSchedule,
+		},
+
+		// machine lacks a required capability
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Capabilities: machine.Capabilities{"GRPC": false}}),
+			job:    newTestJobWithXFleetValues(t, "MachineCapabilities=GRPC"),
+			want:   job.JobActionUnschedule,
+		},
+
+		// machine runs a recent enough fleet
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Version: "1.1.0"}),
+			job:    newTestJobWithXFleetValues(t, "MinFleetVersion=1.1.0"),
+			want:   job.JobActionSchedule,
+		},
+
+		// machine runs an older fleet
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Version: "1.0.9"}),
+			job:    newTestJobWithXFleetValues(t, "MinFleetVersion=1.1.0"),
+			want:   job.JobActionUnschedule,
+		},
+
+		// machine runs an unknown fleet version
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123"}),
+			job:    newTestJobWithXFleetValues(t, "MinFleetVersion=1.1.0"),
+			want:   job.JobActionUnschedule,
+		},
+
 		// peer scheduled locally
 		{
 			dState: &AgentState{
@@ -435,6 +474,132 @@ func TestAbleToRun(t *testing.T) {
 			job:  newTestJobWithXFleetValues(t, "Replaces=ping.service"),
 			want: job.JobActionReschedule,
 		},
+
+		// metadata expressions are satisfied
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Metadata: map[string]string{"region": "us-west", "cores": "32"}}),
+			job:    newTestJobWithXFleetValues(t, "MachineMetadata=region!=eu\nMachineMetadata=cores>=16|gpu"),
+			want:   job.JobActionSchedule,
+		},
+
+		// metadata expressions are not satisfied
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Metadata: map[string]string{"region": "us-west", "cores": "8"}}),
+			job:    newTestJobWithXFleetValues(t, "MachineMetadata=region!=eu\nMachineMetadata=cores>=16|gpu"),
+			want:   job.JobActionUnschedule,
+		},
+
+		// spread key present
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Metadata: map[string]string{"rack": "r1"}}),
+			job:    newTestJobWithXFleetValues(t, "SpreadBy=rack"),
+			want:   job.JobActionSchedule,
+		},
+
+		// spread key missing
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Metadata: map[string]string{"region": "us-west"}}),
+			job:    newTestJobWithXFleetValues(t, "SpreadBy=rack"),
+			want:   job.JobActionUnschedule,
+		},
+
+		// resources reserved but no capacity advertised
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123"}),
+			job:    newTestJobWithXFleetValues(t, "ReserveMemory=128"),
+			want:   job.JobActionUnschedule,
+		},
+
+		// enough free resources
+		{
+			dState: &AgentState{
+				MState: &machine.MachineState{
+					ID:             "123",
+					TotalResources: resource.ResourceTuple{Cores: 800, Memory: 4096},
+				},
+				Units: map[string]*job.Unit{
+					"ping.service": newTestUnitFromUnitContents(t, "ping.service", "[X-Fleet]\nReserveMemory=2048"),
+				},
+			},
+			job:  newTestJobWithXFleetValues(t, "ReserveCores=2\nReserveMemory=1024"),
+			want: job.JobActionSchedule,
+		},
+
+		// not enough free memory left by locally-scheduled units
+		{
+			dState: &AgentState{
+				MState: &machine.MachineState{
+					ID:             "123",
+					TotalResources: resource.ResourceTuple{Cores: 800, Memory: 4096},
+				},
+				Units: map[string]*job.Unit{
+					"ping.service": newTestUnitFromUnitContents(t, "ping.service", "[X-Fleet]\nReserveMemory=3072"),
+				},
+			},
+			job:  newTestJobWithXFleetValues(t, "ReserveMemory=1024"),
+			want: job.JobActionUnschedule,
+		},
+
+		// cores reserved for the host are not available
+		{
+			dState: NewAgentState(&machine.MachineState{
+				ID:             "123",
+				TotalResources: resource.ResourceTuple{Cores: 200, Memory: 4096},
+			}),
+			job:  newTestJobWithXFleetValues(t, "ReserveCores=1.5"),
+			want: job.JobActionUnschedule,
+		},
+
+		// no disk capacity declared
+		{
+			dState: NewAgentState(&machine.MachineState{
+				ID:             "123",
+				TotalResources: resource.ResourceTuple{Cores: 800, Memory: 4096},
+			}),
+			job:  newTestJobWithXFleetValues(t, "ReserveDisk=10"),
+			want: job.JobActionUnschedule,
+		},
+
+		// already-scheduled units keep their reservation
+		{
+			dState: &AgentState{
+				MState: &machine.MachineState{
+					ID:             "123",
+					TotalResources: resource.ResourceTuple{Cores: 800, Memory: 1024},
+				},
+				Units: map[string]*job.Unit{
+					"pong.service": newTestUnitFromUnitContents(t, "pong.service", "[X-Fleet]\nReserveMemory=4096"),
+				},
+			},
+			job:  newTestJobWithXFleetValues(t, "ReserveMemory=4096"),
+			want: job.JobActionSchedule,
+		},
+
+		// cordoned machines take no new units
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Maintenance: machine.MaintenanceCordoned}),
+			job:    newTestJobWithXFleetValues(t, ""),
+			want:   job.JobActionUnschedule,
+		},
+
+		// draining machines take no new units either
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Maintenance: machine.MaintenanceDraining}),
+			job:    newTestJobWithXFleetValues(t, ""),
+			want:   job.JobActionUnschedule,
+		},
+
+		// units already scheduled to a cordoned machine stay there
+		{
+			dState: &AgentState{
+				MState: &machine.MachineState{ID: "123", Maintenance: machine.MaintenanceCordoned},
+				Units: map[string]*job.Unit{
+					"pong.service": newTestUnitFromUnitContents(t, "pong.service", ""),
+				},
+			},
+			job:  newTestJobWithXFleetValues(t, ""),
+			want: job.JobActionSchedule,
+		},
 	}
 
 	for i, tt := range tests {
@@ -941,3 +1106,117 @@ func TestCalculateTasksForUnitsReloadUnitFilesTask(t *testing.T) {
 		}
 	}
 }
+
+func TestHoldUnitsStartingAfter(t *testing.T) {
+	contents := "[X-Fleet]\nStartAfter=db.service cache.service"
+	tests := []struct {
+		states []unit.UnitState
+		cState unitStates
+		target job.JobState
+	}{
+		// nothing is active yet
+		{
+			nil,
+			unitStates{},
+			jsLoaded,
+		},
+		// only one of the units is active
+		{
+			[]unit.UnitState{
+				unit.UnitState{UnitName: "db.service", ActiveState: "active", MachineID: "XXX"},
+				unit.UnitState{UnitName: "cache.service", ActiveState: "activating", MachineID: "YYY"},
+			},
+			unitStates{},
+			jsLoaded,
+		},
+		// both units are active, on other machines
+		{
+			[]unit.UnitState{
+				unit.UnitState{UnitName: "db.service", ActiveState: "active", MachineID: "XXX"},
+				unit.UnitState{UnitName: "cache.service", ActiveState: "active", MachineID: "YYY"},
+			},
+			unitStates{},
+			jsLaunched,
+		},
+		// the unit is already running
+		{
+			nil,
+			unitStates{
+				"foo.service": unitState{state: jsLaunched},
+			},
+			jsLaunched,
+		},
+	}
+
+	for i, tt := range tests {
+		reg := registry.NewFakeRegistry()
+		reg.SetUnitStates(tt.states)
+		dState := &AgentState{
+			Units: map[string]*job.Unit{
+				"foo.service": &job.Unit{
+					Name:        "foo.service",
+					Unit:        newUF(t, contents),
+					TargetState: jsLaunched,
+				},
+				"bar.service": &job.Unit{
+					Name:        "bar.service",
+					Unit:        newUF(t, "blah"),
+					TargetState: jsLaunched,
+				},
+			},
+		}
+
+		ar := NewReconciler(reg, nil)
+		if err := ar.holdUnitsStartingAfter(dState, tt.cState); err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if got := dState.Units["foo.service"].TargetState; got != tt.target {
+			t.Errorf("case %d: expected target state %s, got %s", i, tt.target, got)
+		}
+		if got := dState.Units["bar.service"].TargetState; got != jsLaunched {
+			t.Errorf("case %d: unit without StartAfter held in state %s", i, got)
+		}
+	}
+}
+
+func TestHoldUnitsStartingAfterCachesActiveUnits(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	ar := NewReconciler(reg, nil)
+	fclock := clockwork.NewFakeClock()
+	ar.clock = fclock
+
+	held := func() bool {
+		dState := &AgentState{
+			Units: map[string]*job.Unit{
+				"foo.service": &job.Unit{
+					Name:        "foo.service",
+					Unit:        newUF(t, "[X-Fleet]\nStartAfter=db.service"),
+					TargetState: jsLaunched,
+				},
+			},
+		}
+		if err := ar.holdUnitsStartingAfter(dState, unitStates{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return dState.Units["foo.service"].TargetState == jsLoaded
+	}
+
+	if !held() {
+		t.Fatalf("expected unit to be held until db.service is active")
+	}
+
+	// the UnitStates are not listed again within the reconcile interval
+	reg.SetUnitStates([]unit.UnitState{
+		unit.UnitState{UnitName: "db.service", ActiveState: "active", MachineID: "XXX"},
+	})
+	fclock.Advance(reconcileInterval - time.Second)
+	if !held() {
+		t.Errorf("expected the active units to be cached within the reconcile interval")
+	}
+
+	fclock.Advance(time.Second)
+	if held() {
+		t.Errorf("expected unit to start once db.service is listed active")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/log"
 	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/resource"
 )
 
 type AgentState struct {
@@ -39,6 +40,11 @@ func (as *AgentState) unitScheduled(name string) bool {
 	return as.Units[name] != nil
 }
 
+func isGlobalJob(j *job.Job) bool {
+	u := job.Unit{Name: j.Name, Unit: j.Unit}
+	return u.IsGlobal()
+}
+
 func hasStringInSlice(inSlice []string, unitName string) bool {
 	for _, elem := range inSlice {
 		if globMatches(elem, unitName) {
@@ -50,31 +56,7 @@ func hasStringInSlice(inSlice []string, unitName string) bool {
 
 // HasConflict determines whether there are any known conflicts with the given Unit
 func (as *AgentState) HasConflict(pUnitName string, pConflicts []string) (bool, []string) {
-	found := false
-	conflicts := []string{}
-
-	for _, eUnit := range as.Units {
-		if pUnitName == eUnit.Name {
-			continue
-		}
-
-		if hasStringInSlice(eUnit.Conflicts(), pUnitName) {
-			conflicts = append(conflicts, pUnitName)
-			found = true
-			break
-		}
-		if hasStringInSlice(pConflicts, eUnit.Name) {
-			conflicts = append(conflicts, eUnit.Name)
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		return false, []string{}
-	}
-
-	return true, conflicts
+	return job.FindConflicts(pUnitName, pConflicts, as.Units)
 }
 
 // hasReplace determines whether there are any known replaces with the given Unit
@@ -119,6 +101,75 @@ func (as *AgentState) hasReplace(pUnitName string, pReplaces []string) (found bo
 	return
 }
 
+// FreeResources returns the capacity of the Agent's machine which is
+// neither reserved for the host nor by the Units scheduled to it.
+func (as *AgentState) FreeResources() resource.ResourceTuple {
+	reserved := []resource.ResourceTuple{resource.HostResources}
+	for _, u := range as.Units {
+		reserved = append(reserved, u.Resources())
+	}
+	return resource.Sub(as.MState.TotalResources, resource.Sum(reserved...))
+}
+
+// hasResources determines whether the Agent's machine has enough free
+// capacity for the given reservation.
+func (as *AgentState) hasResources(res resource.ResourceTuple) (bool, string) {
+	if as.MState.TotalResources.Empty() {
+		return false, "local Machine does not advertise its resource capacity"
+	}
+
+	free := as.FreeResources()
+	if res.Cores > free.Cores || res.Memory > free.Memory || res.Disk > free.Disk {
+		return false, fmt.Sprintf("insufficient free resources: requested %s, available %s", res, free)
+	}
+
+	return true, ""
+}
+
+// MeetsMachineRequirements determines whether the Agent's machine advertises
+// the capabilities and runs the fleet version required by the Job (if any).
+func (as *AgentState) MeetsMachineRequirements(j *job.Job) (bool, string) {
+	u := job.Unit{Name: j.Name, Unit: j.Unit}
+	return u.MeetsMachineRequirements(as.MState)
+}
+
+// UsedCapacity returns the number of Units scheduled to the Agent and the
+// sum of their Weight.
+func (as *AgentState) UsedCapacity() (units, weight int) {
+	for _, u := range as.Units {
+		units++
+		weight += u.EffectiveWeight()
+	}
+	return
+}
+
+// Full determines whether the Agent's machine reached any of its limits,
+// leaving no room for another Unit.
+func (as *AgentState) Full() bool {
+	units, weight := as.UsedCapacity()
+	return (as.MState.MaxUnits != 0 && units >= as.MState.MaxUnits) ||
+		(as.MState.MaxWeight != 0 && weight >= as.MState.MaxWeight)
+}
+
+// HasCapacity determines whether the Agent's machine can take the Job
+// without exceeding its limits on the number and the total Weight of the
+// Units scheduled to it.
+func (as *AgentState) HasCapacity(j *job.Job) (bool, string) {
+	if !as.MState.Limited() {
+		return true, ""
+	}
+
+	units, weight := as.UsedCapacity()
+	if max := as.MState.MaxUnits; max != 0 && units >= max {
+		return false, fmt.Sprintf("local Machine is full: %d of %d units scheduled", units, max)
+	}
+	if max := as.MState.MaxWeight; max != 0 && weight+int(j.Weight()) > max {
+		return false, fmt.Sprintf("local Machine is full: weight %d of %d used, %d requested", weight, max, j.Weight())
+	}
+
+	return true, ""
+}
+
 func globMatches(pattern, target string) bool {
 	matched, err := path.Match(pattern, target)
 	if err != nil {
@@ -131,22 +182,51 @@ func globMatches(pattern, target string) bool {
 // the Agent's current state. A boolean indicating whether this is the
 // case or not is returned. The following criteria is used:
 //   - Agent must meet the Job's machine target requirement (if any)
+//   - Agent must not be cordoned, unless the Job is global or already scheduled to it
+//   - Agent must not be excluded by the engine after the Job failed too often on it
 //   - Agent must have all of the Job's required metadata (if any)
+//   - Agent must advertise the Job's required capabilities and run at
+//     least its required fleet version (if any)
+//   - Agent must have the metadata key the Job is spread by (if any)
 //   - Agent must have all required Peers of the Job scheduled locally (if any)
 //   - Job must not conflict with any other Units scheduled to the agent
+//   - Agent must have enough free resources for the Job's reservations (if
+//     any), unless the Job is already scheduled to it
+//   - Agent must not exceed its unit count and weight limits (if any) by
+//     taking the Job, unless the Job is global or already scheduled to it
 //   - Job must specially handle replaced units to be rescheduled
 func (as *AgentState) AbleToRun(j *job.Job) (jobAction job.JobAction, errstr string) {
 	if tgt, ok := j.RequiredTarget(); ok && !as.MState.MatchID(tgt) {
 		return job.JobActionUnschedule, fmt.Sprintf("agent ID %q does not match required %q", as.MState.ID, tgt)
 	}
 
-	metadata := j.RequiredTargetMetadata()
+	if as.MState.Cordoned() && !as.unitScheduled(j.Name) && !isGlobalJob(j) {
+		return job.JobActionUnschedule, fmt.Sprintf("local Machine is %s", as.MState.Maintenance)
+	}
+
+	for _, id := range j.ExcludedMachines {
+		if id == as.MState.ID {
+			return job.JobActionUnschedule, "unit failed too often on local Machine"
+		}
+	}
+
+	metadata := j.MetadataRequirement()
 	if len(metadata) != 0 {
-		if !machine.HasMetadata(as.MState, metadata) {
+		if !machine.MatchesMetadata(as.MState, metadata) {
 			return job.JobActionUnschedule, "local Machine metadata insufficient"
 		}
 	}
 
+	if ok, reason := as.MeetsMachineRequirements(j); !ok {
+		return job.JobActionUnschedule, reason
+	}
+
+	if key, ok := j.SpreadBy(); ok {
+		if _, ok := as.MState.Metadata[key]; !ok {
+			return job.JobActionUnschedule, fmt.Sprintf("local Machine has no metadata %q to spread by", key)
+		}
+	}
+
 	peers := j.Peers()
 	if len(peers) != 0 {
 		for _, peer := range peers {
@@ -160,6 +240,20 @@ func (as *AgentState) AbleToRun(j *job.Job) (jobAction job.JobAction, errstr str
 		return job.JobActionUnschedule, fmt.Sprintf("found conflict with locally-scheduled Unit(%s)", cJobName)
 	}
 
+	// Units already placed keep their reservation, so that lowering a
+	// machine's capacity never evicts running units.
+	if res := j.Resources(); !res.Empty() && !as.unitScheduled(j.Name) {
+		if ok, reason := as.hasResources(res); !ok {
+			return job.JobActionUnschedule, reason
+		}
+	}
+
+	if !as.unitScheduled(j.Name) && !isGlobalJob(j) {
+		if ok, reason := as.HasCapacity(j); !ok {
+			return job.JobActionUnschedule, reason
+		}
+	}
+
 	// Handle Replace option specially for rescheduling the unit
 	if cExists, cJobName := as.hasReplace(j.Name, j.Replaces()); cExists {
 		return job.JobActionReschedule, fmt.Sprintf("found replace with locally-scheduled Unit(%s)", cJobName)
@@ -168,6 +262,38 @@ func (as *AgentState) AbleToRun(j *job.Job) (jobAction job.JobAction, errstr str
 	return job.JobActionSchedule, ""
 }
 
+// PreferenceScore sums the weights of the soft placement requirements of
+// the Job which the Agent satisfies. The following preferences are used:
+//   - Agent has any of the metadata preferred by the Job
+//   - Agent has no locally-scheduled Unit the Job prefers not to be
+//     colocated with
+func (as *AgentState) PreferenceScore(j *job.Job) (score int) {
+	for _, pref := range j.PreferredMachineMetadata() {
+		for _, expr := range pref.Values {
+			term, err := machine.ParseMetadataTerm(expr)
+			if err == nil && term.Matches(as.MState.Metadata) {
+				score += pref.Weight
+				break
+			}
+		}
+	}
+
+	for _, pref := range j.PreferredNotColocatedWith() {
+		colocated := false
+		for _, eUnit := range as.Units {
+			if eUnit.Name != j.Name && hasStringInSlice(pref.Values, eUnit.Name) {
+				colocated = true
+				break
+			}
+		}
+		if !colocated {
+			score += pref.Weight
+		}
+	}
+
+	return
+}
+
 func (as *AgentState) GetReplacedUnit(j *job.Job) (string, error) {
 	cExists, replaced := as.hasReplace(j.Name, j.Replaces())
 	if !cExists {
@@ -20,6 +20,7 @@ import (
 
 	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/resource"
 	"github.com/cea-hpc/fleet/unit"
 )
 
@@ -255,6 +256,127 @@ func TestHasReplaces(t *testing.T) {
 	}
 }
 
+func TestFreeResources(t *testing.T) {
+	as := &AgentState{
+		MState: &machine.MachineState{
+			ID:             "XXX",
+			TotalResources: resource.ResourceTuple{Cores: 800, Memory: 8192, Disk: 1024},
+		},
+		Units: map[string]*job.Unit{
+			"foo.service": &job.Unit{
+				Name: "foo.service",
+				Unit: fleetUnit(t, "ReserveCores=2", "ReserveMemory=1024"),
+			},
+			"bar.service": &job.Unit{
+				Name: "bar.service",
+				Unit: fleetUnit(t, "ReserveCores=0.5", "ReserveDisk=512"),
+			},
+			"baz.service": &job.Unit{
+				Name: "baz.service",
+				Unit: fleetUnit(t),
+			},
+		},
+	}
+
+	want := resource.ResourceTuple{
+		Cores:  800 - resource.HostCores - 250,
+		Memory: 8192 - resource.HostMemory - 1024,
+		Disk:   1024 - resource.HostDisk - 512,
+	}
+	if got := as.FreeResources(); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestHasCapacity(t *testing.T) {
+	newAgentState := func(maxUnits, maxWeight int) *AgentState {
+		return &AgentState{
+			MState: &machine.MachineState{ID: "XXX", MaxUnits: maxUnits, MaxWeight: maxWeight},
+			Units: map[string]*job.Unit{
+				"foo.service": &job.Unit{Name: "foo.service", Unit: fleetUnit(t, "Weight=40")},
+				"bar.service": &job.Unit{Name: "bar.service", Unit: fleetUnit(t)},
+			},
+		}
+	}
+	light := &job.Job{Name: "light.service", Unit: fleetUnit(t)}
+	heavy := &job.Job{Name: "heavy.service", Unit: fleetUnit(t, "Weight=60")}
+	global := &job.Job{Name: "global.service", Unit: fleetUnit(t, "Global=true")}
+
+	tests := []struct {
+		maxUnits  int
+		maxWeight int
+		j         *job.Job
+		able      bool
+		full      bool
+	}{
+		// no limits
+		{0, 0, heavy, true, false},
+		{3, 0, heavy, true, false},
+		{2, 0, light, false, true},
+		{0, 100, light, true, false},
+		{0, 100, heavy, false, false},
+		{0, 41, heavy, false, true},
+		// global units are not refused
+		{2, 0, global, true, true},
+	}
+
+	for i, tt := range tests {
+		as := newAgentState(tt.maxUnits, tt.maxWeight)
+		act, reason := as.AbleToRun(tt.j)
+		if able := act != job.JobActionUnschedule; able != tt.able {
+			t.Errorf("case %d: expected able %t, got %t (%s)", i, tt.able, able, reason)
+		}
+		if full := as.Full(); full != tt.full {
+			t.Errorf("case %d: expected full %t, got %t", i, tt.full, full)
+		}
+	}
+
+	as := newAgentState(0, 0)
+	if units, weight := as.UsedCapacity(); units != 2 || weight != 41 {
+		t.Errorf("expected 2 units of weight 41, got %d units of weight %d", units, weight)
+	}
+}
+
+func TestPreferenceScore(t *testing.T) {
+	as := &AgentState{
+		MState: &machine.MachineState{
+			ID:       "XXX",
+			Metadata: map[string]string{"disk": "ssd", "region": "eu"},
+		},
+		Units: map[string]*job.Unit{
+			"db@1.service": &job.Unit{Name: "db@1.service"},
+		},
+	}
+
+	tests := []struct {
+		opts  []string
+		score int
+	}{
+		// no preferences
+		{[]string{}, 0},
+		// matching metadata earns the default weight
+		{[]string{"PreferMachineMetadata=disk=ssd"}, 1},
+		// any of the values of a preference may match
+		{[]string{"PreferMachineMetadata=disk=hdd region=eu weight=3"}, 3},
+		// mismatching metadata earns nothing
+		{[]string{"PreferMachineMetadata=disk=hdd weight=3"}, 0},
+		// colocation with a matching unit earns nothing
+		{[]string{"PreferNotColocatedWith=db@*.service weight=4"}, 0},
+		{[]string{"PreferNotColocatedWith=web@*.service weight=4"}, 4},
+		// metadata expressions are supported
+		{[]string{"PreferMachineMetadata=region!=us weight=2"}, 2},
+		// weights add up
+		{[]string{"PreferMachineMetadata=disk=ssd weight=2", "PreferMachineMetadata=region=eu weight=5", "PreferNotColocatedWith=web.service"}, 8},
+	}
+
+	for i, tt := range tests {
+		j := &job.Job{Name: "foo.service", Unit: fleetUnit(t, tt.opts...)}
+		if score := as.PreferenceScore(j); score != tt.score {
+			t.Errorf("case %d: got score %d, want %d", i, score, tt.score)
+		}
+	}
+}
+
 func TestGlobMatches(t *testing.T) {
 	tests := []struct {
 		pattern  string
@@ -15,9 +15,13 @@
 package agent
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/metrics"
+	"github.com/cea-hpc/fleet/pkg"
 )
 
 const (
@@ -66,37 +70,90 @@ type taskResult struct {
 
 type taskManager struct {
 	mapper taskMapperFunc
+	// parallelism is the maximum number of tasks run at once
+	parallelism int
 }
 
 func newTaskManager() *taskManager {
 	return &taskManager{
-		mapper: mapTaskToFunc,
+		mapper:      mapTaskToFunc,
+		parallelism: 1,
 	}
 }
 
-// Do attempts to complete a series of tasks against an Agent. Each task
-// is executed in order. If any task is unable to be attempted, or is able
-// to be attempted but fails, Do will halt execution. The returned slice
-// will contain a taskResult for every task that was attempted. Do is not
-// threadsafe.
+// Do attempts to complete a series of tasks against an Agent. Consecutive
+// tasks of the same type are run concurrently, up to the parallelism of the
+// taskManager, and all of them complete before the next tasks start, so
+// that tasks sorted by taskTypeSortOrder keep their ordering guarantees.
+// A task which is unable to be attempted, or fails, does not halt the tasks
+// of other units, but the following tasks of the same unit are skipped, as
+// are all following tasks if the failed task applies to no unit in
+// particular. The returned slice contains a taskResult for every task, in
+// the order of the given tasks. Do is not threadsafe.
 func (tm *taskManager) Do(tasks []task, a *Agent) []taskResult {
-	results := make([]taskResult, 0, len(tasks))
-	for _, t := range tasks {
-		taskFunc, err := tm.mapper(t, a)
-		if err == nil {
-			err = taskFunc()
+	results := make([]taskResult, len(tasks))
+	failed := pkg.NewUnsafeSet()
+	haltAll := false
+
+	for start := 0; start < len(tasks); {
+		end := start + 1
+		for end < len(tasks) && tasks[end].typ == tasks[start].typ {
+			end++
 		}
 
-		results = append(results, taskResult{task: t, err: err})
-
-		if err != nil {
-			break
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, tm.parallelism)
+		for i := start; i < end; i++ {
+			t := tasks[i]
+			if haltAll {
+				results[i] = taskResult{task: t, err: errors.New("skipped after a previous task failed")}
+				continue
+			}
+			if t.unit != nil && failed.Contains(t.unit.Name) {
+				results[i] = taskResult{task: t, err: fmt.Errorf("skipped after a previous task of Unit(%s) failed", t.unit.Name)}
+				continue
+			}
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				results[i] = taskResult{task: tasks[i], err: tm.do(tasks[i], a)}
+			}(i)
+		}
+		wg.Wait()
+
+		for _, res := range results[start:end] {
+			if res.err == nil {
+				continue
+			}
+			if res.task.unit == nil {
+				haltAll = true
+			} else {
+				failed.Add(res.task.unit.Name)
+			}
 		}
+		start = end
 	}
 
 	return results
 }
 
+// do attempts a single task, reporting its failure.
+func (tm *taskManager) do(t task, a *Agent) error {
+	taskFunc, err := tm.mapper(t, a)
+	if err == nil {
+		err = taskFunc()
+	}
+	if err != nil {
+		metrics.ReportAgentTaskFailure(t.typ)
+	}
+	return err
+}
+
 type taskMapperFunc func(t task, a *Agent) (func() error, error)
 
 func mapTaskToFunc(t task, a *Agent) (fn func() error, err error) {
@@ -15,9 +15,14 @@
 package agent
 
 import (
+	"errors"
 	"reflect"
 	"sort"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/cea-hpc/fleet/job"
 )
 
 func TestTaskSorting(t *testing.T) {
@@ -87,3 +92,187 @@ func TestTaskSorting(t *testing.T) {
 		}
 	}
 }
+
+func TestTaskManagerDo(t *testing.T) {
+	newTask := func(typ, name string) task {
+		tsk := task{typ: typ}
+		if name != "" {
+			tsk.unit = &job.Unit{Name: name}
+		}
+		return tsk
+	}
+	// key identifies a task by its type and unit
+	key := func(tsk task) string {
+		if tsk.unit == nil {
+			return tsk.typ
+		}
+		return tsk.typ + ":" + tsk.unit.Name
+	}
+
+	tests := []struct {
+		tasks []task
+		fail  map[string]bool
+		// whether each task is expected to have been attempted, and
+		// to have succeeded
+		attempted []bool
+		succeeded []bool
+	}{
+		// every task attempted
+		{
+			tasks: []task{
+				newTask(taskTypeLoadUnit, "A"),
+				newTask(taskTypeLoadUnit, "B"),
+				newTask(taskTypeReloadUnitFiles, ""),
+				newTask(taskTypeStartUnit, "A"),
+				newTask(taskTypeStartUnit, "B"),
+			},
+			attempted: []bool{true, true, true, true, true},
+			succeeded: []bool{true, true, true, true, true},
+		},
+
+		// a failed task only skips the following tasks of its unit
+		{
+			tasks: []task{
+				newTask(taskTypeUnloadUnit, "C"),
+				newTask(taskTypeLoadUnit, "A"),
+				newTask(taskTypeLoadUnit, "B"),
+				newTask(taskTypeReloadUnitFiles, ""),
+				newTask(taskTypeStartUnit, "A"),
+				newTask(taskTypeStartUnit, "B"),
+			},
+			fail:      map[string]bool{"UnloadUnit:C": true},
+			attempted: []bool{true, true, true, true, true, true},
+			succeeded: []bool{false, true, true, true, true, true},
+		},
+		{
+			tasks: []task{
+				newTask(taskTypeLoadUnit, "A"),
+				newTask(taskTypeLoadUnit, "B"),
+				newTask(taskTypeReloadUnitFiles, ""),
+				newTask(taskTypeStartUnit, "A"),
+				newTask(taskTypeStartUnit, "B"),
+			},
+			fail:      map[string]bool{"LoadUnit:A": true},
+			attempted: []bool{true, true, true, false, true},
+			succeeded: []bool{false, true, true, false, true},
+		},
+
+		// a failed task applying to no unit skips all following tasks
+		{
+			tasks: []task{
+				newTask(taskTypeLoadUnit, "A"),
+				newTask(taskTypeReloadUnitFiles, ""),
+				newTask(taskTypeStartUnit, "A"),
+			},
+			fail:      map[string]bool{"ReloadUnitFiles": true},
+			attempted: []bool{true, true, false},
+			succeeded: []bool{true, false, false},
+		},
+
+		// a task which cannot be attempted is reported as failed
+		{
+			tasks: []task{
+				newTask("Bogus", "A"),
+				newTask(taskTypeStartUnit, "A"),
+				newTask(taskTypeStartUnit, "B"),
+			},
+			attempted: []bool{false, false, true},
+			succeeded: []bool{false, false, true},
+		},
+	}
+
+	for i, tt := range tests {
+		for _, parallelism := range []int{1, 4} {
+			var mu sync.Mutex
+			attempted := make(map[string]bool)
+
+			tm := &taskManager{parallelism: parallelism}
+			tm.mapper = func(tsk task, a *Agent) (func() error, error) {
+				if _, ok := taskTypeSortOrder[tsk.typ]; !ok {
+					return nil, errors.New("unrecognized task type")
+				}
+				return func() error {
+					mu.Lock()
+					defer mu.Unlock()
+					attempted[key(tsk)] = true
+					if tt.fail[key(tsk)] {
+						return errors.New("task failed")
+					}
+					return nil
+				}, nil
+			}
+
+			results := tm.Do(tt.tasks, nil)
+			if len(results) != len(tt.tasks) {
+				t.Fatalf("case %d: expected %d results, got %d", i, len(tt.tasks), len(results))
+			}
+			for j, res := range results {
+				if !reflect.DeepEqual(res.task, tt.tasks[j]) {
+					t.Errorf("case %d: result %d is for task %v, expected %v", i, j, res.task, tt.tasks[j])
+				}
+				if attempted[key(res.task)] != tt.attempted[j] {
+					t.Errorf("case %d, parallelism %d: task %d attempted=%t, expected %t", i, parallelism, j, attempted[key(res.task)], tt.attempted[j])
+				}
+				if (res.err == nil) != tt.succeeded[j] {
+					t.Errorf("case %d, parallelism %d: task %d err=%v, expected success=%t", i, parallelism, j, res.err, tt.succeeded[j])
+				}
+			}
+		}
+	}
+}
+
+func TestTaskManagerDoParallelism(t *testing.T) {
+	var tasks []task
+	for _, typ := range []string{taskTypeLoadUnit, taskTypeStartUnit} {
+		for _, name := range []string{"A", "B", "C", "D", "E", "F"} {
+			tasks = append(tasks, task{typ: typ, unit: &job.Unit{Name: name}})
+		}
+	}
+
+	for _, parallelism := range []int{1, 3} {
+		var mu sync.Mutex
+		running := make(map[string]int)
+		var maxRunning int
+		var order []string
+
+		tm := &taskManager{parallelism: parallelism}
+		tm.mapper = func(tsk task, a *Agent) (func() error, error) {
+			return func() error {
+				mu.Lock()
+				running[tsk.typ]++
+				total := 0
+				for typ, n := range running {
+					if typ != tsk.typ && n > 0 {
+						t.Errorf("parallelism %d: task %s running along with %d %s tasks", parallelism, tsk.typ, n, typ)
+					}
+					total += n
+				}
+				if total > maxRunning {
+					maxRunning = total
+				}
+				order = append(order, tsk.typ)
+				mu.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mu.Lock()
+				running[tsk.typ]--
+				mu.Unlock()
+				return nil
+			}, nil
+		}
+
+		tm.Do(tasks, nil)
+		if maxRunning > parallelism {
+			t.Errorf("expected at most %d tasks running at once, got %d", parallelism, maxRunning)
+		}
+		if parallelism == 1 && maxRunning != 1 {
+			t.Errorf("expected tasks to run one at a time, got %d at once", maxRunning)
+		}
+		for j, typ := range order {
+			if want := tasks[j].typ; typ != want {
+				t.Errorf("parallelism %d: task %d of type %s, expected %s", parallelism, j, typ, want)
+			}
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/cea-hpc/fleet/log"
 	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/metrics"
 	"github.com/cea-hpc/fleet/registry"
 	"github.com/cea-hpc/fleet/unit"
 )
@@ -178,20 +179,55 @@ func (p *UnitStatePublisher) queueForPublish(name string, us *unit.UnitState) {
 // uses to determine when a change has occurred, and to do a periodic
 // publishing of all UnitStates. It returns a boolean indicating whether the
 // state in the given UnitStateHeartbeat differs from the state from the
-// previous heartbeat of this unit, if any exists.
+// previous heartbeat of this unit, if any exists. The state in the
+// heartbeat is given the number of failures of the unit so far, and its
+// resource usage is reported to the agent metrics.
 func (p *UnitStatePublisher) updateCache(update *unit.UnitStateHeartbeat) (changed bool) {
 	p.cacheMutex.Lock()
 	defer p.cacheMutex.Unlock()
 
 	last, ok := p.cache[update.Name]
+	if update.State != nil {
+		update.State.Failures = countFailures(last, update.State)
+		metrics.ReportAgentUnitUsage(update.Name, time.Duration(update.State.CPUUsageNSec), update.State.MemoryCurrent, update.State.TasksCurrent)
+	} else {
+		metrics.ForgetAgentUnitUsage(update.Name)
+	}
 	p.cache[update.Name] = update.State
 
-	if !ok || !reflect.DeepEqual(last, update.State) {
+	if !ok || !reflect.DeepEqual(withoutUsage(last), withoutUsage(update.State)) {
 		changed = true
 	}
 	return
 }
 
+// withoutUsage returns a copy of the UnitState without its resource usage,
+// which changes with nearly every heartbeat of a running unit. Usage alone
+// is therefore not considered a change, and is only published periodically.
+func withoutUsage(us *unit.UnitState) *unit.UnitState {
+	if us == nil {
+		return nil
+	}
+	cp := *us
+	cp.CPUUsageNSec, cp.MemoryCurrent, cp.TasksCurrent = 0, 0, 0
+	return &cp
+}
+
+// countFailures returns the number of times the unit entered the failed
+// state since it was loaded, given its previous and current states. The
+// count starts over once the unit is unloaded, as its previous state is nil
+// then.
+func countFailures(last, us *unit.UnitState) int {
+	failures := 0
+	if last != nil {
+		failures = last.Failures
+	}
+	if us.ActiveState == "failed" && (last == nil || last.ActiveState != "failed") {
+		failures++
+	}
+	return failures
+}
+
 // Purge ensures that the UnitStates for all Units known in the
 // UnitStatePublisher's cache are removed from the registry.
 func (p *UnitStatePublisher) Purge() {
@@ -123,6 +123,73 @@ func TestUpdateCache(t *testing.T) {
 	}
 }
 
+func TestUpdateCacheFailures(t *testing.T) {
+	mach := &machine.FakeMachine{
+		MachineState: machine.MachineState{ID: "XXX"},
+	}
+	usp := NewUnitStatePublisher(nil, mach, 0)
+
+	name := "foo.service"
+	for i, tt := range []struct {
+		activeState string
+		unloaded    bool
+		failures    int
+	}{
+		{"active", false, 0},
+		{"failed", false, 1},
+		// staying in the failed state is not another failure
+		{"failed", false, 1},
+		{"activating", false, 1},
+		{"failed", false, 2},
+		{"active", false, 2},
+		// the count starts over once the unit is unloaded
+		{"", true, 0},
+		{"failed", false, 1},
+	} {
+		ush := &unit.UnitStateHeartbeat{Name: name}
+		if !tt.unloaded {
+			ush.State = &unit.UnitState{ActiveState: tt.activeState, UnitName: name}
+		}
+		usp.updateCache(ush)
+		if tt.unloaded {
+			continue
+		}
+		if ush.State.Failures != tt.failures {
+			t.Errorf("case %d: expected %d failures, got %d", i, tt.failures, ush.State.Failures)
+		}
+	}
+}
+
+func TestUpdateCacheUsage(t *testing.T) {
+	mach := &machine.FakeMachine{
+		MachineState: machine.MachineState{ID: "XXX"},
+	}
+	usp := NewUnitStatePublisher(nil, mach, 0)
+
+	name := "foo.service"
+	for i, tt := range []struct {
+		state   unit.UnitState
+		changed bool
+	}{
+		{unit.UnitState{ActiveState: "active"}, true},
+		// usage alone is not a change
+		{unit.UnitState{ActiveState: "active", CPUUsageNSec: 1000, MemoryCurrent: 4096, TasksCurrent: 2}, false},
+		{unit.UnitState{ActiveState: "active", CPUUsageNSec: 2000, MemoryCurrent: 8192, TasksCurrent: 3}, false},
+		{unit.UnitState{ActiveState: "failed", CPUUsageNSec: 2000}, true},
+	} {
+		us := tt.state
+		us.UnitName = name
+		ush := &unit.UnitStateHeartbeat{Name: name, State: &us}
+		if changed := usp.updateCache(ush); changed != tt.changed {
+			t.Errorf("case %d: expected changed=%t, got %t", i, tt.changed, changed)
+		}
+		// the cache holds the latest usage, published periodically
+		if got := usp.cache[name]; got.CPUUsageNSec != us.CPUUsageNSec || got.MemoryCurrent != us.MemoryCurrent {
+			t.Errorf("case %d: cache holds outdated usage %#v", i, got)
+		}
+	}
+}
+
 func TestPruneCache(t *testing.T) {
 	tests := []struct {
 		cacheBefore map[string]*unit.UnitState
@@ -0,0 +1,51 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/cea-hpc/fleet/log"
+	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/schema"
+)
+
+func wireUpEngineResource(mux *http.ServeMux, prefix string, cReg registry.ClusterRegistry) {
+	res := path.Join(prefix, "engine")
+	er := engineResource{cReg}
+	mux.Handle(res, &er)
+}
+
+type engineResource struct {
+	cReg registry.ClusterRegistry
+}
+
+func (er *engineResource) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		sendError(rw, http.StatusMethodNotAllowed, errors.New("only GET supported against this resource"))
+		return
+	}
+
+	sched, err := er.cReg.EngineScheduler()
+	if err != nil {
+		log.Errorf("Failed fetching engine scheduler from Registry: %v", err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	sendResponse(rw, http.StatusOK, schema.Engine{Scheduler: sched})
+}
@@ -0,0 +1,62 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cea-hpc/fleet/registry"
+)
+
+func TestEngineResourceGet(t *testing.T) {
+	fcr := registry.NewFakeClusterRegistry(nil, 1)
+	fcr.SetEngineScheduler("bin-pack")
+	resource := &engineResource{fcr}
+
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://example.com/fleet/v1/engine", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+
+	resource.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rw.Code)
+	}
+
+	expect := `{"scheduler":"bin-pack"}`
+	if body := rw.Body.String(); body != expect {
+		t.Errorf("Received unexpected body: want=%s got=%s", expect, body)
+	}
+}
+
+func TestEngineResourceBadMethod(t *testing.T) {
+	resource := &engineResource{registry.NewFakeClusterRegistry(nil, 1)}
+
+	for _, verb := range []string{"POST", "PUT", "DELETE", "PATCH"} {
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest(verb, "http://example.com/fleet/v1/engine", nil)
+		if err != nil {
+			t.Fatalf("Failed creating http.Request: %v", err)
+		}
+
+		resource.ServeHTTP(rw, req)
+		if err := assertErrorResponse(rw, http.StatusMethodNotAllowed); err != nil {
+			t.Errorf("%s: %v", verb, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cea-hpc/fleet/client"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+func TestEventsResourceList(t *testing.T) {
+	fcr := registry.NewFakeClusterRegistry(nil, 1)
+	at := time.Date(2016, time.May, 4, 12, 0, 0, 0, time.UTC)
+	fcr.SetEngineEvents([]registry.EngineEvent{
+		{Time: at, LeaderID: "XXX", Type: "schedule", UnitName: "foo.service", MachineID: "YYY", Reason: "target state launched and unit not scheduled"},
+		{Time: at, LeaderID: "XXX", Type: "reschedule", UnitName: "bar.service", MachineID: "XXX", PreviousMachineID: "YYY", Reason: "target Machine(YYY) went away"},
+		{Time: at, LeaderID: "XXX", Type: "unschedule", UnitName: "foo.service", MachineID: "YYY", Reason: "target state inactive"},
+	})
+	fAPI := &client.RegistryClient{Registry: struct {
+		*registry.FakeRegistry
+		*registry.FakeClusterRegistry
+	}{registry.NewFakeRegistry(), fcr}}
+	resource := &eventsResource{fAPI}
+
+	for i, tt := range []struct {
+		query  string
+		expect string
+	}{
+		{
+			query:  "?unitName=foo.service",
+			expect: `{"events":[{"leaderID":"XXX","machineID":"YYY","reason":"target state launched and unit not scheduled","time":"2016-05-04T12:00:00Z","type":"schedule","unitName":"foo.service"},{"leaderID":"XXX","machineID":"YYY","reason":"target state inactive","time":"2016-05-04T12:00:00Z","type":"unschedule","unitName":"foo.service"}]}`,
+		},
+		{
+			query:  "?machineID=XXX",
+			expect: `{"events":[{"leaderID":"XXX","machineID":"XXX","previousMachineID":"YYY","reason":"target Machine(YYY) went away","time":"2016-05-04T12:00:00Z","type":"reschedule","unitName":"bar.service"}]}`,
+		},
+		{
+			query:  "?unitName=baz.service",
+			expect: `{}`,
+		},
+	} {
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://example.com/fleet/v1/events"+tt.query, nil)
+		if err != nil {
+			t.Fatalf("Failed creating http.Request: %v", err)
+		}
+
+		resource.ServeHTTP(rw, req)
+		if rw.Code != http.StatusOK {
+			t.Errorf("case %d: expected 200, got %d", i, rw.Code)
+			continue
+		}
+		if body := rw.Body.String(); body != tt.expect {
+			t.Errorf("case %d: received unexpected body:\nwant=%s\ngot=%s", i, tt.expect, body)
+		}
+	}
+
+	// every event matches without filter
+	events, err := fAPI.EngineEvents("", "")
+	if err != nil || len(events) != 3 {
+		t.Errorf("Expected 3 events, got %d (err=%v)", len(events), err)
+	}
+}
+
+func TestEventsResourceBadMethod(t *testing.T) {
+	resource := &eventsResource{&client.RegistryClient{Registry: registry.NewFakeRegistry()}}
+
+	for _, verb := range []string{"POST", "PUT", "DELETE", "PATCH"} {
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest(verb, "http://example.com/fleet/v1/events", nil)
+		if err != nil {
+			t.Fatalf("Failed creating http.Request: %v", err)
+		}
+
+		resource.ServeHTTP(rw, req)
+		if err := assertErrorResponse(rw, http.StatusMethodNotAllowed); err != nil {
+			t.Errorf("%s: %v", verb, err)
+		}
+	}
+}
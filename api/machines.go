@@ -28,7 +28,8 @@ import (
 )
 
 var (
-	metadataPathRegex = regexp.MustCompile("^/([^/]+)/metadata/([A-Za-z0-9_.-]+$)")
+	metadataPathRegex    = regexp.MustCompile("^/([^/]+)/metadata/([A-Za-z0-9_.-]+$)")
+	maintenancePathRegex = regexp.MustCompile("^/([^/]+)/maintenance$")
 )
 
 func wireUpMachinesResource(mux *http.ServeMux, prefix string, tokenLimit int, cAPI client.API) {
@@ -97,7 +98,8 @@ func (mr *machinesResource) patch(rw http.ResponseWriter, req *http.Request) {
 			return
 		}
 
-		if metadataPathRegex.FindStringSubmatch(op.Path) == nil {
+		maintenance := maintenancePathRegex.MatchString(op.Path)
+		if !maintenance && metadataPathRegex.FindStringSubmatch(op.Path) == nil {
 			sendError(rw, http.StatusBadRequest, errors.New("machine metadata path invalid"))
 			return
 		}
@@ -106,10 +108,28 @@ func (mr *machinesResource) patch(rw http.ResponseWriter, req *http.Request) {
 			sendError(rw, http.StatusBadRequest, errors.New("invalid value: add and replace require a value"))
 			return
 		}
+
+		if maintenance && op.Operation != "remove" &&
+			op.Value.Value != machine.MaintenanceCordoned && op.Value.Value != machine.MaintenanceDraining {
+			sendError(rw, http.StatusBadRequest, errors.New("invalid value: expect cordoned or draining maintenance"))
+			return
+		}
 	}
 
 	for _, op := range ops {
-		// regex already validated above
+		// regexes already validated above
+		if s := maintenancePathRegex.FindStringSubmatch(op.Path); s != nil {
+			mode := op.Value.Value
+			if op.Operation == "remove" {
+				mode = ""
+			}
+			if err := mr.cAPI.SetMachineMaintenance(s[1], mode); err != nil {
+				sendError(rw, http.StatusInternalServerError, err)
+				return
+			}
+			continue
+		}
+
 		s := metadataPathRegex.FindStringSubmatch(op.Path)
 		machID := s[1]
 		key := s[2]
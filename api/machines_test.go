@@ -23,8 +23,10 @@ import (
 	"testing"
 
 	"github.com/cea-hpc/fleet/client"
+	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/machine"
 	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/resource"
 )
 
 func fakeMachinesSetup() (*machinesResource, *httptest.ResponseRecorder) {
@@ -70,6 +72,72 @@ func TestMachinesList(t *testing.T) {
 	}
 }
 
+func TestMachinesListResources(t *testing.T) {
+	fr := registry.NewFakeRegistry()
+	fr.SetMachines([]machine.MachineState{
+		{ID: "XXX", TotalResources: resource.ResourceTuple{Cores: 400, Memory: 4096, Disk: 1024}},
+		{ID: "YYY"},
+	})
+	fr.SetJobs([]job.Job{
+		{Name: "foo.service", Unit: newUnit(t, "[X-Fleet]\nReserveCores=1\nReserveMemory=512"), TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+		{Name: "bar.service", Unit: newUnit(t, "[X-Fleet]\nReserveMemory=1024"), TargetState: job.JobStateInactive, TargetMachineID: "XXX"},
+		{Name: "baz.service", Unit: newUnit(t, "[X-Fleet]\nGlobal=true\nReserveDisk=100"), TargetState: job.JobStateLaunched},
+	})
+	fAPI := &client.RegistryClient{Registry: fr}
+	mr := &machinesResource{cAPI: fAPI, tokenLimit: testTokenLimit}
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+
+	mr.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", rw.Code)
+	}
+
+	body := rw.Body.String()
+	expected := `{"machines":[{"freeResources":{"cores":200,"disk":924,"memory":3328},"id":"XXX","totalResources":{"cores":400,"disk":1024,"memory":4096}},{"id":"YYY"}]}`
+	if body != expected {
+		t.Errorf("Expected body:\n%s\n\nReceived body:\n%s\n", expected, body)
+	}
+}
+
+func TestMachinesListCapacity(t *testing.T) {
+	fr := registry.NewFakeRegistry()
+	fr.SetMachines([]machine.MachineState{
+		{ID: "XXX", MaxUnits: 10, MaxWeight: 100},
+		{ID: "YYY"},
+	})
+	fr.SetJobs([]job.Job{
+		{Name: "foo.service", Unit: newUnit(t, "[X-Fleet]\nWeight=20"), TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+		{Name: "bar.service", Unit: newUnit(t, "[X-Fleet]\nWeight=30"), TargetState: job.JobStateInactive, TargetMachineID: "XXX"},
+		{Name: "baz.service", Unit: newUnit(t, "[X-Fleet]\nGlobal=true"), TargetState: job.JobStateLaunched},
+		// global units which the engine does not place on XXX are not
+		// counted either
+		{Name: "qux.service", Unit: newUnit(t, "[X-Fleet]\nGlobal=true\nConflicts=foo.service"), TargetState: job.JobStateLaunched},
+		{Name: "quux.service", Unit: newUnit(t, "[X-Fleet]\nGlobal=true\nMachineCapabilities=GRPC"), TargetState: job.JobStateLaunched},
+	})
+	fAPI := &client.RegistryClient{Registry: fr}
+	mr := &machinesResource{cAPI: fAPI, tokenLimit: testTokenLimit}
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+
+	mr.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", rw.Code)
+	}
+
+	body := rw.Body.String()
+	expected := `{"machines":[{"id":"XXX","maxCapacity":{"units":10,"weight":100},"usedCapacity":{"units":2,"weight":21}},{"id":"YYY"}]}`
+	if body != expected {
+		t.Errorf("Expected body:\n%s\n\nReceived body:\n%s\n", expected, body)
+	}
+}
+
 func TestMachinesListBadNextPageToken(t *testing.T) {
 	fr := registry.NewFakeRegistry()
 	fAPI := &client.RegistryClient{Registry: fr}
@@ -266,3 +334,57 @@ func TestMachinesPatchBadValue(t *testing.T) {
 		t.Errorf("Expected 400, got %d", rw.Code)
 	}
 }
+
+func TestMachinesPatchMaintenance(t *testing.T) {
+	reqBody := `
+	[{"op": "add", "path": "/XXX/maintenance", "value": { "value": "cordoned" }},
+	{"op": "replace", "path": "/YYY/maintenance", "value": { "value": "draining" }},
+	{"op": "remove", "path": "/XXX/maintenance"}]
+	`
+
+	resource, rw := fakeMachinesSetup()
+	req, err := http.NewRequest("PATCH", "http://example.com/machines", strings.NewReader(reqBody))
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+
+	resource.ServeHTTP(rw, req)
+	if rw.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", rw.Code)
+	}
+
+	// fetch machine to make sure maintenance has been set
+	req, err = http.NewRequest("GET", "http://example.com/machines", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+	rw.Body.Reset()
+	resource.ServeHTTP(rw, req)
+
+	if rw.Body == nil {
+		t.Error("Received nil response body")
+	} else {
+		body := rw.Body.String()
+		expected := `{"machines":[{"id":"XXX"},{"id":"YYY","maintenance":"draining","metadata":{"ping":"pong"},"primaryIP":"1.2.3.4"}]}`
+		if body != expected {
+			t.Errorf("Expected body:\n%s\n\nReceived body:\n%s\n", expected, body)
+		}
+	}
+}
+
+func TestMachinesPatchBadMaintenance(t *testing.T) {
+	reqBody := `
+	[{"op": "add", "path": "/XXX/maintenance", "value": { "value": "asleep" }}]
+	`
+
+	resource, rw := fakeMachinesSetup()
+	req, err := http.NewRequest("PATCH", "http://example.com/machines", strings.NewReader(reqBody))
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+
+	resource.ServeHTTP(rw, req)
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rw.Code)
+	}
+}
@@ -25,16 +25,18 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-func NewServeMux(reg registry.Registry, tokenLimit int) http.Handler {
+func NewServeMux(reg registry.Registry, cReg registry.ClusterRegistry, tokenLimit int) http.Handler {
 	sm := http.NewServeMux()
 	cAPI := &client.RegistryClient{Registry: reg}
 
 	for _, prefix := range []string{"/v1-alpha", "/fleet/v1"} {
 		wireUpDiscoveryResource(sm, prefix)
 
+		wireUpEngineResource(sm, prefix, cReg)
+		wireUpEventsResource(sm, prefix, cAPI)
 		wireUpMachinesResource(sm, prefix, tokenLimit, cAPI)
 		wireUpStateResource(sm, prefix, tokenLimit, cAPI)
-		wireUpUnitsResource(sm, prefix, tokenLimit, cAPI)
+		wireUpUnitsResource(sm, prefix, tokenLimit, cAPI, reg, cReg)
 		sm.HandleFunc(prefix, methodNotAllowedHandler)
 	}
 
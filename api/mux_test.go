@@ -40,7 +40,8 @@ func TestDefaultHandlers(t *testing.T) {
 
 	for i, tt := range tests {
 		fr := registry.NewFakeRegistry()
-		hdlr := NewServeMux(fr, testTokenLimit)
+		fcr := registry.NewFakeClusterRegistry(nil, 0)
+		hdlr := NewServeMux(fr, fcr, testTokenLimit)
 		rr := httptest.NewRecorder()
 
 		req, err := http.NewRequest(tt.method, tt.path, nil)
@@ -43,3 +43,12 @@ func isItemPath(base, p string) (item string, matched bool) {
 
 	return
 }
+
+// isItemActionPath determines whether the path addresses the given action
+// of an item of the collection, e.g. /units/foo.service/explain.
+func isItemActionPath(base, p, action string) (item string, matched bool) {
+	if path.Base(p) != action {
+		return
+	}
+	return isItemPath(base, path.Dir(p))
+}
@@ -93,3 +93,25 @@ func TestIsItemPathFail(t *testing.T) {
 		}
 	}
 }
+
+func TestIsItemActionPath(t *testing.T) {
+	tests := []struct {
+		base    string
+		arg     string
+		item    string
+		matched bool
+	}{
+		{"/v1/units", "/v1/units/foo.service/explain", "foo.service", true},
+		{"/v1/units", "/v1/units/foo.service/explain/", "", false},
+		{"/v1/units", "/v1/units/foo.service/other", "", false},
+		{"/v1/units", "/v1/units/explain", "", false},
+		{"/v1/units", "/v1/units/foo/bar/explain", "", false},
+	}
+
+	for i, tt := range tests {
+		item, ok := isItemActionPath(tt.base, tt.arg, "explain")
+		if ok != tt.matched || item != tt.item {
+			t.Errorf("case %d: expected (%q, %t), got (%q, %t)", i, tt.item, tt.matched, item, ok)
+		}
+	}
+}
@@ -18,29 +18,37 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"math"
 	"net/http"
 	"path"
 	"strings"
 
 	"github.com/cea-hpc/fleet/client"
+	"github.com/cea-hpc/fleet/engine"
 	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/log"
 	"github.com/cea-hpc/fleet/pkg"
+	"github.com/cea-hpc/fleet/registry"
 	"github.com/cea-hpc/fleet/schema"
 	"github.com/cea-hpc/fleet/unit"
 
 	gsunit "github.com/coreos/go-systemd/unit"
 )
 
-func wireUpUnitsResource(mux *http.ServeMux, prefix string, tokenLimit int, cAPI client.API) {
+func wireUpUnitsResource(mux *http.ServeMux, prefix string, tokenLimit int, cAPI client.API, reg registry.Registry, cReg registry.ClusterRegistry) {
 	base := path.Join(prefix, "units")
-	ur := unitsResource{cAPI, base, uint16(tokenLimit)}
+	ur := unitsResource{cAPI, reg, cReg, base, uint16(tokenLimit)}
 	mux.Handle(base, &ur)
 	mux.Handle(base+"/", &ur)
 }
 
 type unitsResource struct {
-	cAPI       client.API
+	cAPI client.API
+	// reg and cReg are used to explain the scheduling of units, which
+	// runs the engine schedulers on the server rather than in clients.
+	reg        registry.Registry
+	cReg       registry.ClusterRegistry
 	basePath   string
 	tokenLimit uint16
 }
@@ -53,6 +61,13 @@ func (ur *unitsResource) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		default:
 			sendError(rw, http.StatusMethodNotAllowed, errors.New("only GET supported against this resource"))
 		}
+	} else if item, ok := isItemActionPath(ur.basePath, req.URL.Path, "explain"); ok {
+		switch req.Method {
+		case "GET":
+			ur.explain(rw, req, item)
+		default:
+			sendError(rw, http.StatusMethodNotAllowed, errors.New("only GET supported against this resource"))
+		}
 	} else if item, ok := isItemPath(ur.basePath, req.URL.Path); ok {
 		switch req.Method {
 		case "GET":
@@ -75,13 +90,24 @@ func (ur *unitsResource) set(rw http.ResponseWriter, req *http.Request, item str
 		return
 	}
 
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("unable to read body: %v", err))
+		return
+	}
+
 	var su schema.Unit
-	dec := json.NewDecoder(req.Body)
-	err := dec.Decode(&su)
+	err = json.Unmarshal(body, &su)
 	if err != nil {
 		sendError(rw, http.StatusBadRequest, fmt.Errorf("unable to decode body: %v", err))
 		return
 	}
+	// schema.Unit does not tell a zero replicas count from a missing one
+	var scale struct {
+		Replicas *int64 `json:"replicas"`
+	}
+	json.Unmarshal(body, &scale)
+
 	if su.Name == "" {
 		su.Name = item
 	}
@@ -133,6 +159,16 @@ func (ur *unitsResource) set(rw http.ResponseWriter, req *http.Request, item str
 		return
 	}
 
+	if scale.Replicas != nil {
+		if len(su.DesiredState) != 0 {
+			err := errors.New("cannot provide DesiredState when scaling a unit")
+			sendError(rw, http.StatusBadRequest, err)
+			return
+		}
+		ur.scale(rw, eu, *scale.Replicas)
+		return
+	}
+
 	if len(su.DesiredState) == 0 {
 		err := errors.New("must provide DesiredState to update existing unit")
 		sendError(rw, http.StatusConflict, err)
@@ -234,6 +270,39 @@ func ValidateOptions(opts []*schema.UnitOption) error {
 	j := &job.Job{
 		Unit: *uf,
 	}
+	if err := j.ValidateResources(); err != nil {
+		return err
+	}
+	if err := j.ValidateMetadata(); err != nil {
+		return err
+	}
+	if err := j.ValidatePreferences(); err != nil {
+		return err
+	}
+	if err := j.ValidateGang(); err != nil {
+		return err
+	}
+	if err := j.ValidateReplicas(); err != nil {
+		return err
+	}
+	if err := j.ValidateRescheduleDelay(); err != nil {
+		return err
+	}
+	if err := j.ValidatePriority(); err != nil {
+		return err
+	}
+	if err := j.ValidateStartAfter(); err != nil {
+		return err
+	}
+	if err := j.ValidateMinFleetVersion(); err != nil {
+		return err
+	}
+	if err := j.ValidateHealthCheck(); err != nil {
+		return err
+	}
+	if err := j.ValidateMaxFailures(); err != nil {
+		return err
+	}
 	conflicts := pkg.NewUnsafeSet(j.Conflicts()...)
 	replaces := pkg.NewUnsafeSet(j.Replaces()...)
 	peers := pkg.NewUnsafeSet(j.Peers()...)
@@ -259,6 +328,10 @@ func ValidateOptions(opts []*schema.UnitOption) error {
 		Unit: *uf,
 	}
 	isGlobal := u.IsGlobal()
+	spreadKey, hasSpreadBy := j.SpreadBy()
+	if hasSpreadBy && strings.ContainsAny(spreadKey, "= \t") {
+		return fmt.Errorf("invalid SpreadBy metadata key %q", spreadKey)
+	}
 
 	switch {
 	case hasReqTarget && hasPeers:
@@ -275,6 +348,12 @@ func ValidateOptions(opts []*schema.UnitOption) error {
 		return errors.New("Global cannot be used with Replaces")
 	case hasConflicts && hasReplaces:
 		return errors.New("Conflicts cannot be used with Replaces")
+	case hasReqTarget && hasSpreadBy:
+		return errors.New("MachineID cannot be used with SpreadBy")
+	case isGlobal && hasSpreadBy:
+		return errors.New("Global cannot be used with SpreadBy")
+	case isGlobal && j.Gang() != "":
+		return errors.New("Global cannot be used with Gang")
 	}
 
 	return nil
@@ -300,6 +379,27 @@ func (ur *unitsResource) update(rw http.ResponseWriter, item, ds string) {
 	rw.WriteHeader(http.StatusNoContent)
 }
 
+func (ur *unitsResource) scale(rw http.ResponseWriter, u *schema.Unit, replicas int64) {
+	if _, ok := schema.MapSchemaUnitToUnit(u).RequiredReplicas(); !ok {
+		err := fmt.Errorf("cannot scale %q: not a template unit with Replicas", u.Name)
+		sendError(rw, http.StatusBadRequest, err)
+		return
+	}
+	if replicas < 0 || replicas > math.MaxUint16 {
+		err := fmt.Errorf("invalid replicas %d", replicas)
+		sendError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := ur.cAPI.ScaleUnit(u.Name, int(replicas)); err != nil {
+		log.Errorf("Failed scaling Unit(%s): %v", u.Name, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
 func (ur *unitsResource) destroy(rw http.ResponseWriter, req *http.Request, item string) {
 	u, err := ur.cAPI.Unit(item)
 	if err != nil {
@@ -339,6 +439,90 @@ func (ur *unitsResource) get(rw http.ResponseWriter, req *http.Request, item str
 	sendResponse(rw, http.StatusOK, *u)
 }
 
+func (ur *unitsResource) explain(rw http.ResponseWriter, req *http.Request, item string) {
+	ue, err := ur.explainUnit(item)
+	if err != nil {
+		log.Errorf("Failed explaining Unit(%s) scheduling: %v", item, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if ue == nil {
+		sendError(rw, http.StatusNotFound, errors.New("unit does not exist"))
+		return
+	}
+
+	sendResponse(rw, http.StatusOK, *ue)
+}
+
+// explainUnit evaluates the named Unit against every machine known to the
+// Registry, ranking the machines able to run it with the scheduling strategy
+// reported by the engine leader, and leaving out the machines the leader
+// excluded for it. Nil is returned if the Unit does not exist.
+func (ur *unitsResource) explainUnit(name string) (*schema.UnitExplanation, error) {
+	rUnits, err := ur.reg.Units()
+	if err != nil {
+		return nil, err
+	}
+
+	sUnits, err := ur.reg.Schedule()
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := ur.reg.Machines()
+	if err != nil {
+		return nil, err
+	}
+
+	schedName := engine.DefaultScheduler
+	var exclusions []registry.EngineExclusion
+	if ur.cReg != nil {
+		if reported, err := ur.cReg.EngineScheduler(); err == nil && reported != "" {
+			schedName = reported
+		}
+		exclusions, err = ur.cReg.EngineExclusions()
+		if err != nil {
+			return nil, err
+		}
+	}
+	sched, err := engine.NewScheduler(schedName)
+	if err != nil {
+		return nil, err
+	}
+
+	explanations := engine.Explain(sched, rUnits, sUnits, machines, exclusions, name)
+	if explanations == nil {
+		return nil, nil
+	}
+
+	ue := schema.UnitExplanation{
+		Name:      name,
+		Scheduler: sched.Name(),
+		Machines:  make([]*schema.MachineExplanation, 0, len(explanations)),
+	}
+	for _, sUnit := range sUnits {
+		if sUnit.Name == name {
+			ue.MachineID = sUnit.TargetMachineID
+		}
+	}
+
+	ips := make(map[string]string, len(machines))
+	for _, ms := range machines {
+		ips[ms.ID] = ms.PublicIP
+	}
+	for _, e := range explanations {
+		ue.Machines = append(ue.Machines, &schema.MachineExplanation{
+			MachineID: e.MachineID,
+			PrimaryIP: ips[e.MachineID],
+			Able:      e.Able,
+			Reason:    e.Reason,
+		})
+	}
+
+	return &ue, nil
+}
+
 func (ur *unitsResource) list(rw http.ResponseWriter, req *http.Request) {
 	token, err := findNextPageToken(req.URL, ur.tokenLimit)
 	if err != nil {
@@ -23,10 +23,13 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cea-hpc/fleet/client"
 	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
 	"github.com/cea-hpc/fleet/registry"
 	"github.com/cea-hpc/fleet/schema"
 	"github.com/cea-hpc/fleet/unit"
@@ -43,7 +46,7 @@ func newUnit(t *testing.T, str string) unit.UnitFile {
 func TestUnitsSubResourceNotFound(t *testing.T) {
 	fr := registry.NewFakeRegistry()
 	fAPI := &client.RegistryClient{Registry: fr}
-	ur := &unitsResource{fAPI, "/units", testTokenLimit}
+	ur := &unitsResource{fAPI, fr, nil, "/units", testTokenLimit}
 	rr := httptest.NewRecorder()
 
 	req, err := http.NewRequest("GET", "/units/foo/bar", nil)
@@ -66,7 +69,7 @@ func TestUnitsList(t *testing.T) {
 		{Name: "YYY.service"},
 	})
 	fAPI := &client.RegistryClient{Registry: fr}
-	resource := &unitsResource{fAPI, "/units", testTokenLimit}
+	resource := &unitsResource{fAPI, fr, nil, "/units", testTokenLimit}
 	rw := httptest.NewRecorder()
 	req, err := http.NewRequest("GET", "http://example.com/units", nil)
 	if err != nil {
@@ -103,7 +106,7 @@ func TestUnitsList(t *testing.T) {
 func TestUnitsListBadNextPageToken(t *testing.T) {
 	fr := registry.NewFakeRegistry()
 	fAPI := &client.RegistryClient{Registry: fr}
-	resource := &unitsResource{fAPI, "/units", testTokenLimit}
+	resource := &unitsResource{fAPI, fr, nil, "/units", testTokenLimit}
 	rw := httptest.NewRecorder()
 	req, err := http.NewRequest("GET", "http://example.com/units?nextPageToken=EwBMLg==", nil)
 	if err != nil {
@@ -184,7 +187,7 @@ func TestUnitGet(t *testing.T) {
 		{Name: "YYY.service"},
 	})
 	fAPI := &client.RegistryClient{Registry: fr}
-	resource := &unitsResource{fAPI, "/units", testTokenLimit}
+	resource := &unitsResource{fAPI, fr, nil, "/units", testTokenLimit}
 
 	for i, tt := range tests {
 		rw := httptest.NewRecorder()
@@ -209,6 +212,91 @@ func TestUnitGet(t *testing.T) {
 	}
 }
 
+func TestUnitExplain(t *testing.T) {
+	fr := registry.NewFakeRegistry()
+	fr.SetMachines([]machine.MachineState{
+		{ID: "AAA", PublicIP: "10.0.0.1", Metadata: map[string]string{"region": "us"}},
+		{ID: "BBB", PublicIP: "10.0.0.2", Metadata: map[string]string{"region": "eu"}},
+		{ID: "CCC", PublicIP: "10.0.0.3", Metadata: map[string]string{"region": "us"}},
+	})
+	fr.SetJobs([]job.Job{
+		{Name: "XXX.service", TargetState: job.JobStateLaunched, Unit: newUnit(t, "[X-Fleet]\nMachineMetadata=region=us\nConflicts=YYY.service")},
+		{Name: "YYY.service", TargetState: job.JobStateLaunched, TargetMachineID: "CCC"},
+	})
+	fAPI := &client.RegistryClient{Registry: fr}
+	resource := &unitsResource{fAPI, fr, nil, "/units", testTokenLimit}
+
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://example.com/units/XXX.service/explain", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+	resource.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rw.Code)
+	}
+
+	var ue schema.UnitExplanation
+	if err := json.Unmarshal(rw.Body.Bytes(), &ue); err != nil {
+		t.Fatalf("Received unparseable body: %v", err)
+	}
+
+	expect := schema.UnitExplanation{
+		Name:      "XXX.service",
+		Scheduler: "least-loaded",
+		Machines: []*schema.MachineExplanation{
+			{MachineID: "AAA", PrimaryIP: "10.0.0.1", Able: true, Reason: "ranked 1 of 1 by least-loaded scheduler, weighted load 0"},
+			{MachineID: "BBB", PrimaryIP: "10.0.0.2", Reason: "local Machine metadata insufficient"},
+			{MachineID: "CCC", PrimaryIP: "10.0.0.3", Reason: "found conflict with locally-scheduled Unit([YYY.service])"},
+		},
+	}
+	if !reflect.DeepEqual(expect, ue) {
+		t.Errorf("Unexpected explanation:\nexpected %#v\nreceived %#v", expect, ue)
+	}
+
+	rw = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://example.com/units/ZZZ.service/explain", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+	resource.ServeHTTP(rw, req)
+
+	if err := assertErrorResponse(rw, http.StatusNotFound); err != nil {
+		t.Error(err)
+	}
+
+	// machines excluded by the engine leader are not able to run the unit
+	fcr := registry.NewFakeClusterRegistry(nil, 0)
+	fcr.SetEngineExclusions([]registry.EngineExclusion{
+		{UnitName: "XXX.service", MachineID: "AAA", Until: time.Now().Add(time.Hour)},
+	})
+	resource.cReg = fcr
+
+	rw = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://example.com/units/XXX.service/explain", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+	resource.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rw.Code)
+	}
+	ue = schema.UnitExplanation{}
+	if err := json.Unmarshal(rw.Body.Bytes(), &ue); err != nil {
+		t.Fatalf("Received unparseable body: %v", err)
+	}
+	for _, m := range ue.Machines {
+		if m.Able {
+			t.Errorf("Expected no machine able to run XXX.service, got %#v", m)
+		}
+		if m.MachineID == "AAA" && m.Reason != "unit failed too often on local Machine" {
+			t.Errorf("Expected Machine(AAA) to be excluded, got %q", m.Reason)
+		}
+	}
+}
+
 func TestUnitsDestroy(t *testing.T) {
 	tests := []struct {
 		// initial state of registry
@@ -247,7 +335,7 @@ func TestUnitsDestroy(t *testing.T) {
 		}
 
 		fAPI := &client.RegistryClient{Registry: fr}
-		resource := &unitsResource{fAPI, "/units", testTokenLimit}
+		resource := &unitsResource{fAPI, fr, nil, "/units", testTokenLimit}
 		rw := httptest.NewRecorder()
 		resource.destroy(rw, req, tt.arg)
 
@@ -415,7 +503,7 @@ func TestUnitsSetDesiredState(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 
 		fAPI := &client.RegistryClient{Registry: fr}
-		resource := &unitsResource{fAPI, "/units", testTokenLimit}
+		resource := &unitsResource{fAPI, fr, nil, "/units", testTokenLimit}
 		rw := httptest.NewRecorder()
 		resource.set(rw, req, tt.item)
 
@@ -446,6 +534,61 @@ func TestUnitsSetDesiredState(t *testing.T) {
 	}
 }
 
+func TestUnitsSetReplicas(t *testing.T) {
+	tests := []struct {
+		// item path (name) of the Unit
+		item string
+		// raw request body
+		body string
+		// expected HTTP status code
+		code int
+		// expected replicas of the Unit after request
+		replicas int
+	}{
+		{"web@.service", `{"replicas":8}`, http.StatusNoContent, 8},
+		{"web@.service", `{"replicas":0}`, http.StatusNoContent, 0},
+		{"web@.service", `{"replicas":-1}`, http.StatusBadRequest, 5},
+		{"web@.service", `{"replicas":8,"desiredState":"inactive"}`, http.StatusBadRequest, 5},
+		// only templates setting Replicas can be scaled
+		{"api@.service", `{"replicas":8}`, http.StatusBadRequest, 0},
+		{"web@1.service", `{"replicas":8}`, http.StatusBadRequest, 0},
+		// setting the desired state is unaffected
+		{"web@.service", `{"desiredState":"inactive"}`, http.StatusNoContent, 5},
+	}
+
+	for i, tt := range tests {
+		fr := registry.NewFakeRegistry()
+		fr.SetJobs([]job.Job{
+			job.Job{Name: "web@.service", Unit: newUnit(t, "[X-Fleet]\nReplicas=5")},
+			job.Job{Name: "web@1.service", Unit: newUnit(t, "[X-Fleet]\nReplicas=5")},
+			job.Job{Name: "api@.service", Unit: newUnit(t, "[Service]\nFoo=Bar")},
+		})
+
+		req, err := http.NewRequest("PUT", fmt.Sprintf("http://example.com/units/%s", tt.item), strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		fAPI := &client.RegistryClient{Registry: fr}
+		resource := &unitsResource{fAPI, fr, nil, "/units", testTokenLimit}
+		rw := httptest.NewRecorder()
+		resource.set(rw, req, tt.item)
+
+		if rw.Code != tt.code {
+			t.Errorf("case %d: expected %d, got %d", i, tt.code, rw.Code)
+		}
+
+		u, err := fr.Unit(tt.item)
+		if err != nil || u == nil {
+			t.Fatalf("case %d: failed fetching Unit(%s): %v", i, tt.item, err)
+		}
+		if u.Replicas != tt.replicas {
+			t.Errorf("case %d: expected %d replicas, got %d", i, tt.replicas, u.Replicas)
+		}
+	}
+}
+
 func makeConflictUO(name string) *schema.UnitOption {
 	return &schema.UnitOption{
 		Section: "X-Fleet",
@@ -721,6 +864,336 @@ func TestValidateOptions(t *testing.T) {
 			},
 			false,
 		},
+		// Resource reservations must be valid numbers
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "ReserveCores",
+					Value:   "1.5",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "ReserveMemory",
+					Value:   "512",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "ReserveMemory",
+					Value:   "lots",
+				},
+			},
+			false,
+		},
+		// SpreadBy takes a single metadata key
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "SpreadBy",
+					Value:   "rack",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "SpreadBy",
+					Value:   "rack=r1",
+				},
+			},
+			false,
+		},
+		// Priority must be an integer
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Priority",
+					Value:   "-10",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Priority",
+					Value:   "critical",
+				},
+			},
+			false,
+		},
+		// MinFleetVersion must be a semantic version
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MinFleetVersion",
+					Value:   "1.1.0",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MachineCapabilities",
+					Value:   "GRPC",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MinFleetVersion",
+					Value:   "latest",
+				},
+			},
+			false,
+		},
+		// HealthCheck must have a known type and a valid target
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "HealthCheck",
+					Value:   "http http://127.0.0.1:8080/health",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "HealthCheckInterval",
+					Value:   "30s",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "HealthCheck",
+					Value:   "ping 127.0.0.1",
+				},
+			},
+			false,
+		},
+		// MaxFailures must be a positive integer and FailureWindow a
+		// positive duration
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MaxFailures",
+					Value:   "3",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "FailureWindow",
+					Value:   "15m",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MaxFailures",
+					Value:   "0",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MaxFailures",
+					Value:   "3",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "FailureWindow",
+					Value:   "-1m",
+				},
+			},
+			false,
+		},
+		// StartAfter must name units
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "StartAfter",
+					Value:   "db.service etcd.service",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "StartAfter",
+					Value:   "db",
+				},
+			},
+			false,
+		},
+		// Gang cannot be used with Global
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Gang",
+					Value:   "solver",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "GangSize",
+					Value:   "4",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Gang",
+					Value:   "solver",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Global",
+					Value:   "true",
+				},
+			},
+			false,
+		},
+		// Replicas must be a non-negative integer
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Replicas",
+					Value:   "5",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Replicas",
+					Value:   "five",
+				},
+			},
+			false,
+		},
+		// RescheduleDelay must be a non-negative duration
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "RescheduleDelay",
+					Value:   "2m",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "RescheduleDelay",
+					Value:   "-2m",
+				},
+			},
+			false,
+		},
+		// MachineMetadata expressions must be valid
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MachineMetadata",
+					Value:   "cores>=32|gpu",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MachineMetadata",
+					Value:   "!maintenance",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MachineMetadata",
+					Value:   "host=~[",
+				},
+			},
+			false,
+		},
+		// Preferences must be well-formed
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "PreferMachineMetadata",
+					Value:   "disk=ssd weight=10",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "PreferNotColocatedWith",
+					Value:   "db.service weight=-1",
+				},
+			},
+			false,
+		},
+		// SpreadBy with MachineID or Global no good
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "SpreadBy",
+					Value:   "rack",
+				},
+				makeIDUO("abcdefghi"),
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "SpreadBy",
+					Value:   "rack",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Global",
+					Value:   "true",
+				},
+			},
+			false,
+		},
 	}
 	for i, tt := range testCases {
 		err := ValidateOptions(tt.opts)
@@ -805,7 +1278,7 @@ func TestValidateName(t *testing.T) {
 func TestUnitsSetDesiredStateBadContentType(t *testing.T) {
 	fr := registry.NewFakeRegistry()
 	fAPI := &client.RegistryClient{Registry: fr}
-	resource := &unitsResource{fAPI, "/units", testTokenLimit}
+	resource := &unitsResource{fAPI, fr, nil, "/units", testTokenLimit}
 	rr := httptest.NewRecorder()
 
 	body := ioutil.NopCloser(bytes.NewBuffer([]byte(`{"foo":"bar"}`)))
@@ -23,13 +23,19 @@ type API interface {
 	Machines() ([]machine.MachineState, error)
 	SetMachineMetadata(machID, key, value string) error
 	DeleteMachineMetadata(machID, key string) error
+	SetMachineMaintenance(machID, mode string) error
 
 	Unit(string) (*schema.Unit, error)
 	Units() ([]*schema.Unit, error)
 	UnitState(string) (*schema.UnitState, error)
 	UnitStates() ([]*schema.UnitState, error)
+	UnitExplanation(string) (*schema.UnitExplanation, error)
+	// EngineEvents returns the recent scheduling decisions of the engine
+	// leaders, oldest first, optionally restricted to a unit or a machine.
+	EngineEvents(unitName, machineID string) ([]*schema.EngineEvent, error)
 
 	SetUnitTargetState(name, target string) error
+	ScaleUnit(name string, replicas int) error
 	CreateUnit(*schema.Unit) error
 	DestroyUnit(string) error
 }
@@ -125,6 +125,30 @@ func (c *HTTPClient) UnitState(name string) (*schema.UnitState, error) {
 	return u, nil
 }
 
+func (c *HTTPClient) UnitExplanation(name string) (*schema.UnitExplanation, error) {
+	ue, err := c.svc.Units.Explain(name).Do()
+	if err != nil && !is404(err) {
+		return nil, err
+	}
+	return ue, nil
+}
+
+func (c *HTTPClient) EngineEvents(unitName, machineID string) ([]*schema.EngineEvent, error) {
+	call := c.svc.Events.List()
+	if unitName != "" {
+		call.UnitName(unitName)
+	}
+	if machineID != "" {
+		call.MachineID(machineID)
+	}
+
+	page, err := call.Do()
+	if err != nil {
+		return nil, err
+	}
+	return page.Events, nil
+}
+
 func (c *HTTPClient) DestroyUnit(name string) error {
 	return c.svc.Units.Delete(name).Do()
 }
@@ -141,6 +165,30 @@ func (c *HTTPClient) SetUnitTargetState(name, target string) error {
 	return c.svc.Units.Set(name, &u).Do()
 }
 
+func (c *HTTPClient) ScaleUnit(name string, replicas int) error {
+	u := schema.Unit{
+		Name:     name,
+		Replicas: int64(replicas),
+		// scaling down to zero instances must be sent as well
+		ForceSendFields: []string{"Replicas"},
+	}
+	return c.svc.Units.Set(name, &u).Do()
+}
+
+// SetMachineMaintenance sends a JSONPatch request replacing, or removing if
+// the mode is empty, the maintenance mode of the machine.
+func (c *HTTPClient) SetMachineMaintenance(machID, mode string) error {
+	op := schema.MachineOperation{
+		Op:   "remove",
+		Path: path.Join("/", machID, "maintenance"),
+	}
+	if mode != "" {
+		op.Op = "replace"
+		op.Value = &schema.MachineOperationValue{Value: mode}
+	}
+	return c.svc.Machines.Patch(&schema.MachineOperationList{&op}).Do()
+}
+
 func is404(err error) bool {
 	googerr, ok := err.(*googleapi.Error)
 	return ok && googerr.Code == http.StatusNotFound
@@ -15,8 +15,13 @@
 package client
 
 import (
+	"errors"
+	"time"
+
 	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
 	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/resource"
 	"github.com/cea-hpc/fleet/schema"
 )
 
@@ -24,6 +29,85 @@ type RegistryClient struct {
 	registry.Registry
 }
 
+// Machines returns the machines known to the Registry. The FreeResources of
+// every machine advertising its capacity, and the used capacity of every
+// machine with limits, are computed from the units currently scheduled to it.
+func (rc *RegistryClient) Machines() ([]machine.MachineState, error) {
+	machines, err := rc.Registry.Machines()
+	if err != nil {
+		return nil, err
+	}
+
+	accounted := func(ms *machine.MachineState) bool {
+		return !ms.TotalResources.Empty() || ms.Limited()
+	}
+
+	advertised := false
+	for i := range machines {
+		if accounted(&machines[i]) {
+			advertised = true
+			break
+		}
+	}
+	if !advertised {
+		return machines, nil
+	}
+
+	rUnits, err := rc.Registry.Units()
+	if err != nil {
+		return nil, err
+	}
+
+	sUnits, err := rc.Registry.Schedule()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]string, len(sUnits))
+	for _, sUnit := range sUnits {
+		targets[sUnit.Name] = sUnit.TargetMachineID
+	}
+
+	// the Units are ordered by name, which is the order the engine places
+	// global Units in
+	var gUnits []*job.Unit
+	for i := range rUnits {
+		if rUnits[i].IsGlobal() {
+			gUnits = append(gUnits, &rUnits[i])
+		}
+	}
+
+	for i := range machines {
+		ms := &machines[i]
+		if !accounted(ms) {
+			continue
+		}
+
+		units := make(map[string]*job.Unit)
+		for i := range rUnits {
+			ru := &rUnits[i]
+			if ru.TargetState == job.JobStateInactive || ru.IsGlobal() || targets[ru.Name] != ms.ID {
+				continue
+			}
+			units[ru.Name] = ru
+		}
+		job.AddGlobalUnits(ms, units, gUnits)
+
+		reserved := []resource.ResourceTuple{resource.HostResources}
+		ms.UsedUnits, ms.UsedWeight = 0, 0
+		for _, ru := range units {
+			reserved = append(reserved, ru.Resources())
+			ms.UsedUnits++
+			ms.UsedWeight += ru.EffectiveWeight()
+		}
+		if !ms.TotalResources.Empty() {
+			ms.FreeResources = resource.Sub(ms.TotalResources, resource.Sum(reserved...))
+		}
+	}
+
+	return machines, nil
+}
+
 func (rc *RegistryClient) Units() ([]*schema.Unit, error) {
 	rUnits, err := rc.Registry.Units()
 	if err != nil {
@@ -109,6 +193,53 @@ func (rc *RegistryClient) UnitStates() ([]*schema.UnitState, error) {
 	return states, nil
 }
 
+// EngineEvents returns the scheduling decisions recorded in the Registry by
+// the engine leaders. A decision matches a machine if the unit was
+// scheduled to or moved away from it.
+func (rc *RegistryClient) EngineEvents(unitName, machineID string) ([]*schema.EngineEvent, error) {
+	events := make([]*schema.EngineEvent, 0)
+
+	cReg, ok := rc.Registry.(registry.ClusterRegistry)
+	if !ok {
+		return events, nil
+	}
+	recorded, err := cReg.EngineEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ev := range recorded {
+		if unitName != "" && ev.UnitName != unitName {
+			continue
+		}
+		if machineID != "" && ev.MachineID != machineID && ev.PreviousMachineID != machineID {
+			continue
+		}
+		events = append(events, &schema.EngineEvent{
+			Time:              ev.Time.UTC().Format(time.RFC3339),
+			LeaderID:          ev.LeaderID,
+			Type:              ev.Type,
+			UnitName:          ev.UnitName,
+			MachineID:         ev.MachineID,
+			PreviousMachineID: ev.PreviousMachineID,
+			Reason:            ev.Reason,
+		})
+	}
+
+	return events, nil
+}
+
+// UnitExplanation is not supported by the RegistryClient: explaining the
+// scheduling of a Unit runs the engine schedulers, which is only done by
+// the fleet API, so that clients need not embed the engine.
+func (rc *RegistryClient) UnitExplanation(name string) (*schema.UnitExplanation, error) {
+	return nil, errors.New("explaining units requires the fleet API, it is not available with the etcd driver")
+}
+
 func (rc *RegistryClient) SetUnitTargetState(name, target string) error {
 	return rc.Registry.SetUnitTargetState(name, job.JobState(target))
 }
+
+func (rc *RegistryClient) ScaleUnit(name string, replicas int) error {
+	return rc.Registry.ScaleUnit(name, replicas)
+}
@@ -18,30 +18,47 @@ import (
 	"strings"
 
 	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/resource"
 )
 
 type Config struct {
-	EtcdServers             []string
-	EtcdUsername            string
-	EtcdPassword            string
-	EtcdKeyPrefix           string
-	EtcdKeyFile             string
-	EtcdCertFile            string
-	EtcdCAFile              string
-	EtcdRequestTimeout      float64
-	EngineReconcileInterval float64
-	PublicIP                string
-	Verbosity               int
-	RawMetadata             string
-	AgentTTL                string
-	TokenLimit              int
-	DisableEngine           bool
-	DisableWatches          bool
-	EnableGRPC              bool
-	VerifyUnits             bool
-	UnitsDirectory          string
-	SystemdUser             bool
-	AuthorizedKeysFile      string
+	EtcdServers                    []string
+	EtcdUsername                   string
+	EtcdPassword                   string
+	EtcdKeyPrefix                  string
+	EtcdKeyFile                    string
+	EtcdCertFile                   string
+	EtcdCAFile                     string
+	EtcdRequestTimeout             float64
+	EngineReconcileInterval        float64
+	EngineScheduler                string
+	EngineRebalanceThreshold       int
+	EngineRebalanceMaxMoves        int
+	EngineRescheduleDelay          string
+	EngineResyncInterval           float64
+	EngineWarmStandby              bool
+	EngineMaxSchedules             int
+	EngineMaxUnschedules           int
+	EngineMaxSchedulesPerMachine   int
+	EngineMaxUnschedulesPerMachine int
+	PublicIP                       string
+	Verbosity                      int
+	RawMetadata                    string
+	TotalCores                     int
+	TotalMemory                    int
+	TotalDisk                      int
+	MaxUnits                       int
+	MaxWeight                      int
+	AgentTTL                       string
+	AgentTaskParallelism           int
+	TokenLimit                     int
+	DisableEngine                  bool
+	DisableWatches                 bool
+	EnableGRPC                     bool
+	VerifyUnits                    bool
+	UnitsDirectory                 string
+	SystemdUser                    bool
+	AuthorizedKeysFile             string
 }
 
 func (c *Config) Capabilities() machine.Capabilities {
@@ -51,6 +68,16 @@ func (c *Config) Capabilities() machine.Capabilities {
 	}
 }
 
+// TotalResources returns the machine capacity declared in the
+// configuration. Zero values are detected from the local host instead.
+func (c *Config) TotalResources() resource.ResourceTuple {
+	return resource.ResourceTuple{
+		Cores:  c.TotalCores * 100,
+		Memory: c.TotalMemory,
+		Disk:   c.TotalDisk,
+	}
+}
+
 func (c *Config) Metadata() map[string]string {
 	meta := make(map[string]string, 0)
 
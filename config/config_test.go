@@ -44,3 +44,20 @@ func TestConfigMetadataNotSet(t *testing.T) {
 		t.Errorf("Parsed %d keys, expected 0", len(metadata))
 	}
 }
+
+func TestConfigTotalResources(t *testing.T) {
+	cfg := Config{TotalCores: 4, TotalMemory: 8192}
+	res := cfg.TotalResources()
+
+	if res.Cores != 400 {
+		t.Errorf("Incorrect Cores %d, expected 400", res.Cores)
+	}
+
+	if res.Memory != 8192 {
+		t.Errorf("Incorrect Memory %d, expected 8192", res.Memory)
+	}
+
+	if res.Disk != 0 {
+		t.Errorf("Incorrect Disk %d, expected 0", res.Disk)
+	}
+}
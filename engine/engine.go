@@ -18,12 +18,14 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/log"
 	"github.com/cea-hpc/fleet/machine"
 	"github.com/cea-hpc/fleet/metrics"
 	"github.com/cea-hpc/fleet/pkg"
 	"github.com/cea-hpc/fleet/pkg/lease"
 	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/unit"
 )
 
 const (
@@ -44,6 +46,26 @@ type Engine struct {
 
 	lease lease.Lease
 
+	// model, when set, keeps the cluster state between rounds, updated
+	// from the changes emitted by the changes stream
+	model   *clusterModel
+	changes registry.UnitChangeStream
+	// warmStandby makes the model be kept up to date while the Engine is
+	// not the leader
+	warmStandby bool
+
+	// leading is set while the Engine is the leader, and leaderSince
+	// records when it became leader until it completes its first
+	// reconciliation
+	leading     bool
+	leaderSince time.Time
+
+	history decisionHistory
+
+	// schedReported is set once the leader has published the name of its
+	// scheduling strategy in the Registry
+	schedReported bool
+
 	updateEngineState func(newEngine machine.MachineState)
 }
 
@@ -52,8 +74,8 @@ type CompleteRegistry interface {
 	registry.ClusterRegistry
 }
 
-func New(reg CompleteRegistry, lManager lease.Manager, rStream pkg.EventStream, mach machine.Machine, updateEngineState func(newEngine machine.MachineState)) *Engine {
-	rec := NewReconciler()
+func New(reg CompleteRegistry, lManager lease.Manager, rStream pkg.EventStream, mach machine.Machine, sched Scheduler, rebal *Rebalancer, rescheduleDelay time.Duration, updateEngineState func(newEngine machine.MachineState)) *Engine {
+	rec := NewReconciler(sched, rebal, rescheduleDelay)
 	return &Engine{
 		rec:               rec,
 		registry:          reg,
@@ -65,6 +87,29 @@ func New(reg CompleteRegistry, lManager lease.Manager, rStream pkg.EventStream,
 	}
 }
 
+// SetIncremental makes the Engine keep the cluster state between
+// reconciliations, and only read back from the Registry the Units emitted
+// by the UnitChangeStream. The whole cluster state is still read once
+// every resyncInterval.
+func (e *Engine) SetIncremental(changes registry.UnitChangeStream, resyncInterval time.Duration) {
+	e.changes = changes
+	e.model = newClusterModel(resyncInterval)
+}
+
+// SetThrottle caps the number of tasks the Engine issues per
+// reconciliation.
+func (e *Engine) SetThrottle(th *Throttle) {
+	e.rec.throttle = th
+}
+
+// SetWarmStandby makes the Engine keep its cluster state up to date while
+// it is not the leader, so that it starts reconciling as soon as it becomes
+// leader, without reading the whole cluster state first. It only applies to
+// an Engine reconciling incrementally.
+func (e *Engine) SetWarmStandby(warm bool) {
+	e.warmStandby = warm
+}
+
 func (e *Engine) Run(ival time.Duration, stop <-chan struct{}) {
 	leaseTTL := ival * 5
 	if e.machine.State().Capabilities.Has(machine.CapGRPC) {
@@ -103,9 +148,29 @@ func (e *Engine) Run(ival time.Duration, stop <-chan struct{}) {
 		}
 
 		if !isLeader(e.lease, machID) {
+			e.schedReported = false
+			e.leading = false
+			e.history.reset()
+			if e.model != nil {
+				if e.warmStandby {
+					e.model.refresh(e.registry)
+				} else {
+					e.model.reset()
+				}
+			}
 			return
 		}
 
+		if !e.leading {
+			e.leading = true
+			e.leaderSince = time.Now()
+			e.rec.resetFailures()
+		}
+
+		if !e.schedReported {
+			e.schedReported = reportEngineScheduler(e.cRegistry, e.rec.sched.Name())
+		}
+
 		// abort is closed when reconciliation must stop prematurely, either
 		// by a local timeout or the fleet server shutting down
 		abort := make(chan struct{})
@@ -130,6 +195,13 @@ func (e *Engine) Run(ival time.Duration, stop <-chan struct{}) {
 		elapsed := time.Now().Sub(start)
 		metrics.ReportEngineReconcileSuccess(start)
 
+		if !e.leaderSince.IsZero() {
+			handover := time.Since(e.leaderSince)
+			log.Infof("Engine completed its first reconciliation as leader in %s", handover)
+			metrics.ReportEngineLeaderHandover(handover)
+			e.leaderSince = time.Time{}
+		}
+
 		msg := fmt.Sprintf("Engine completed reconciliation in %s", elapsed)
 		if elapsed > ival {
 			log.Warning(msg)
@@ -138,6 +210,10 @@ func (e *Engine) Run(ival time.Duration, stop <-chan struct{}) {
 		}
 	}
 
+	if e.model != nil {
+		go e.model.watch(e.changes, stop)
+	}
+
 	rec := pkg.NewPeriodicReconciler(ival, reconcile, e.rStream)
 	rec.Run(stop)
 }
@@ -188,6 +264,18 @@ func ensureEngineVersionMatch(cReg registry.ClusterRegistry, expect int) bool {
 	return true
 }
 
+// reportEngineScheduler publishes the name of the scheduling strategy used
+// by the engine leader, returning true on success.
+func reportEngineScheduler(cReg registry.ClusterRegistry, name string) bool {
+	if err := cReg.SetEngineScheduler(name); err != nil {
+		log.Errorf("Failed reporting engine scheduler %q: %v", name, err)
+		return false
+	}
+
+	log.Infof("Engine scheduling units using the %s strategy", name)
+	return true
+}
+
 func acquireLeadership(lManager lease.Manager, machID string, ver int, ttl time.Duration) lease.Lease {
 	existing, err := lManager.GetLease(engineLeaseName)
 	if err != nil {
@@ -248,19 +336,27 @@ func renewLeadership(l lease.Lease, ttl time.Duration) lease.Lease {
 }
 
 func (e *Engine) clusterState() (*clusterState, error) {
-	units, err := e.registry.Units()
+	if e.model != nil {
+		return e.model.clusterState(e.registry)
+	}
+	return loadClusterState(e.registry)
+}
+
+// loadClusterState reads the whole cluster state from the Registry.
+func loadClusterState(reg registry.Registry) (*clusterState, error) {
+	units, err := reg.Units()
 	if err != nil {
 		log.Errorf("Failed fetching Units from Registry: %v", err)
 		return nil, err
 	}
 
-	sUnits, err := e.registry.Schedule()
+	sUnits, err := reg.Schedule()
 	if err != nil {
 		log.Errorf("Failed fetching schedule from Registry: %v", err)
 		return nil, err
 	}
 
-	machines, err := e.registry.Machines()
+	machines, err := reg.Machines()
 	if err != nil {
 		log.Errorf("Failed fetching Machines from Registry: %v", err)
 		return nil, err
@@ -292,3 +388,46 @@ func (e *Engine) attemptScheduleUnit(name, machID string) bool {
 	log.Infof("Scheduled Unit(%s) to Machine(%s)", name, machID)
 	return true
 }
+
+// createInstance creates an instance of a replicated template in the
+// Registry, from the unit file of the template and with a launched target
+// state.
+func (e *Engine) createInstance(name string) (err error) {
+	uni := unit.NewUnitNameInfo(name)
+	if uni == nil || !uni.IsInstance() {
+		return fmt.Errorf("Unit(%s) is not an instance", name)
+	}
+
+	tmpl, err := e.registry.Unit(uni.Template)
+	if err != nil {
+		return err
+	}
+	if tmpl == nil {
+		return fmt.Errorf("Template(%s) of Unit(%s) not found", uni.Template, name)
+	}
+
+	u := &job.Unit{
+		Name:        name,
+		Unit:        tmpl.Unit,
+		TargetState: job.JobStateLaunched,
+	}
+	err = e.registry.CreateUnit(u)
+	if err != nil {
+		log.Errorf("Failed creating Unit(%s) from Template(%s): %v", name, uni.Template, err)
+	} else {
+		log.Infof("Created Unit(%s) from Template(%s)", name, uni.Template)
+	}
+	return
+}
+
+// destroyUnit removes an instance of a replicated template from the
+// Registry.
+func (e *Engine) destroyUnit(name string) (err error) {
+	err = e.registry.DestroyUnit(name)
+	if err != nil {
+		log.Errorf("Failed destroying Unit(%s): %v", name, err)
+	} else {
+		log.Infof("Destroyed Unit(%s)", name)
+	}
+	return
+}
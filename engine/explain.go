@@ -0,0 +1,125 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cea-hpc/fleet/agent"
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+// Explanation tells whether a machine is able to run a unit and, if so, how
+// the scheduler ranks it among the other machines able to run the unit.
+type Explanation struct {
+	MachineID string
+	Able      bool
+	Reason    string
+}
+
+// agentSorter is implemented by the schedulers considering agents in a
+// deterministic order.
+type agentSorter interface {
+	sortedAgents(*clusterState) []*agent.AgentState
+}
+
+// Explain evaluates the named unit against every machine of the cluster
+// formed by the given units, schedule and machines, the way the engine
+// using the given scheduler would. The unit is kept away from the machines
+// of the given exclusions which have not expired yet. One Explanation is
+// returned per machine, in the order the scheduler considers them. Nil is
+// returned if the unit does not exist.
+func Explain(sched Scheduler, units []job.Unit, sUnits []job.ScheduledUnit, machines []machine.MachineState, exclusions []registry.EngineExclusion, name string) []Explanation {
+	clust := newClusterState(units, sUnits, machines)
+
+	j, ok := clust.jobs[name]
+	if !ok {
+		gu, ok := clust.gUnits[name]
+		if !ok {
+			return nil
+		}
+		j = &job.Job{
+			Name:        gu.Name,
+			Unit:        gu.Unit,
+			TargetState: gu.TargetState,
+		}
+	}
+
+	now := time.Now()
+	for _, ex := range exclusions {
+		if ex.UnitName == j.Name && now.Before(ex.Until) {
+			j.ExcludedMachines = append(j.ExcludedMachines, ex.MachineID)
+		}
+	}
+
+	var agents []*agent.AgentState
+	if s, ok := sched.(agentSorter); ok {
+		agents = s.sortedAgents(clust)
+	} else {
+		agents = sortedAgentsByID(clust)
+	}
+	agents = rankAgents(agents, j)
+
+	able := make([]*agent.AgentState, 0)
+	reasons := make(map[string]string, len(agents))
+	for _, as := range agents {
+		if clust.gUnits[j.Name] != nil {
+			delete(as.Units, j.Name)
+		}
+		if act, reason := as.AbleToRun(j); act == job.JobActionUnschedule {
+			reasons[as.MState.ID] = reason
+			continue
+		}
+		able = append(able, as)
+	}
+
+	best := make(map[string]bool)
+	for _, as := range bestRanked(agents, able, j) {
+		best[as.MState.ID] = true
+	}
+
+	explanations := make([]Explanation, 0, len(agents))
+	rank := 0
+	for _, as := range agents {
+		id := as.MState.ID
+		if reason, ok := reasons[id]; ok {
+			explanations = append(explanations, Explanation{MachineID: id, Reason: reason})
+			continue
+		}
+
+		rank++
+		var reason string
+		switch {
+		case clust.gUnits[j.Name] != nil:
+			reason = "global unit runs on every able machine"
+		case j.TargetMachineID == id:
+			reason = "unit is scheduled to this machine"
+		case sched.Name() == SchedulerRandomSpread:
+			if best[id] {
+				reason = fmt.Sprintf("one of %d best ranked machines picked at random by %s scheduler", len(best), sched.Name())
+			} else {
+				reason = fmt.Sprintf("ranked below the %d best ranked machines by %s scheduler", len(best), sched.Name())
+			}
+		default:
+			reason = fmt.Sprintf("ranked %d of %d by %s scheduler, weighted load %d", rank, len(able), sched.Name(), agentLoad(as))
+		}
+		explanations = append(explanations, Explanation{MachineID: id, Able: true, Reason: reason})
+	}
+
+	return explanations
+}
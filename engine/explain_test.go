@@ -0,0 +1,109 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+func TestExplain(t *testing.T) {
+	units := []job.Unit{
+		job.Unit{Name: "foo.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "MachineOf=bar.service")},
+		job.Unit{Name: "bar.service", TargetState: job.JobStateLaunched},
+		job.Unit{Name: "baz.service", TargetState: job.JobStateLaunched, Unit: newUnitWithMetadata(t, "disk=ssd")},
+		job.Unit{Name: "global.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Global=true\nMachineMetadata=disk=ssd")},
+	}
+	sUnits := []job.ScheduledUnit{
+		job.ScheduledUnit{Name: "bar.service", TargetMachineID: "BBB"},
+		job.ScheduledUnit{Name: "baz.service", TargetMachineID: "BBB"},
+	}
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "AAA", Metadata: map[string]string{"disk": "ssd"}},
+		machine.MachineState{ID: "BBB", Metadata: map[string]string{"disk": "ssd"}},
+		machine.MachineState{ID: "CCC"},
+	}
+
+	now := time.Now()
+	tests := []struct {
+		sched      Scheduler
+		exclusions []registry.EngineExclusion
+		name       string
+		want       []Explanation
+	}{
+		{
+			&leastLoadedScheduler{},
+			nil,
+			"foo.service",
+			[]Explanation{
+				{MachineID: "CCC", Reason: "required peer Unit(bar.service) is not scheduled locally"},
+				{MachineID: "AAA", Reason: "required peer Unit(bar.service) is not scheduled locally"},
+				{MachineID: "BBB", Able: true, Reason: "ranked 1 of 1 by least-loaded scheduler, weighted load 3"},
+			},
+		},
+		{
+			&binPackScheduler{},
+			nil,
+			"bar.service",
+			[]Explanation{
+				{MachineID: "BBB", Able: true, Reason: "unit is scheduled to this machine"},
+				{MachineID: "AAA", Able: true, Reason: "ranked 2 of 3 by bin-pack scheduler, weighted load 1"},
+				{MachineID: "CCC", Able: true, Reason: "ranked 3 of 3 by bin-pack scheduler, weighted load 0"},
+			},
+		},
+		{
+			&leastLoadedScheduler{},
+			nil,
+			"global.service",
+			[]Explanation{
+				{MachineID: "CCC", Reason: "local Machine metadata insufficient"},
+				{MachineID: "AAA", Able: true, Reason: "global unit runs on every able machine"},
+				{MachineID: "BBB", Able: true, Reason: "global unit runs on every able machine"},
+			},
+		},
+		{
+			&binPackScheduler{},
+			[]registry.EngineExclusion{
+				{UnitName: "bar.service", MachineID: "AAA", Until: now.Add(time.Hour)},
+				{UnitName: "bar.service", MachineID: "CCC", Until: now.Add(-time.Hour)},
+				{UnitName: "foo.service", MachineID: "CCC", Until: now.Add(time.Hour)},
+			},
+			"bar.service",
+			[]Explanation{
+				{MachineID: "BBB", Able: true, Reason: "unit is scheduled to this machine"},
+				{MachineID: "AAA", Reason: "unit failed too often on local Machine"},
+				{MachineID: "CCC", Able: true, Reason: "ranked 2 of 2 by bin-pack scheduler, weighted load 0"},
+			},
+		},
+		{
+			&leastLoadedScheduler{},
+			nil,
+			"missing.service",
+			nil,
+		},
+	}
+
+	for i, tt := range tests {
+		got := Explain(tt.sched, units, sUnits, machines, tt.exclusions, tt.name)
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected explanations\nexpected %#v\ngot      %#v", i, tt.want, got)
+		}
+	}
+}
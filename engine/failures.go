@@ -0,0 +1,234 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/log"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+// failureRecord follows the failures of a Job on the machine it is
+// scheduled to, as counted by the agent of that machine. The Job turning
+// unhealthy also counts as a failure.
+type failureRecord struct {
+	machineID string
+	// count is the number of failures last reported for the Job
+	count int
+	// unhealthy is set while the Job is last reported unhealthy
+	unhealthy bool
+	// times holds when each failure still within the failure window of
+	// the Job was first seen
+	times []time.Time
+}
+
+// resetFailures forgets the failures and the exclusions followed during a
+// previous leadership, and has the next reconciliation rebuild the failure
+// records from the failures published by the agents. As their times are not
+// published, these failures are considered to have occurred when the unit
+// last entered the inactive state.
+func (r *Reconciler) resetFailures() {
+	r.failures = make(map[string]*failureRecord)
+	r.excluded = make(map[string]map[string]time.Time)
+	r.rebuildFailures = true
+	r.published, r.exclusionsPublished = nil, false
+}
+
+// trackFailures records the failures of the Jobs limiting them, as reported
+// by the UnitStates of the cluster, and forgets about the exclusions which
+// expired. Failures which occurred before the Job was first seen on its
+// machine are not counted, unless the engine just took the lead. Every Job is given the machines it is currently
+// excluded from.
+func (r *Reconciler) trackFailures(clust *clusterState) {
+	now := r.clock.Now()
+
+	for name, machines := range r.excluded {
+		j, ok := clust.jobs[name]
+		if ok {
+			_, _, ok = j.MaxFailures()
+		}
+		if !ok {
+			delete(r.excluded, name)
+			continue
+		}
+		for id, until := range machines {
+			if !now.Before(until) {
+				log.Infof("Job(%s) no longer excluded from Machine(%s)", name, id)
+				delete(machines, id)
+			}
+		}
+		if len(machines) == 0 {
+			delete(r.excluded, name)
+		}
+	}
+	for _, j := range clust.jobs {
+		j.ExcludedMachines = r.excludedMachines(j.Name)
+	}
+
+	rebuild := r.rebuildFailures
+	r.rebuildFailures = false
+	if clust.unitStates == nil {
+		return
+	}
+
+	for name, rec := range r.failures {
+		if j, ok := clust.jobs[name]; !ok || j.TargetMachineID != rec.machineID {
+			delete(r.failures, name)
+		}
+	}
+
+	for _, j := range clust.jobs {
+		_, window, ok := j.MaxFailures()
+		if !ok || !j.Scheduled() {
+			continue
+		}
+		us, ok := clust.unitStates[j.Name]
+		if !ok {
+			continue
+		}
+
+		unhealthy := us.Healthy != nil && !*us.Healthy
+		rec, ok := r.failures[j.Name]
+		if !ok {
+			rec = &failureRecord{machineID: j.TargetMachineID, count: us.Failures, unhealthy: unhealthy}
+			r.failures[j.Name] = rec
+			if !rebuild || us.Failures == 0 || us.InactiveEnterTimestamp == 0 {
+				continue
+			}
+			at := time.Unix(0, int64(us.InactiveEnterTimestamp)*int64(time.Microsecond))
+			for i := 0; i < us.Failures; i++ {
+				rec.times = append(rec.times, at)
+			}
+		}
+
+		// the count starts over when the unit is loaded again
+		if us.Failures < rec.count {
+			rec.count = us.Failures
+		}
+		for ; rec.count < us.Failures; rec.count++ {
+			rec.times = append(rec.times, now)
+		}
+		if unhealthy && !rec.unhealthy {
+			rec.times = append(rec.times, now)
+		}
+		rec.unhealthy = unhealthy
+
+		recent := rec.times[:0]
+		for _, t := range rec.times {
+			if now.Sub(t) < window {
+				recent = append(recent, t)
+			}
+		}
+		rec.times = recent
+	}
+}
+
+// failedTooOften reports whether the scheduled Job is to be moved away from
+// its target machine because it failed there too often, along with the
+// reason. The machine is excluded for the Job as long as its failure window
+// once it failed MaxFailures times within this window.
+func (r *Reconciler) failedTooOften(j *job.Job) (string, bool) {
+	max, window, ok := j.MaxFailures()
+	if !ok {
+		return "", false
+	}
+
+	if rec, ok := r.failures[j.Name]; ok && rec.machineID == j.TargetMachineID && len(rec.times) >= max {
+		until := r.clock.Now().Add(window)
+		log.Infof("Excluding Machine(%s) for Job(%s) until %s", j.TargetMachineID, j.Name, until.Format(time.RFC3339))
+		if r.excluded[j.Name] == nil {
+			r.excluded[j.Name] = make(map[string]time.Time)
+		}
+		r.excluded[j.Name][j.TargetMachineID] = until
+		j.ExcludedMachines = r.excludedMachines(j.Name)
+		delete(r.failures, j.Name)
+	}
+
+	if _, ok := r.excluded[j.Name][j.TargetMachineID]; !ok {
+		return "", false
+	}
+	return fmt.Sprintf("unit failed %d times within %v on target Machine(%s)", max, window, j.TargetMachineID), true
+}
+
+// excludedMachines returns the sorted IDs of the machines the Job of the
+// given name is currently excluded from.
+func (r *Reconciler) excludedMachines(name string) []string {
+	machines := r.excluded[name]
+	if len(machines) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(machines))
+	for id := range machines {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// exclusions returns the current exclusions, sorted by Job name and
+// machine ID.
+func (r *Reconciler) exclusions() []registry.EngineExclusion {
+	names := make([]string, 0, len(r.excluded))
+	for name := range r.excluded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var exclusions []registry.EngineExclusion
+	for _, name := range names {
+		for _, id := range r.excludedMachines(name) {
+			exclusions = append(exclusions, registry.EngineExclusion{
+				UnitName:  name,
+				MachineID: id,
+				Until:     r.excluded[name][id],
+			})
+		}
+	}
+	return exclusions
+}
+
+// publishExclusions saves the current exclusions in the Registry if they
+// changed since they were last saved, so that explaining the scheduling of
+// a unit takes them into account.
+func (r *Reconciler) publishExclusions(cReg registry.ClusterRegistry) {
+	exclusions := r.exclusions()
+	if r.exclusionsPublished && reflect.DeepEqual(exclusions, r.published) {
+		return
+	}
+
+	if err := cReg.SetEngineExclusions(exclusions); err != nil {
+		log.Errorf("Failed saving engine exclusions in Registry: %v", err)
+		return
+	}
+	r.published, r.exclusionsPublished = exclusions, true
+}
+
+// limitsFailures reports whether any Job of the cluster is to be moved away
+// from machines it fails on, in which case the UnitStates of the cluster
+// are needed.
+func limitsFailures(clust *clusterState) bool {
+	for _, j := range clust.jobs {
+		if _, _, ok := j.MaxFailures(); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,335 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/unit"
+)
+
+func TestCalculateClusterTasksFailures(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	newClust := func(unitOpts, target string, failures int) *clusterState {
+		var sUnits []job.ScheduledUnit
+		if target != "" {
+			sUnits = append(sUnits, job.ScheduledUnit{
+				Name:            "foo.service",
+				State:           &jsLaunched,
+				TargetMachineID: target,
+			})
+		}
+		clust := newClusterState(
+			[]job.Unit{
+				job.Unit{
+					Name:        "foo.service",
+					Unit:        newUnitWithXFleetValues(t, unitOpts),
+					TargetState: job.JobStateLaunched,
+				},
+			},
+			sUnits,
+			[]machine.MachineState{
+				machine.MachineState{ID: "XXX"},
+				machine.MachineState{ID: "YYY"},
+			},
+		)
+		if target != "" {
+			clust.setUnitStates([]*unit.UnitState{
+				&unit.UnitState{
+					UnitName:    "foo.service",
+					MachineID:   target,
+					ActiveState: "failed",
+					Failures:    failures,
+				},
+			})
+		}
+		return clust
+	}
+	unscheduled := func(machID, reason string) *task {
+		return &task{
+			Type:      taskTypeUnscheduleUnit,
+			Reason:    reason,
+			JobName:   "foo.service",
+			MachineID: machID,
+		}
+	}
+	scheduled := func(machID string) *task {
+		return &task{
+			Type:      taskTypeAttemptScheduleUnit,
+			Reason:    "target state launched and unit not scheduled",
+			JobName:   "foo.service",
+			MachineID: machID,
+		}
+	}
+
+	type round struct {
+		// elapsed time before the round
+		elapsed time.Duration
+		// machine the unit is scheduled to, and its failures reported
+		// by this machine
+		target   string
+		failures int
+		tasks    []*task
+	}
+	tests := []struct {
+		unitOpts string
+		rounds   []round
+	}{
+		// units without limit are left alone
+		{
+			unitOpts: "",
+			rounds: []round{
+				{0, "XXX", 0, []*task{}},
+				{time.Minute, "XXX", 5, []*task{}},
+			},
+		},
+		// failing units are moved away, and kept away from the machines
+		// they failed on until the end of their failure window
+		{
+			unitOpts: "MaxFailures=2\nFailureWindow=10m",
+			rounds: []round{
+				{0, "XXX", 0, []*task{}},
+				{time.Minute, "XXX", 2, []*task{
+					unscheduled("XXX", "unit failed 2 times within 10m0s on target Machine(XXX)"),
+					scheduled("YYY"),
+				}},
+				{time.Minute, "YYY", 0, []*task{}},
+				{time.Minute, "YYY", 1, []*task{}},
+				{time.Minute, "YYY", 2, []*task{
+					unscheduled("YYY", "unit failed 2 times within 10m0s on target Machine(YYY)"),
+				}},
+				{time.Minute, "", 0, []*task{}},
+				{6 * time.Minute, "", 0, []*task{scheduled("XXX")}},
+			},
+		},
+		// failures are only counted within the failure window
+		{
+			unitOpts: "MaxFailures=2\nFailureWindow=5m",
+			rounds: []round{
+				{0, "XXX", 0, []*task{}},
+				{6 * time.Minute, "XXX", 1, []*task{}},
+				{6 * time.Minute, "XXX", 2, []*task{}},
+				{time.Minute, "XXX", 3, []*task{
+					unscheduled("XXX", "unit failed 2 times within 5m0s on target Machine(XXX)"),
+					scheduled("YYY"),
+				}},
+			},
+		},
+		// failures which occurred before the unit was first seen on its
+		// machine are not counted, and the count starts over when the
+		// unit is loaded again
+		{
+			unitOpts: "MaxFailures=2",
+			rounds: []round{
+				{0, "XXX", 4, []*task{}},
+				{time.Minute, "XXX", 5, []*task{}},
+				{time.Minute, "XXX", 0, []*task{}},
+				{time.Minute, "XXX", 1, []*task{
+					unscheduled("XXX", "unit failed 2 times within 10m0s on target Machine(XXX)"),
+					scheduled("YYY"),
+				}},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
+		fclock := clockwork.NewFakeClock()
+		r.clock = fclock
+
+		for n, rnd := range tt.rounds {
+			fclock.Advance(rnd.elapsed)
+			tasks := make([]*task, 0)
+			for tsk := range r.calculateClusterTasks(newClust(tt.unitOpts, rnd.target, rnd.failures), make(chan struct{})) {
+				tasks = append(tasks, tsk)
+			}
+			if !reflect.DeepEqual(rnd.tasks, tasks) {
+				t.Errorf("case %d, round %d: task mismatch\nexpected %v\n got %v", i, n, rnd.tasks, tasks)
+			}
+		}
+	}
+}
+
+func TestCalculateClusterTasksFailuresTakeover(t *testing.T) {
+	fclock := clockwork.NewFakeClock()
+	newClust := func(inactiveSince time.Duration) *clusterState {
+		jsLaunched := job.JobStateLaunched
+		clust := newClusterState(
+			[]job.Unit{
+				job.Unit{
+					Name:        "foo.service",
+					Unit:        newUnitWithXFleetValues(t, "MaxFailures=2\nFailureWindow=10m"),
+					TargetState: job.JobStateLaunched,
+				},
+			},
+			[]job.ScheduledUnit{
+				job.ScheduledUnit{
+					Name:            "foo.service",
+					State:           &jsLaunched,
+					TargetMachineID: "XXX",
+				},
+			},
+			[]machine.MachineState{
+				machine.MachineState{ID: "XXX"},
+				machine.MachineState{ID: "YYY"},
+			},
+		)
+		clust.setUnitStates([]*unit.UnitState{
+			&unit.UnitState{
+				UnitName:               "foo.service",
+				MachineID:              "XXX",
+				ActiveState:            "failed",
+				Failures:               2,
+				InactiveEnterTimestamp: uint64(fclock.Now().Add(-inactiveSince).UnixNano() / int64(time.Microsecond)),
+			},
+		})
+		return clust
+	}
+
+	tests := []struct {
+		// whether the engine just took the lead
+		takeover bool
+		// time since the unit last entered the inactive state
+		inactiveSince time.Duration
+		unscheduled   bool
+	}{
+		// failures published before the engine took the lead are
+		// counted if the unit became inactive within the failure window
+		{true, time.Minute, true},
+		{true, 20 * time.Minute, false},
+		// otherwise only the failures seen by the engine count
+		{false, time.Minute, false},
+	}
+
+	for i, tt := range tests {
+		r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
+		r.clock = fclock
+		r.excluded["foo.service"] = map[string]time.Time{"YYY": fclock.Now().Add(time.Hour)}
+		if tt.takeover {
+			r.resetFailures()
+		}
+
+		count := countTasks(r, newClust(tt.inactiveSince))
+		if unscheduled := count[taskTypeUnscheduleUnit]["XXX"] == 1; unscheduled != tt.unscheduled {
+			t.Errorf("case %d: expected unscheduled %t, got tasks %v", i, tt.unscheduled, count)
+		}
+		// exclusions of a previous leadership are forgotten
+		if _, excluded := r.excluded["foo.service"]["YYY"]; excluded == tt.takeover {
+			t.Errorf("case %d: unexpected exclusion of Machine(YYY): %v", i, r.excluded)
+		}
+
+		// the failures are only rebuilt once
+		r.failures = make(map[string]*failureRecord)
+		r.excluded = make(map[string]map[string]time.Time)
+		if count := countTasks(r, newClust(tt.inactiveSince)); count[taskTypeUnscheduleUnit]["XXX"] != 0 {
+			t.Errorf("case %d: expected failures to be rebuilt once, got tasks %v", i, count)
+		}
+	}
+}
+
+func TestCalculateClusterTasksUnhealthy(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	newClust := func(healthy *bool) *clusterState {
+		clust := newClusterState(
+			[]job.Unit{
+				job.Unit{
+					Name:        "foo.service",
+					Unit:        newUnitWithXFleetValues(t, "MaxFailures=2\nFailureWindow=10m"),
+					TargetState: job.JobStateLaunched,
+				},
+			},
+			[]job.ScheduledUnit{
+				job.ScheduledUnit{
+					Name:            "foo.service",
+					State:           &jsLaunched,
+					TargetMachineID: "XXX",
+				},
+			},
+			[]machine.MachineState{
+				machine.MachineState{ID: "XXX"},
+				machine.MachineState{ID: "YYY"},
+			},
+		)
+		clust.setUnitStates([]*unit.UnitState{
+			&unit.UnitState{
+				UnitName:    "foo.service",
+				MachineID:   "XXX",
+				ActiveState: "active",
+				Healthy:     healthy,
+			},
+		})
+		return clust
+	}
+	yes, no := true, false
+
+	r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
+	r.clock = clockwork.NewFakeClock()
+	// the unit turning unhealthy counts as a failure, staying unhealthy
+	// does not
+	for n, healthy := range []*bool{nil, &no, &no, &yes, &no} {
+		count := countTasks(r, newClust(healthy))
+		expect := n == 4
+		if unscheduled := count[taskTypeUnscheduleUnit]["XXX"] == 1; unscheduled != expect {
+			t.Errorf("round %d: expected unscheduled %t, got tasks %v", n, expect, count)
+		}
+	}
+}
+
+func TestPublishExclusions(t *testing.T) {
+	fclock := clockwork.NewFakeClock()
+	r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
+	r.clock = fclock
+	cReg := registry.NewFakeClusterRegistry(nil, 0)
+	cReg.SetEngineExclusions([]registry.EngineExclusion{
+		{UnitName: "stale.service", MachineID: "XXX", Until: fclock.Now().Add(time.Hour)},
+	})
+
+	// the exclusions of a previous leader are replaced by the ones of the
+	// new leader, even if it has none
+	r.resetFailures()
+	r.publishExclusions(cReg)
+	if got, _ := cReg.EngineExclusions(); got != nil {
+		t.Fatalf("Expected no exclusions, got %v", got)
+	}
+
+	r.excluded["foo.service"] = map[string]time.Time{
+		"YYY": fclock.Now().Add(time.Hour),
+		"XXX": fclock.Now().Add(time.Minute),
+	}
+	r.excluded["bar.service"] = map[string]time.Time{"XXX": fclock.Now().Add(time.Hour)}
+	r.publishExclusions(cReg)
+	want := []registry.EngineExclusion{
+		{UnitName: "bar.service", MachineID: "XXX", Until: fclock.Now().Add(time.Hour)},
+		{UnitName: "foo.service", MachineID: "XXX", Until: fclock.Now().Add(time.Minute)},
+		{UnitName: "foo.service", MachineID: "YYY", Until: fclock.Now().Add(time.Hour)},
+	}
+	if got, _ := cReg.EngineExclusions(); !reflect.DeepEqual(want, got) {
+		t.Fatalf("Unexpected exclusions\nexpected %v\ngot      %v", want, got)
+	}
+
+	// unchanged exclusions are not saved again
+	cReg.SetEngineExclusions(nil)
+	r.publishExclusions(cReg)
+	if got, _ := cReg.EngineExclusions(); got != nil {
+		t.Errorf("Expected unchanged exclusions not to be saved, got %v", got)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cea-hpc/fleet/job"
+)
+
+// gangPlacement is a joint placement of the unscheduled members of a gang
+type gangPlacement struct {
+	jobs      []*job.Job
+	decisions []*decision
+}
+
+// decideGang computes a placement for every unscheduled member of a gang
+// at once, placing each member as if the members placed before it were
+// already scheduled. If any member cannot be placed, or fewer members than
+// the GangSize of the gang are to run, no placement is returned but an
+// error telling why. The cluster state is left unchanged.
+func decideGang(sched Scheduler, clust *clusterState, gang string) (*gangPlacement, error) {
+	var members []*job.Job
+	size := 0
+	for _, j := range clust.jobs {
+		if j.Gang() != gang || j.TargetState == job.JobStateInactive {
+			continue
+		}
+		members = append(members, j)
+		if s := j.GangSize(); s > size {
+			size = s
+		}
+	}
+	if len(members) < size {
+		return nil, fmt.Errorf("waiting for %d of %d members to be started", size-len(members), size)
+	}
+
+	// members of highest priority are placed first, like any other units
+	sort.Sort(sort.Reverse(byPriority(members)))
+
+	var gp gangPlacement
+	defer func() {
+		for _, j := range gp.jobs {
+			clust.unschedule(j.Name)
+		}
+	}()
+
+	for _, j := range members {
+		if j.Scheduled() {
+			continue
+		}
+		dec, err := sched.Decide(clust, j)
+		if err != nil {
+			return nil, fmt.Errorf("unable to place Job(%s): %v", j.Name, err)
+		}
+		clust.schedule(j.Name, dec.machineID)
+		gp.jobs = append(gp.jobs, j)
+		gp.decisions = append(gp.decisions, dec)
+	}
+
+	return &gp, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+)
+
+func TestCalculateClusterTasksGang(t *testing.T) {
+	member := func(name, opts string) job.Unit {
+		return job.Unit{
+			Name:        name,
+			Unit:        newUnitWithXFleetValues(t, "Gang=solver\nConflicts=solver-*\n"+opts),
+			TargetState: job.JobStateLaunched,
+		}
+	}
+	machines := func(ids ...string) []machine.MachineState {
+		var ms []machine.MachineState
+		for _, id := range ids {
+			ms = append(ms, machine.MachineState{ID: id})
+		}
+		return ms
+	}
+	scheduled := func(names ...string) []string { return names }
+
+	tests := []struct {
+		units    []job.Unit
+		sUnits   []job.ScheduledUnit
+		machines []machine.MachineState
+		// units expected to be scheduled, in order
+		scheduled []string
+	}{
+		// every member fits
+		{
+			units:     []job.Unit{member("solver-1.service", ""), member("solver-2.service", ""), member("solver-3.service", "")},
+			machines:  machines("AAA", "BBB", "CCC"),
+			scheduled: scheduled("solver-3.service", "solver-2.service", "solver-1.service"),
+		},
+		// one member does not fit, so none is scheduled
+		{
+			units:    []job.Unit{member("solver-1.service", ""), member("solver-2.service", ""), member("solver-3.service", "")},
+			machines: machines("AAA", "BBB"),
+		},
+		// members of higher priority are placed first
+		{
+			units:     []job.Unit{member("solver-1.service", "Priority=10"), member("solver-2.service", "")},
+			machines:  machines("AAA", "BBB"),
+			scheduled: scheduled("solver-1.service", "solver-2.service"),
+		},
+		// the gang waits for all of its members
+		{
+			units:    []job.Unit{member("solver-1.service", "GangSize=3"), member("solver-2.service", "GangSize=3")},
+			machines: machines("AAA", "BBB", "CCC"),
+		},
+		// members already scheduled are left in place
+		{
+			units: []job.Unit{member("solver-1.service", ""), member("solver-2.service", "")},
+			sUnits: []job.ScheduledUnit{
+				job.ScheduledUnit{Name: "solver-1.service", TargetMachineID: "AAA"},
+			},
+			machines:  machines("AAA", "BBB"),
+			scheduled: scheduled("solver-2.service"),
+		},
+		// units out of the gang are scheduled regardless
+		{
+			units: []job.Unit{
+				member("solver-1.service", ""),
+				member("solver-2.service", ""),
+				job.Unit{Name: "web.service", TargetState: job.JobStateLaunched},
+			},
+			machines:  machines("AAA"),
+			scheduled: scheduled("web.service"),
+		},
+	}
+
+	for i, tt := range tests {
+		clust := newClusterState(tt.units, tt.sUnits, tt.machines)
+		r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
+
+		var got []string
+		for tsk := range r.calculateClusterTasks(clust, make(chan struct{})) {
+			if tsk.Type != taskTypeAttemptScheduleUnit {
+				t.Errorf("case %d: unexpected task %v", i, tsk)
+				continue
+			}
+			got = append(got, tsk.JobName)
+		}
+		if !reflect.DeepEqual(tt.scheduled, got) {
+			t.Errorf("case %d: expected scheduled units %v, got %v", i, tt.scheduled, got)
+		}
+	}
+}
+
+func TestDecideGangLeavesClusterUnchanged(t *testing.T) {
+	units := []job.Unit{
+		job.Unit{Name: "solver-1.service", Unit: newUnitWithXFleetValues(t, "Gang=solver"), TargetState: job.JobStateLaunched},
+		job.Unit{Name: "solver-2.service", Unit: newUnitWithXFleetValues(t, "Gang=solver\nMachineID=ZZZ"), TargetState: job.JobStateLaunched},
+	}
+	clust := newClusterState(units, []job.ScheduledUnit{}, []machine.MachineState{machine.MachineState{ID: "AAA"}})
+
+	if gp, err := decideGang(&leastLoadedScheduler{}, clust, "solver"); err == nil {
+		t.Fatalf("expected error, got placement %v", gp)
+	}
+	for name, j := range clust.jobs {
+		if j.Scheduled() {
+			t.Errorf("Job(%s) unexpectedly scheduled to %s", name, j.TargetMachineID)
+		}
+	}
+}
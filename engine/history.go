@@ -0,0 +1,125 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"time"
+
+	"github.com/cea-hpc/fleet/log"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+const (
+	// number of scheduling decisions kept in the history of the engine
+	historySize = 500
+
+	eventTypeSchedule   = "schedule"
+	eventTypeUnschedule = "unschedule"
+	eventTypeReschedule = "reschedule"
+	eventTypeCreate     = "create"
+	eventTypeDestroy    = "destroy"
+)
+
+// decisionHistory is a ring buffer of the most recent scheduling decisions
+// of the engine leaders. It is persisted in the Registry, so that it
+// outlives leadership changes, and loaded back by every new leader.
+type decisionHistory struct {
+	events []registry.EngineEvent
+	// loaded is set once the events recorded by previous leaders have
+	// been read from the Registry
+	loaded bool
+}
+
+// add appends the events to the history, dropping the oldest ones beyond
+// historySize.
+func (h *decisionHistory) add(events ...registry.EngineEvent) {
+	h.events = append(h.events, events...)
+	if over := len(h.events) - historySize; over > 0 {
+		h.events = append([]registry.EngineEvent(nil), h.events[over:]...)
+	}
+}
+
+// record adds the decisions of the given tasks to the history, and saves
+// it in the Registry.
+func (h *decisionHistory) record(cReg registry.ClusterRegistry, leaderID string, tasks []*task) {
+	events := decisionEvents(tasks, leaderID, time.Now())
+	if len(events) == 0 {
+		return
+	}
+
+	if !h.loaded {
+		previous, err := cReg.EngineEvents()
+		if err != nil {
+			log.Errorf("Failed fetching engine events from Registry: %v", err)
+			h.add(events...)
+			return
+		}
+		h.events = append(previous, h.events...)
+		h.loaded = true
+	}
+	h.add(events...)
+
+	if err := cReg.SetEngineEvents(h.events); err != nil {
+		log.Errorf("Failed saving engine events in Registry: %v", err)
+	}
+}
+
+// reset forgets the history, so that it is loaded again from the Registry
+// should the engine become leader again.
+func (h *decisionHistory) reset() {
+	h.events = nil
+	h.loaded = false
+}
+
+// decisionEvents turns the tasks resolved in a reconciliation into events.
+// A Unit unscheduled from a machine and scheduled to another one in the
+// same reconciliation is recorded as rescheduled.
+func decisionEvents(tasks []*task, leaderID string, now time.Time) []registry.EngineEvent {
+	var events []registry.EngineEvent
+	unscheduled := make(map[string]int)
+	for _, t := range tasks {
+		ev := registry.EngineEvent{
+			Time:      now,
+			LeaderID:  leaderID,
+			UnitName:  t.JobName,
+			MachineID: t.MachineID,
+			Reason:    t.Reason,
+		}
+
+		switch t.Type {
+		case taskTypeUnscheduleUnit:
+			ev.Type = eventTypeUnschedule
+			unscheduled[t.JobName] = len(events)
+		case taskTypeAttemptScheduleUnit:
+			if i, ok := unscheduled[t.JobName]; ok {
+				delete(unscheduled, t.JobName)
+				events[i].Type = eventTypeReschedule
+				events[i].PreviousMachineID = events[i].MachineID
+				events[i].MachineID = t.MachineID
+				continue
+			}
+			ev.Type = eventTypeSchedule
+		case taskTypeCreateUnit:
+			ev.Type = eventTypeCreate
+		case taskTypeDestroyUnit:
+			ev.Type = eventTypeDestroy
+		default:
+			continue
+		}
+		events = append(events, ev)
+	}
+
+	return events
+}
@@ -0,0 +1,112 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+func TestDecisionEvents(t *testing.T) {
+	now := time.Now()
+	tasks := []*task{
+		{Type: taskTypeCreateUnit, JobName: "web@3.service", Reason: "Template(web@.service) scaled to 3 replicas"},
+		{Type: taskTypeUnscheduleUnit, JobName: "foo.service", MachineID: "XXX", Reason: "target Machine(XXX) went away"},
+		{Type: taskTypeUnscheduleUnit, JobName: "bar.service", MachineID: "XXX", Reason: "target state inactive"},
+		{Type: taskTypeAttemptScheduleUnit, JobName: "web@3.service", MachineID: "YYY", Reason: "target state launched and unit not scheduled"},
+		{Type: taskTypeAttemptScheduleUnit, JobName: "foo.service", MachineID: "YYY", Reason: "target state launched and unit not scheduled"},
+	}
+
+	expect := []registry.EngineEvent{
+		{Time: now, LeaderID: "ZZZ", Type: eventTypeCreate, UnitName: "web@3.service", Reason: "Template(web@.service) scaled to 3 replicas"},
+		{Time: now, LeaderID: "ZZZ", Type: eventTypeReschedule, UnitName: "foo.service", MachineID: "YYY", PreviousMachineID: "XXX", Reason: "target Machine(XXX) went away"},
+		{Time: now, LeaderID: "ZZZ", Type: eventTypeUnschedule, UnitName: "bar.service", MachineID: "XXX", Reason: "target state inactive"},
+		{Time: now, LeaderID: "ZZZ", Type: eventTypeSchedule, UnitName: "web@3.service", MachineID: "YYY", Reason: "target state launched and unit not scheduled"},
+	}
+
+	events := decisionEvents(tasks, "ZZZ", now)
+	if !reflect.DeepEqual(expect, events) {
+		t.Errorf("Unexpected events:\nexpected %#v\ngot %#v", expect, events)
+	}
+}
+
+func TestDecisionHistoryRecord(t *testing.T) {
+	cReg := registry.NewFakeClusterRegistry(nil, engineVersion)
+	cReg.SetEngineEvents([]registry.EngineEvent{
+		{LeaderID: "XXX", Type: eventTypeSchedule, UnitName: "previous.service", MachineID: "XXX"},
+	})
+
+	// a new leader carries on with the history of the previous ones
+	var h decisionHistory
+	h.record(cReg, "YYY", []*task{{Type: taskTypeAttemptScheduleUnit, JobName: "foo.service", MachineID: "YYY"}})
+	events, _ := cReg.EngineEvents()
+	if len(events) != 2 || events[0].UnitName != "previous.service" || events[1].UnitName != "foo.service" || events[1].LeaderID != "YYY" {
+		t.Fatalf("Unexpected events: %#v", events)
+	}
+
+	// no task, no event
+	h.record(cReg, "YYY", nil)
+	if events, _ := cReg.EngineEvents(); len(events) != 2 {
+		t.Errorf("Expected 2 events, got %d", len(events))
+	}
+
+	// the oldest events are dropped
+	var tasks []*task
+	for i := 0; i < historySize; i++ {
+		tasks = append(tasks, &task{Type: taskTypeAttemptScheduleUnit, JobName: fmt.Sprintf("unit-%d.service", i), MachineID: "YYY"})
+	}
+	h.record(cReg, "YYY", tasks)
+	events, _ = cReg.EngineEvents()
+	if len(events) != historySize || events[0].UnitName != "unit-0.service" || events[historySize-1].UnitName != fmt.Sprintf("unit-%d.service", historySize-1) {
+		t.Errorf("Expected the %d most recent events, got %d from %s", historySize, len(events), events[0].UnitName)
+	}
+}
+
+// failingScheduleRegistry fails to persist any scheduling decision.
+type failingScheduleRegistry struct {
+	*registry.FakeRegistry
+}
+
+func (r *failingScheduleRegistry) ScheduleUnit(name, machID string) error {
+	return errors.New("registry unavailable")
+}
+
+func TestReconcileHistoryFailedTasks(t *testing.T) {
+	reg := &failingScheduleRegistry{registry.NewFakeRegistry()}
+	reg.SetMachines([]machine.MachineState{{ID: "XXX"}})
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateLaunched},
+	})
+	cReg := registry.NewFakeClusterRegistry(nil, engineVersion)
+
+	e := &Engine{
+		rec:       NewReconciler(&leastLoadedScheduler{}, nil, 0),
+		registry:  reg,
+		cRegistry: cReg,
+	}
+	e.rec.Reconcile(e, make(chan struct{}))
+
+	// the unit was not scheduled, so no decision is recorded
+	if events, _ := cReg.EngineEvents(); len(events) != 0 {
+		t.Errorf("Expected no events for failed tasks, got %v", events)
+	}
+}
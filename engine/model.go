@@ -0,0 +1,155 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/cea-hpc/fleet/log"
+	"github.com/cea-hpc/fleet/metrics"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+// clusterModel keeps a long-lived clusterState up to date from the names
+// of the Units reported as changed in the Registry. Between two full
+// resyncs, only the changed Units and the machines are read from the
+// Registry, and only the Jobs they affect are reconciled.
+type clusterModel struct {
+	resyncInterval time.Duration
+	clock          clockwork.Clock
+
+	// mu guards the changes recorded between two rounds
+	mu      sync.Mutex
+	changed map[string]bool
+	// missed is set when changes may have been missed, so that the next
+	// round is a full resync
+	missed bool
+
+	clust    *clusterState
+	lastSync time.Time
+
+	// standby is set while the clusterState is kept up to date by an
+	// engine which is not the leader, and whose rounds reconcile nothing
+	standby bool
+}
+
+func newClusterModel(resyncInterval time.Duration) *clusterModel {
+	return &clusterModel{
+		resyncInterval: resyncInterval,
+		clock:          clockwork.NewRealClock(),
+		changed:        make(map[string]bool),
+	}
+}
+
+// watch records the changes emitted by the UnitChangeStream until stop is
+// closed.
+func (m *clusterModel) watch(changes registry.UnitChangeStream, stop <-chan struct{}) {
+	for name := range changes.Changes(stop) {
+		m.unitChanged(name)
+	}
+}
+
+// unitChanged records that the Unit of the given name changed in the
+// Registry. An empty name means that any Unit may have changed.
+func (m *clusterModel) unitChanged(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == "" {
+		m.missed = true
+		return
+	}
+	m.changed[name] = true
+}
+
+// reset drops the clusterState, so that the next round is a full resync.
+func (m *clusterModel) reset() {
+	m.clust = nil
+}
+
+// refresh keeps the clusterState up to date on an engine which is not the
+// leader, so that it is ready to be reconciled once the engine becomes
+// leader.
+func (m *clusterModel) refresh(reg registry.Registry) {
+	if _, err := m.clusterState(reg); err != nil {
+		log.Errorf("Failed refreshing standby cluster state: %v", err)
+	}
+	m.standby = true
+}
+
+// clusterState returns the clusterState to reconcile, either loaded in
+// full from the Registry or updated with the changes recorded since the
+// previous round.
+func (m *clusterModel) clusterState(reg registry.Registry) (*clusterState, error) {
+	m.mu.Lock()
+	changed, missed := m.changed, m.missed
+	m.changed, m.missed = make(map[string]bool), false
+	m.mu.Unlock()
+
+	standby := m.standby
+	m.standby = false
+
+	if m.clust == nil || missed || m.clock.Since(m.lastSync) >= m.resyncInterval {
+		clust, err := loadClusterState(reg)
+		if err != nil {
+			m.reset()
+			return nil, err
+		}
+		log.Debugf("Loaded full cluster state from Registry")
+		metrics.ReportEngineClusterSync(metrics.FullSync)
+		m.clust, m.lastSync = clust, m.clock.Now()
+		return clust, nil
+	}
+
+	machines, err := reg.Machines()
+	if err != nil {
+		log.Errorf("Failed fetching Machines from Registry: %v", err)
+		m.reset()
+		return nil, err
+	}
+
+	m.clust.beginRound()
+	m.clust.setMachines(machines)
+	for name := range changed {
+		u, err := reg.Unit(name)
+		if err != nil {
+			log.Errorf("Failed fetching Unit(%s) from Registry: %v", name, err)
+			m.reset()
+			return nil, err
+		}
+
+		su, err := reg.ScheduledUnit(name)
+		if err != nil {
+			log.Errorf("Failed fetching schedule of Unit(%s) from Registry: %v", name, err)
+			m.reset()
+			return nil, err
+		}
+
+		m.clust.update(name, u, su)
+	}
+	log.Debugf("Updated cluster state with %d changed Units", len(changed))
+	metrics.ReportEngineClusterSync(metrics.IncrementalSync)
+
+	// the changes read during standby rounds were not reconciled, so
+	// every Job is reconciled in the first round as leader
+	if standby {
+		m.clust.reconcileAll()
+	}
+
+	return m.clust, nil
+}
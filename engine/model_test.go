@@ -0,0 +1,393 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/unit"
+)
+
+func TestClusterModel(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	reg.SetMachines([]machine.MachineState{{ID: "XXX"}, {ID: "YYY"}})
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+		{Name: "bar.service", TargetState: job.JobStateLaunched, TargetMachineID: "YYY"},
+	})
+
+	m := newClusterModel(time.Hour)
+	clock := clockwork.NewFakeClock()
+	m.clock = clock
+
+	affected := func(clust *clusterState) map[string]bool {
+		names := make(map[string]bool)
+		for _, j := range clust.jobs {
+			if j.Scheduled() && clust.affected(j) {
+				names[j.Name] = true
+			}
+		}
+		return names
+	}
+
+	// the first round reads the whole cluster
+	clust, err := m.clusterState(reg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(clust.jobs) != 2 || len(affected(clust)) != 2 {
+		t.Fatalf("Expected 2 jobs to reconcile, got %v", affected(clust))
+	}
+
+	// nothing changed
+	clust, err = m.clusterState(reg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := affected(clust); len(got) != 0 {
+		t.Errorf("Expected no job to reconcile, got %v", got)
+	}
+
+	// a new unit is read back, and affects the units of its machine
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+		{Name: "bar.service", TargetState: job.JobStateLaunched, TargetMachineID: "YYY"},
+		{Name: "baz.service", TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+	})
+	m.unitChanged("baz.service")
+	clust, err = m.clusterState(reg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := affected(clust); len(got) != 2 || !got["foo.service"] || !got["baz.service"] {
+		t.Errorf("Expected foo.service and baz.service to reconcile, got %v", got)
+	}
+
+	// a lost machine affects the units scheduled to it
+	reg.SetMachines([]machine.MachineState{{ID: "XXX"}})
+	for i := 0; i < 2; i++ {
+		clust, err = m.clusterState(reg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := affected(clust); len(got) != 1 || !got["bar.service"] {
+			t.Errorf("round %d: expected bar.service to reconcile, got %v", i, got)
+		}
+	}
+
+	// a destroyed unit is dropped
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+		{Name: "bar.service", TargetState: job.JobStateLaunched, TargetMachineID: "YYY"},
+	})
+	m.unitChanged("baz.service")
+	clust, err = m.clusterState(reg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := clust.jobs["baz.service"]; ok {
+		t.Errorf("Expected baz.service to be dropped")
+	}
+
+	// missed changes, and the resync interval, lead to a full read
+	m.unitChanged("")
+	clust, err = m.clusterState(reg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if clust.dirty != nil {
+		t.Errorf("Expected a full read after missed changes")
+	}
+	clust, _ = m.clusterState(reg)
+	if clust.dirty == nil {
+		t.Errorf("Expected an incremental read")
+	}
+	clock.Advance(time.Hour)
+	clust, _ = m.clusterState(reg)
+	if clust.dirty != nil {
+		t.Errorf("Expected a full read once the resync interval elapsed")
+	}
+}
+
+func TestClusterModelStandby(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	reg.SetMachines([]machine.MachineState{{ID: "XXX"}})
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+	})
+
+	m := newClusterModel(time.Hour)
+	clock := clockwork.NewFakeClock()
+	m.clock = clock
+
+	// standby rounds keep up with the changes without reconciling them
+	m.refresh(reg)
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateInactive, TargetMachineID: "XXX"},
+	})
+	m.unitChanged("foo.service")
+	clock.Advance(time.Minute)
+	m.refresh(reg)
+	m.refresh(reg)
+	lastSync := m.lastSync
+
+	// the first round as leader reconciles every Job, without reading the
+	// whole cluster
+	clust, err := m.clusterState(reg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if m.lastSync != lastSync {
+		t.Errorf("Expected no full read of the cluster state")
+	}
+	j := clust.jobs["foo.service"]
+	if j == nil || j.TargetState != job.JobStateInactive || !clust.affected(j) {
+		t.Errorf("Expected foo.service to be up to date and reconciled, got %+v", j)
+	}
+
+	// later rounds are incremental again
+	clust, _ = m.clusterState(reg)
+	if clust.affected(clust.jobs["foo.service"]) {
+		t.Errorf("Expected foo.service not to be reconciled again")
+	}
+}
+
+func TestEngineReconcileIncremental(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	reg.SetMachines([]machine.MachineState{{ID: "XXX"}})
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateLaunched},
+	})
+
+	e := &Engine{
+		rec:       NewReconciler(&leastLoadedScheduler{}, nil, 0),
+		registry:  reg,
+		cRegistry: registry.NewFakeClusterRegistry(nil, engineVersion),
+		model:     newClusterModel(time.Hour),
+	}
+
+	stop := make(chan struct{})
+	e.rec.Reconcile(e, stop)
+	if su, _ := reg.ScheduledUnit("foo.service"); su.TargetMachineID != "XXX" {
+		t.Fatalf("Expected foo.service to be scheduled to XXX, got %q", su.TargetMachineID)
+	}
+
+	// a unit created after the full read is scheduled once reported
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+		{Name: "bar.service", TargetState: job.JobStateLaunched},
+	})
+	e.rec.Reconcile(e, stop)
+	if su, _ := reg.ScheduledUnit("bar.service"); su.TargetMachineID != "" {
+		t.Fatalf("Expected bar.service not to be seen before being reported, got %q", su.TargetMachineID)
+	}
+	e.model.unitChanged("bar.service")
+	e.rec.Reconcile(e, stop)
+	if su, _ := reg.ScheduledUnit("bar.service"); su.TargetMachineID != "XXX" {
+		t.Fatalf("Expected bar.service to be scheduled to XXX, got %q", su.TargetMachineID)
+	}
+
+	// units of a lost machine are rescheduled
+	reg.SetMachines([]machine.MachineState{{ID: "YYY"}})
+	e.rec.Reconcile(e, stop)
+	e.rec.Reconcile(e, stop)
+	for _, name := range []string{"foo.service", "bar.service"} {
+		if su, _ := reg.ScheduledUnit(name); su.TargetMachineID != "YYY" {
+			t.Errorf("Expected %s to be rescheduled to YYY, got %q", name, su.TargetMachineID)
+		}
+	}
+}
+
+func TestEngineReconcileIncrementalThrottle(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	reg.SetMachines([]machine.MachineState{{ID: "XXX"}, {ID: "YYY"}})
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+		{Name: "bar.service", TargetState: job.JobStateLaunched, TargetMachineID: "YYY"},
+	})
+
+	e := &Engine{
+		rec:       NewReconciler(&leastLoadedScheduler{}, nil, 0),
+		registry:  reg,
+		cRegistry: registry.NewFakeClusterRegistry(nil, engineVersion),
+		model:     newClusterModel(time.Hour),
+	}
+	e.rec.throttle = &Throttle{MaxUnschedules: 1}
+
+	stop := make(chan struct{})
+	e.rec.Reconcile(e, stop)
+
+	// both units are stopped, but only one of them may be unscheduled per
+	// round
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateInactive, TargetMachineID: "XXX"},
+		{Name: "bar.service", TargetState: job.JobStateInactive, TargetMachineID: "YYY"},
+	})
+	e.model.unitChanged("foo.service")
+	e.model.unitChanged("bar.service")
+
+	scheduled := func() (n int) {
+		for _, name := range []string{"foo.service", "bar.service"} {
+			if su, _ := reg.ScheduledUnit(name); su != nil && su.TargetMachineID != "" {
+				n++
+			}
+		}
+		return
+	}
+	e.rec.Reconcile(e, stop)
+	if n := scheduled(); n != 1 {
+		t.Fatalf("Expected 1 unit left scheduled by the throttle, got %d", n)
+	}
+
+	// the deferred unit is unscheduled in the next round, although
+	// neither it nor its machine changed in the meantime
+	e.rec.Reconcile(e, stop)
+	if n := scheduled(); n != 0 {
+		t.Errorf("Expected the deferred unit to be unscheduled, %d still scheduled", n)
+	}
+}
+
+// unitStatesCounter counts the reads of every UnitState of the Registry.
+type unitStatesCounter struct {
+	*registry.FakeRegistry
+	reads int
+}
+
+func (c *unitStatesCounter) UnitStates() ([]*unit.UnitState, error) {
+	c.reads++
+	return c.FakeRegistry.UnitStates()
+}
+
+func TestEngineReconcileIncrementalFailures(t *testing.T) {
+	reg := &unitStatesCounter{FakeRegistry: registry.NewFakeRegistry()}
+	reg.SetMachines([]machine.MachineState{{ID: "XXX"}, {ID: "YYY"}})
+	reg.SetJobs([]job.Job{
+		{
+			Name:            "foo.service",
+			Unit:            newUnitWithXFleetValues(t, "MaxFailures=1"),
+			TargetState:     job.JobStateLaunched,
+			TargetMachineID: "XXX",
+		},
+	})
+	setFailures := func(failures int) {
+		reg.SetUnitStates([]unit.UnitState{
+			{UnitName: "foo.service", MachineID: "XXX", ActiveState: "failed", Failures: failures},
+		})
+	}
+	setFailures(0)
+
+	e := &Engine{
+		rec:       NewReconciler(&leastLoadedScheduler{}, nil, 0),
+		registry:  reg,
+		cRegistry: registry.NewFakeClusterRegistry(nil, engineVersion),
+		model:     newClusterModel(time.Hour),
+	}
+	clock := clockwork.NewFakeClock()
+	e.model.clock = clock
+
+	stop := make(chan struct{})
+	e.rec.Reconcile(e, stop)
+	if reg.reads != 1 {
+		t.Fatalf("Expected the UnitStates to be read along with the full cluster state, got %d reads", reg.reads)
+	}
+
+	// incremental rounds do not read the UnitStates, so the failure is
+	// only noticed at the next full read
+	setFailures(1)
+	e.rec.Reconcile(e, stop)
+	e.rec.Reconcile(e, stop)
+	if reg.reads != 1 {
+		t.Fatalf("Expected no UnitStates read by incremental rounds, got %d reads", reg.reads)
+	}
+	if su, _ := reg.ScheduledUnit("foo.service"); su.TargetMachineID != "XXX" {
+		t.Fatalf("Expected foo.service to stay on XXX, got %q", su.TargetMachineID)
+	}
+
+	clock.Advance(time.Hour)
+	e.rec.Reconcile(e, stop)
+	if reg.reads != 2 {
+		t.Fatalf("Expected the UnitStates to be read again at the resync, got %d reads", reg.reads)
+	}
+	if su, _ := reg.ScheduledUnit("foo.service"); su.TargetMachineID == "XXX" {
+		t.Errorf("Expected foo.service to be moved away from XXX")
+	}
+}
+
+// benchmarkCluster returns a registry of the given number of machines, each
+// running unitsPerMachine units.
+func benchmarkCluster(machines, unitsPerMachine int) *registry.FakeRegistry {
+	reg := registry.NewFakeRegistry()
+
+	var ms []machine.MachineState
+	var jobs []job.Job
+	for i := 0; i < machines; i++ {
+		id := fmt.Sprintf("machine-%d", i)
+		ms = append(ms, machine.MachineState{ID: id})
+		for j := 0; j < unitsPerMachine; j++ {
+			jobs = append(jobs, job.Job{
+				Name:            fmt.Sprintf("unit-%d-%d.service", i, j),
+				TargetState:     job.JobStateLaunched,
+				TargetMachineID: id,
+			})
+		}
+	}
+	reg.SetMachines(ms)
+	reg.SetJobs(jobs)
+
+	return reg
+}
+
+// benchmarkEngineReconcile measures a reconciliation rescheduling one unit
+// unscheduled behind the engine's back, out of a cluster of 200 machines
+// running 10 units each.
+func benchmarkEngineReconcile(b *testing.B, model *clusterModel) {
+	reg := benchmarkCluster(200, 10)
+	e := &Engine{
+		rec:       NewReconciler(&leastLoadedScheduler{}, nil, 0),
+		registry:  reg,
+		cRegistry: registry.NewFakeClusterRegistry(nil, engineVersion),
+		model:     model,
+	}
+
+	stop := make(chan struct{})
+	e.rec.Reconcile(e, stop)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		name := fmt.Sprintf("unit-%d-0.service", i%200)
+		reg.UnscheduleUnit(name, fmt.Sprintf("machine-%d", i%200))
+		if e.model != nil {
+			e.model.unitChanged(name)
+		}
+
+		e.rec.Reconcile(e, stop)
+	}
+}
+
+func BenchmarkEngineReconcile(b *testing.B) {
+	b.Run("full", func(b *testing.B) {
+		benchmarkEngineReconcile(b, nil)
+	})
+	b.Run("incremental", func(b *testing.B) {
+		benchmarkEngineReconcile(b, newClusterModel(time.Hour))
+	})
+}
@@ -0,0 +1,235 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cea-hpc/fleet/agent"
+	"github.com/cea-hpc/fleet/job"
+)
+
+// preemption describes the units to unschedule from a machine so that a
+// Job of higher priority can be scheduled to it.
+type preemption struct {
+	machineID string
+	victims   []*job.Job
+}
+
+// decidePreemption looks for the machine on which the given Job could be
+// scheduled by unscheduling the fewest units of strictly lower priority.
+// Global units, required peers of the Job, and units required through
+// MachineOf by a unit of equal or higher priority are never unscheduled; a
+// unit is always unscheduled along with the units requiring it through
+// MachineOf. An error is returned if no such machine exists.
+func decidePreemption(clust *clusterState, j *job.Job) (*preemption, error) {
+	prio := j.Priority()
+
+	var best *preemption
+	for _, as := range sortedAgentsByID(clust) {
+		victims, ok := agentPreemption(clust, as, j, prio)
+		if !ok {
+			continue
+		}
+		if best == nil || len(victims) < len(best.victims) ||
+			(len(victims) == len(best.victims) && maxPriority(victims) < maxPriority(best.victims)) {
+			best = &preemption{
+				machineID: as.MState.ID,
+				victims:   victims,
+			}
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no agents able to run job by preempting units of lower priority than %d", prio)
+	}
+	return best, nil
+}
+
+// agentPreemption returns the jobs to unschedule from the given agent so
+// that it becomes able to run the Job, or false if unscheduling units of
+// lower priority than prio is not enough.
+func agentPreemption(clust *clusterState, as *agent.AgentState, j *job.Job, prio int) ([]*job.Job, bool) {
+	peers := make(map[string]bool)
+	for _, peer := range j.Peers() {
+		peers[peer] = true
+	}
+
+	var candidates []*job.Job
+	for name := range as.Units {
+		cj, ok := clust.jobs[name]
+		if !ok || peers[name] || cj.Priority() >= prio {
+			continue
+		}
+		candidates = append(candidates, cj)
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	sort.Sort(byPriority(candidates))
+
+	// Units conflicting with the Job need to go first, whatever their
+	// priority.
+	var conflicting, others []*job.Job
+	for _, cj := range candidates {
+		if conflicts(as, j, cj) {
+			conflicting = append(conflicting, cj)
+		} else {
+			others = append(others, cj)
+		}
+	}
+
+	var groups [][]*job.Job
+	evicted := make(map[string]*job.Unit)
+	evict := func(cj *job.Job) bool {
+		if _, ok := evicted[cj.Name]; ok {
+			return true
+		}
+		group, ok := dependentGroup(clust, as, cj, prio)
+		if !ok {
+			return false
+		}
+		var members []*job.Job
+		for _, member := range group {
+			if _, ok := evicted[member.Name]; ok {
+				continue
+			}
+			evicted[member.Name] = as.Units[member.Name]
+			delete(as.Units, member.Name)
+			members = append(members, member)
+		}
+		groups = append(groups, members)
+		return true
+	}
+	able := func() bool {
+		act, _ := as.AbleToRun(j)
+		return act != job.JobActionUnschedule
+	}
+
+	for _, cj := range conflicting {
+		if !evict(cj) {
+			return nil, false
+		}
+	}
+	required := len(groups)
+	for _, cj := range others {
+		if able() {
+			break
+		}
+		evict(cj)
+	}
+	if !able() {
+		return nil, false
+	}
+
+	// Units of lowest priority were unscheduled first, possibly in vain:
+	// keep those which are not needed for the Job to fit after all.
+	var victims []*job.Job
+	for i := len(groups) - 1; i >= 0; i-- {
+		for _, member := range groups[i] {
+			as.Units[member.Name] = evicted[member.Name]
+		}
+		if i >= required && peersScheduled(as, groups[i]) && able() {
+			continue
+		}
+		for _, member := range groups[i] {
+			delete(as.Units, member.Name)
+		}
+		victims = append(victims, groups[i]...)
+	}
+	sort.Sort(byPriority(victims))
+
+	return victims, true
+}
+
+// dependentGroup returns the given job along with the units of the agent
+// requiring it through MachineOf, recursively, or false if any of them has
+// a priority of at least prio or belongs to a gang, as evicting a member of
+// a gang would leave the rest of the gang holding its machines.
+func dependentGroup(clust *clusterState, as *agent.AgentState, cj *job.Job, prio int) ([]*job.Job, bool) {
+	if cj.Gang() != "" {
+		return nil, false
+	}
+	group := []*job.Job{cj}
+	seen := map[string]bool{cj.Name: true}
+	for i := 0; i < len(group); i++ {
+		for name, u := range as.Units {
+			if seen[name] || !hasString(u.Peers(), group[i].Name) {
+				continue
+			}
+			dj, ok := clust.jobs[name]
+			if !ok || dj.Priority() >= prio || dj.Gang() != "" {
+				return nil, false
+			}
+			seen[name] = true
+			group = append(group, dj)
+		}
+	}
+	return group, true
+}
+
+// peersScheduled determines whether the peers required by all of the given
+// jobs are scheduled to the agent.
+func peersScheduled(as *agent.AgentState, jobs []*job.Job) bool {
+	for _, j := range jobs {
+		for _, peer := range j.Peers() {
+			if _, ok := as.Units[peer]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// conflicts determines whether the given Job and the job scheduled to the
+// agent are prevented from running together by their Conflicts options.
+func conflicts(as *agent.AgentState, j, cj *job.Job) bool {
+	single := agent.NewAgentState(as.MState)
+	single.Units[cj.Name] = as.Units[cj.Name]
+	found, _ := single.HasConflict(j.Name, j.Conflicts())
+	return found
+}
+
+func hasString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func maxPriority(jobs []*job.Job) int {
+	max := jobs[0].Priority()
+	for _, j := range jobs[1:] {
+		if p := j.Priority(); p > max {
+			max = p
+		}
+	}
+	return max
+}
+
+// byPriority sorts jobs ascending by priority, then by name
+type byPriority []*job.Job
+
+func (bp byPriority) Len() int      { return len(bp) }
+func (bp byPriority) Swap(i, j int) { bp[i], bp[j] = bp[j], bp[i] }
+
+func (bp byPriority) Less(i, j int) bool {
+	iPrio := bp[i].Priority()
+	jPrio := bp[j].Priority()
+	return iPrio < jPrio || (iPrio == jPrio && bp[i].Name < bp[j].Name)
+}
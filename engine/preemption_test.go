@@ -0,0 +1,160 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/resource"
+)
+
+func TestDecidePreemption(t *testing.T) {
+	newClust := func() *clusterState {
+		units := []job.Unit{
+			job.Unit{Name: "low1.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Priority=-1\nReserveMemory=1024")},
+			job.Unit{Name: "low2.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Priority=-5\nReserveMemory=512")},
+			job.Unit{Name: "peerdep.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Priority=-10\nReserveMemory=512\nMachineOf=low1.service")},
+			job.Unit{Name: "mon.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Priority=50\nMachineOf=low2.service")},
+			job.Unit{Name: "crit.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Priority=100\nReserveMemory=1024")},
+			job.Unit{Name: "batch.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "ReserveMemory=1024")},
+		}
+		sUnits := []job.ScheduledUnit{
+			job.ScheduledUnit{Name: "low1.service", TargetMachineID: "AAA"},
+			job.ScheduledUnit{Name: "low2.service", TargetMachineID: "AAA"},
+			job.ScheduledUnit{Name: "peerdep.service", TargetMachineID: "AAA"},
+			job.ScheduledUnit{Name: "mon.service", TargetMachineID: "AAA"},
+			job.ScheduledUnit{Name: "crit.service", TargetMachineID: "BBB"},
+			job.ScheduledUnit{Name: "batch.service", TargetMachineID: "BBB"},
+		}
+		machines := []machine.MachineState{
+			machine.MachineState{ID: "AAA", TotalResources: resource.ResourceTuple{Cores: 800, Memory: 2304}},
+			machine.MachineState{ID: "BBB", TotalResources: resource.ResourceTuple{Cores: 800, Memory: 2304}},
+		}
+		return newClusterState(units, sUnits, machines)
+	}
+
+	tests := []struct {
+		values  string
+		machine string
+		victims []string
+	}{
+		// fewest victims win
+		{
+			"Priority=10\nReserveMemory=1024",
+			"BBB",
+			[]string{"batch.service"},
+		},
+		// conflicting units of higher priority are never unscheduled,
+		// required peers go along with the units they require
+		{
+			"Priority=10\nReserveMemory=1024\nConflicts=crit.service",
+			"AAA",
+			[]string{"peerdep.service", "low1.service"},
+		},
+		// required peers of the job are never unscheduled
+		{
+			"Priority=10\nReserveMemory=1024\nMachineOf=low1.service",
+			"",
+			nil,
+		},
+		// conflicting units are unscheduled whatever their priority
+		{
+			"Priority=10\nConflicts=low1.service\nMachineID=AAA",
+			"AAA",
+			[]string{"peerdep.service", "low1.service"},
+		},
+		// units requiring a victim are unscheduled along with it
+		{
+			"Priority=60\nReserveMemory=1024\nMachineID=AAA",
+			"AAA",
+			[]string{"peerdep.service", "low2.service", "mon.service"},
+		},
+		// units of equal priority are never unscheduled
+		{
+			"ReserveMemory=1024",
+			"AAA",
+			[]string{"peerdep.service", "low1.service"},
+		},
+		{
+			"Priority=-5\nReserveMemory=1024",
+			"",
+			nil,
+		},
+	}
+
+	for i, tt := range tests {
+		j := &job.Job{Name: "foo.service", Unit: newUnitWithXFleetValues(t, tt.values)}
+		pre, err := decidePreemption(newClust(), j)
+		if tt.machine == "" {
+			if err == nil {
+				t.Errorf("case %d: expected error, got preemption on %s of %v", i, pre.machineID, pre.victims)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		var victims []string
+		for _, v := range pre.victims {
+			victims = append(victims, v.Name)
+		}
+		if pre.machineID != tt.machine || !reflect.DeepEqual(victims, tt.victims) {
+			t.Errorf("case %d: expected preemption on %s of %v, got %s of %v", i, tt.machine, tt.victims, pre.machineID, victims)
+		}
+	}
+}
+
+func TestCalculateClusterTasksPreemption(t *testing.T) {
+	units := []job.Unit{
+		job.Unit{Name: "batch.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Priority=-1\nReserveMemory=1024")},
+		job.Unit{Name: "crit.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Priority=10\nReserveMemory=1024")},
+		job.Unit{Name: "other.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Priority=-1\nReserveMemory=1024")},
+	}
+	sUnits := []job.ScheduledUnit{
+		job.ScheduledUnit{Name: "batch.service", TargetMachineID: "AAA"},
+	}
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "AAA", TotalResources: resource.ResourceTuple{Cores: 800, Memory: 1280}},
+	}
+
+	r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
+	tasks := make([]*task, 0)
+	for tsk := range r.calculateClusterTasks(newClusterState(units, sUnits, machines), make(chan struct{})) {
+		tasks = append(tasks, tsk)
+	}
+
+	want := []*task{
+		&task{
+			Type:      taskTypeUnscheduleUnit,
+			Reason:    "preempted by Job(crit.service) of priority 10",
+			JobName:   "batch.service",
+			MachineID: "AAA",
+		},
+		&task{
+			Type:      taskTypeAttemptScheduleUnit,
+			Reason:    "target state launched and unit not scheduled",
+			JobName:   "crit.service",
+			MachineID: "AAA",
+		},
+	}
+	if !reflect.DeepEqual(want, tasks) {
+		t.Errorf("task mismatch\nexpected %v\n got %v", want, tasks)
+	}
+}
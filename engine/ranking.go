@@ -0,0 +1,135 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sort"
+
+	"github.com/cea-hpc/fleet/agent"
+	"github.com/cea-hpc/fleet/job"
+)
+
+// ranking orders candidate agents by the placement preferences of a job:
+// agents in the spread domains holding the fewest members of the job's
+// spread group come first, then agents with the highest preference score.
+type ranking struct {
+	agents []*agent.AgentState
+
+	// spreadKey is the job's SpreadBy metadata key, if spread is true
+	spreadKey string
+	spread    bool
+	// counts holds the number of group members per spread domain
+	counts map[string]int
+
+	// scores holds the preference score of each candidate by machine ID
+	scores map[string]int
+}
+
+// newRanking prepares the ranking of a copy of the given candidates. Spread
+// domains are populated from the units scheduled to all of the agents.
+func newRanking(agents, candidates []*agent.AgentState, j *job.Job) *ranking {
+	r := &ranking{
+		agents: make([]*agent.AgentState, len(candidates)),
+		scores: make(map[string]int, len(candidates)),
+	}
+	copy(r.agents, candidates)
+
+	r.spreadKey, r.spread = j.SpreadBy()
+	group := j.SpreadGroup()
+	if r.spread && group != "" {
+		r.counts = make(map[string]int)
+		for _, as := range agents {
+			value, ok := as.MState.Metadata[r.spreadKey]
+			if !ok {
+				continue
+			}
+			for _, u := range as.Units {
+				if u.Name != j.Name && u.SpreadGroup() == group {
+					r.counts[value]++
+				}
+			}
+		}
+	} else {
+		r.spread = false
+	}
+
+	for _, as := range candidates {
+		r.scores[as.MState.ID] = as.PreferenceScore(j)
+	}
+
+	return r
+}
+
+func (r *ranking) Len() int      { return len(r.agents) }
+func (r *ranking) Swap(i, j int) { r.agents[i], r.agents[j] = r.agents[j], r.agents[i] }
+
+func (r *ranking) Less(i, j int) bool {
+	if r.spread {
+		iValue, iOK := r.agents[i].MState.Metadata[r.spreadKey]
+		jValue, jOK := r.agents[j].MState.Metadata[r.spreadKey]
+		if !iOK || !jOK {
+			if iOK != jOK {
+				return iOK
+			}
+		} else if r.counts[iValue] != r.counts[jValue] {
+			return r.counts[iValue] < r.counts[jValue]
+		}
+	}
+	return r.scores[r.agents[i].MState.ID] > r.scores[r.agents[j].MState.ID]
+}
+
+// rankAgents reorders the given agents by the placement preferences of the
+// job. The order given by the scheduling strategy is kept among agents
+// ranked equally.
+func rankAgents(agents []*agent.AgentState, j *job.Job) []*agent.AgentState {
+	r := newRanking(agents, agents, j)
+	sort.Stable(r)
+	return r.agents
+}
+
+// rankCandidates returns the agents with room for another unit, ranked by
+// the placement preferences of the job. Full agents are left out of the
+// candidates only, as the units they run still populate the spread
+// domains.
+func rankCandidates(agents []*agent.AgentState, j *job.Job) []*agent.AgentState {
+	candidates := make([]*agent.AgentState, 0, len(agents))
+	for _, as := range agents {
+		if !as.Full() {
+			candidates = append(candidates, as)
+		}
+	}
+
+	r := newRanking(agents, candidates, j)
+	sort.Stable(r)
+	return r.agents
+}
+
+// bestRanked returns the candidates ranked first by the placement
+// preferences of the job, all of the given agents populating the spread
+// domains.
+func bestRanked(agents, candidates []*agent.AgentState, j *job.Job) []*agent.AgentState {
+	if len(candidates) == 0 {
+		return candidates
+	}
+
+	r := newRanking(agents, candidates, j)
+	sort.Stable(r)
+
+	best := 1
+	for best < len(r.agents) && !r.Less(0, best) {
+		best++
+	}
+	return r.agents[:best]
+}
@@ -0,0 +1,125 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cea-hpc/fleet/agent"
+	"github.com/cea-hpc/fleet/job"
+)
+
+// Rebalancer moves units from the most loaded agents of the cluster to the
+// least loaded ones, so that load does not stay skewed after machines join
+// the cluster.
+type Rebalancer struct {
+	// Threshold is the difference of weighted load between two agents
+	// above which units are moved from one to the other
+	Threshold uint16
+	// MaxMoves caps the number of units moved per reconciliation
+	MaxMoves int
+}
+
+// move describes a unit to be moved from one machine to another.
+type move struct {
+	jobName string
+	from    string
+	to      string
+}
+
+// nextMove returns the next unit to be moved to rebalance the cluster, or
+// nil if the cluster is balanced or no unit can be moved. The unit with the
+// highest weight still lowering the difference of load is moved from the
+// most loaded agent to the least loaded agent able to run it.
+func (rb *Rebalancer) nextMove(clust *clusterState) *move {
+	agents := make(sortableAgentStates, 0)
+	for _, as := range clust.agents() {
+		agents = append(agents, as)
+	}
+	if len(agents) < 2 {
+		return nil
+	}
+	sort.Sort(agents)
+
+	src := agents[len(agents)-1]
+	srcLoad := agentLoad(src)
+	if srcLoad-agentLoad(agents[0]) <= rb.Threshold {
+		return nil
+	}
+
+	for _, j := range movableJobs(clust, src) {
+		weight := j.Weight()
+		if weight == 0 {
+			weight = 1
+		}
+		for _, dst := range agents[:len(agents)-1] {
+			// Moving the unit must leave the destination less loaded than
+			// the source was, or units would keep bouncing between them.
+			if agentLoad(dst)+weight >= srcLoad {
+				break
+			}
+			if act, _ := dst.AbleToRun(j); act == job.JobActionUnschedule {
+				continue
+			}
+			return &move{jobName: j.Name, from: src.MState.ID, to: dst.MState.ID}
+		}
+	}
+
+	return nil
+}
+
+// movableJobs returns the jobs scheduled to the agent which may be moved,
+// sorted descending by weight, then ascending by name. Jobs pinned to the
+// agent by MachineID or MachineOf, jobs required by other units of the
+// agent through MachineOf, and jobs with Movable=false are left out.
+func movableJobs(clust *clusterState, as *agent.AgentState) []*job.Job {
+	required := make(map[string]bool)
+	for _, u := range as.Units {
+		for _, peer := range u.Peers() {
+			required[peer] = true
+		}
+	}
+
+	var jobs []*job.Job
+	for name := range as.Units {
+		j, ok := clust.jobs[name]
+		if !ok || required[name] || !j.Movable() || len(j.Peers()) != 0 {
+			continue
+		}
+		if _, ok := j.RequiredTarget(); ok {
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	sort.Sort(byWeightDescending(jobs))
+
+	return jobs
+}
+
+func (m *move) reason() string {
+	return fmt.Sprintf("rebalancing load from Machine(%s) to Machine(%s)", m.from, m.to)
+}
+
+type byWeightDescending []*job.Job
+
+func (wd byWeightDescending) Len() int      { return len(wd) }
+func (wd byWeightDescending) Swap(i, j int) { wd[i], wd[j] = wd[j], wd[i] }
+
+func (wd byWeightDescending) Less(i, j int) bool {
+	iWeight := wd[i].Weight()
+	jWeight := wd[j].Weight()
+	return iWeight > jWeight || (iWeight == jWeight && wd[i].Name < wd[j].Name)
+}
@@ -0,0 +1,136 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+)
+
+func TestRebalancerNextMove(t *testing.T) {
+	newClust := func(values map[string]string, sched map[string]string, machines []string) *clusterState {
+		var units []job.Unit
+		var sUnits []job.ScheduledUnit
+		for name, v := range values {
+			units = append(units, job.Unit{Name: name, TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, v)})
+			sUnits = append(sUnits, job.ScheduledUnit{Name: name, TargetMachineID: sched[name]})
+		}
+		var ms []machine.MachineState
+		for _, id := range machines {
+			ms = append(ms, machine.MachineState{ID: id})
+		}
+		return newClusterState(units, sUnits, ms)
+	}
+
+	tests := []struct {
+		values   map[string]string
+		sched    map[string]string
+		machines []string
+		want     *move
+	}{
+		// balanced enough
+		{
+			map[string]string{"1.service": ""},
+			map[string]string{"1.service": "AAA"},
+			[]string{"AAA", "BBB"},
+			nil,
+		},
+		// heaviest unit lowering the difference moves to the least loaded agent
+		{
+			map[string]string{"1.service": "Weight=3", "2.service": "Weight=2", "3.service": "", "4.service": ""},
+			map[string]string{"1.service": "AAA", "2.service": "AAA", "3.service": "AAA", "4.service": "BBB"},
+			[]string{"AAA", "BBB", "CCC"},
+			&move{jobName: "1.service", from: "AAA", to: "CCC"},
+		},
+		// units too heavy to lower the difference stay
+		{
+			map[string]string{"1.service": "Weight=4", "2.service": "Weight=1", "3.service": "Weight=2"},
+			map[string]string{"1.service": "AAA", "2.service": "AAA", "3.service": "BBB"},
+			[]string{"AAA", "BBB"},
+			&move{jobName: "2.service", from: "AAA", to: "BBB"},
+		},
+		// pinned, required and opted out units stay
+		{
+			map[string]string{
+				"1.service": "Movable=false",
+				"2.service": "MachineOf=3.service",
+				"3.service": "",
+				"4.service": "MachineID=AAA",
+			},
+			map[string]string{"1.service": "AAA", "2.service": "AAA", "3.service": "AAA", "4.service": "AAA"},
+			[]string{"AAA", "BBB"},
+			nil,
+		},
+		// agents unable to run the unit are skipped
+		{
+			map[string]string{"1.service": "Conflicts=2.service", "2.service": "", "3.service": ""},
+			map[string]string{"1.service": "AAA", "3.service": "AAA", "2.service": "BBB"},
+			[]string{"AAA", "BBB", "CCC"},
+			&move{jobName: "1.service", from: "AAA", to: "CCC"},
+		},
+	}
+
+	rb := &Rebalancer{Threshold: 1, MaxMoves: 1}
+	for i, tt := range tests {
+		got := rb.nextMove(newClust(tt.values, tt.sched, tt.machines))
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: expected move %+v, got %+v", i, tt.want, got)
+		}
+	}
+}
+
+func TestCalculateClusterTasksRebalance(t *testing.T) {
+	var units []job.Unit
+	var sUnits []job.ScheduledUnit
+	for _, name := range []string{"1.service", "2.service", "3.service", "4.service", "5.service"} {
+		units = append(units, job.Unit{Name: name, TargetState: job.JobStateLaunched})
+		sUnits = append(sUnits, job.ScheduledUnit{Name: name, TargetMachineID: "AAA"})
+	}
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "AAA"},
+		machine.MachineState{ID: "BBB"},
+	}
+
+	for _, tt := range []struct {
+		rebal *Rebalancer
+		moves int
+	}{
+		{nil, 0},
+		{&Rebalancer{Threshold: 1, MaxMoves: 1}, 1},
+		{&Rebalancer{Threshold: 1, MaxMoves: 5}, 2},
+		{&Rebalancer{Threshold: 5, MaxMoves: 5}, 0},
+	} {
+		r := NewReconciler(&leastLoadedScheduler{}, tt.rebal, 0)
+		var tasks []*task
+		for tsk := range r.calculateClusterTasks(newClusterState(units, sUnits, machines), make(chan struct{})) {
+			tasks = append(tasks, tsk)
+		}
+
+		if len(tasks) != 2*tt.moves {
+			t.Errorf("rebalancer %+v: expected %d moves, got tasks %v", tt.rebal, tt.moves, tasks)
+			continue
+		}
+		for i := 0; i < len(tasks); i += 2 {
+			if tasks[i].Type != taskTypeUnscheduleUnit || tasks[i].MachineID != "AAA" ||
+				tasks[i+1].Type != taskTypeAttemptScheduleUnit || tasks[i+1].MachineID != "BBB" ||
+				tasks[i].JobName != tasks[i+1].JobName {
+				t.Errorf("rebalancer %+v: unexpected move tasks %v, %v", tt.rebal, tasks[i], tasks[i+1])
+			}
+		}
+	}
+}
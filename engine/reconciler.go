@@ -16,16 +16,22 @@ package engine
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/jonboulle/clockwork"
+
 	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/log"
 	"github.com/cea-hpc/fleet/metrics"
+	"github.com/cea-hpc/fleet/registry"
 )
 
 const (
 	taskTypeUnscheduleUnit      = "UnscheduleUnit"
 	taskTypeAttemptScheduleUnit = "AttemptScheduleUnit"
+	taskTypeCreateUnit          = "CreateUnit"
+	taskTypeDestroyUnit         = "DestroyUnit"
 )
 
 type task struct {
@@ -39,14 +45,58 @@ func (t *task) String() string {
 	return fmt.Sprintf("{Type: %s, JobName: %s, MachineID: %s, Reason: %q}", t.Type, t.JobName, t.MachineID, t.Reason)
 }
 
-func NewReconciler() *Reconciler {
+// NewReconciler returns a Reconciler placing units with the given
+// Scheduler. Units are only moved to rebalance the cluster if a Rebalancer
+// is given. Units scheduled to a machine which went away are rescheduled
+// once rescheduleDelay has elapsed, unless they set their own
+// RescheduleDelay.
+func NewReconciler(sched Scheduler, rebal *Rebalancer, rescheduleDelay time.Duration) *Reconciler {
 	return &Reconciler{
-		sched: &leastLoadedScheduler{},
+		sched:           sched,
+		rebal:           rebal,
+		rescheduleDelay: rescheduleDelay,
+		lostMachines:    make(map[string]time.Time),
+		pendingSince:    make(map[string]time.Time),
+		failures:        make(map[string]*failureRecord),
+		excluded:        make(map[string]map[string]time.Time),
+		deferred:        make(map[string]bool),
+		clock:           clockwork.NewRealClock(),
 	}
 }
 
 type Reconciler struct {
-	sched Scheduler
+	sched           Scheduler
+	rebal           *Rebalancer
+	rescheduleDelay time.Duration
+
+	// throttle, when set, caps the number of tasks issued per
+	// reconciliation
+	throttle *Throttle
+
+	// lostMachines records when the machines which went away while units
+	// were still scheduled to them were first found missing
+	lostMachines map[string]time.Time
+	// pendingSince records when the units waiting to be scheduled were
+	// first found unscheduled
+	pendingSince map[string]time.Time
+	// failures follows the failures of the units limiting them on the
+	// machine they are scheduled to
+	failures map[string]*failureRecord
+	// excluded records until when units are kept away from the machines
+	// they failed too often on
+	excluded map[string]map[string]time.Time
+	// rebuildFailures is set when the failures published before the
+	// engine took the lead are to be counted
+	rebuildFailures bool
+	// published holds the exclusions last saved in the Registry, valid
+	// once exclusionsPublished is set
+	published           []registry.EngineExclusion
+	exclusionsPublished bool
+	// deferred records the scheduled units whose unscheduling or
+	// rescheduling was deferred by the throttle, so that they are
+	// reconciled again in the next round even if nothing changed
+	deferred map[string]bool
+	clock    clockwork.Clock
 }
 
 func (r *Reconciler) Reconcile(e *Engine, stop chan struct{}) {
@@ -60,35 +110,55 @@ func (r *Reconciler) Reconcile(e *Engine, stop chan struct{}) {
 		return
 	}
 
+	// the UnitStates are not watched, so they are only read along with
+	// the whole cluster state rather than at every incremental round
+	if clust.full() && limitsFailures(clust) {
+		states, err := e.registry.UnitStates()
+		if err != nil {
+			log.Errorf("Failed fetching UnitStates from Registry: %v", err)
+		} else {
+			clust.setUnitStates(states)
+		}
+	}
+
+	var resolved []*task
 	for t := range r.calculateClusterTasks(clust, stop) {
 		err = doTask(t, e)
 		if err != nil {
 			log.Errorf("Failed resolving task: task=%s err=%v", t, err)
+		} else {
+			resolved = append(resolved, t)
+		}
+		// the outcome of the task is read back from the Registry
+		// in the next round, in case it failed or raced with another
+		// change
+		if e.model != nil {
+			e.model.unitChanged(t.JobName)
 		}
 	}
 
+	r.publishExclusions(e.cRegistry)
+
+	var leaderID string
+	if e.lease != nil {
+		leaderID = e.lease.MachineID()
+	}
+	e.history.record(e.cRegistry, leaderID, resolved)
+
 	metrics.ReportEngineReconcileSuccess(start)
 }
 
 func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan struct{}) (taskchan chan *task) {
 	taskchan = make(chan *task)
 
-	go func() {
-		agents := clust.agents()
-		metrics.ReportAvailableAgents(len(agents))
-		metrics.ResetAgents()
-		for _, as := range agents {
-			load := uint16(0)
-			for _, unit := range as.Units {
-				if unit.Weight == 0 {
-					load += 1
-				} else {
-					load += unit.Weight
-				}
-			}
-			metrics.ReportAgentLoad(as.MState.ID, load)
-		}
-	}()
+	// reported before any task is issued, as a long-lived clusterState is
+	// updated again as soon as the next round begins
+	agents := clust.agents()
+	metrics.ReportAvailableAgents(len(agents))
+	metrics.ResetAgents()
+	for _, as := range agents {
+		metrics.ReportAgentLoad(as.MState.ID, agentLoad(as))
+	}
 
 	send := func(typ, reason, jName, machID string) bool {
 		select {
@@ -101,6 +171,13 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 		return true
 	}
 
+	// number of units kept on lost machines during their reschedule delay
+	waiting := 0
+
+	budget := newTaskBudget(r.throttle)
+	deferred := r.deferred
+	r.deferred = make(map[string]bool)
+
 	decide := func(j *job.Job) (jobAction job.JobAction, reason string) {
 		if j.TargetState == job.JobStateInactive {
 			return job.JobActionUnschedule, "target state inactive"
@@ -110,10 +187,18 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 
 		as, ok := agents[j.TargetMachineID]
 		if !ok {
+			if grace := r.rescheduleGrace(j); grace > 0 {
+				waiting++
+				return job.JobActionSchedule, fmt.Sprintf("target Machine(%s) went away, rescheduling in %v", j.TargetMachineID, grace)
+			}
 			metrics.ReportEngineReconcileFailure(metrics.MachineAway)
 			return job.JobActionUnschedule, fmt.Sprintf("target Machine(%s) went away", j.TargetMachineID)
 		}
 
+		if as.MState.Draining() {
+			return job.JobActionUnschedule, fmt.Sprintf("target Machine(%s) is draining", j.TargetMachineID)
+		}
+
 		if act, ableReason := as.AbleToRun(j); act != job.JobActionSchedule {
 			metrics.ReportEngineReconcileFailure(metrics.RunFailure)
 			return act, fmt.Sprintf("target Machine(%s) unable to run unit: %v",
@@ -148,24 +233,34 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 				continue
 			}
 
-			if !send(taskTypeUnscheduleUnit, reason, replacedUnit, j.TargetMachineID) {
-				log.Infof("Job(%s) unschedule send failed", replacedUnit)
+			dec, err := r.sched.DecideReschedule(clust, j)
+			if err != nil {
+				log.Debugf("Unable to schedule Job(%s): %v", j.Name, err)
 				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
 				continue
 			}
 
-			dec, err := r.sched.DecideReschedule(clust, j)
-			if err != nil {
-				log.Debugf("Unable to schedule Job(%s): %v", j.Name, err)
+			if !budget.allow(taskTypeUnscheduleUnit, j.TargetMachineID) || !budget.allow(taskTypeAttemptScheduleUnit, dec.machineID) {
+				log.Debugf("Job(%s) rescheduling deferred by throttle", replacedUnit)
+				budget.postpone(taskTypeUnscheduleUnit, 1)
+				budget.postpone(taskTypeAttemptScheduleUnit, 1)
+				r.deferred[j.Name] = true
+				continue
+			}
+
+			if !send(taskTypeUnscheduleUnit, reason, replacedUnit, j.TargetMachineID) {
+				log.Infof("Job(%s) unschedule send failed", replacedUnit)
 				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
 				continue
 			}
+			budget.take(taskTypeUnscheduleUnit, j.TargetMachineID)
 
 			if !send(taskTypeAttemptScheduleUnit, reason, replacedUnit, dec.machineID) {
 				log.Infof("Job(%s) attemptschedule send failed", replacedUnit)
 				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
 				continue
 			}
+			budget.take(taskTypeAttemptScheduleUnit, dec.machineID)
 			clust.schedule(replacedUnit, dec.machineID)
 			log.Debugf("rescheduling unit %s to machine %s", replacedUnit, dec.machineID)
 
@@ -180,6 +275,35 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 
 	go func() {
 		defer close(taskchan)
+		defer budget.report()
+
+		r.forgetLostMachines(clust)
+		r.trackFailures(clust)
+
+		// Instances of replicated templates are created and destroyed
+		// first, so that new instances are scheduled in the same round.
+		for _, rc := range replicaChanges(clust) {
+			for _, name := range rc.destroy {
+				if !send(taskTypeDestroyUnit, rc.reason(), name, clust.jobs[name].TargetMachineID) {
+					metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+					return
+				}
+				clust.destroy(name)
+			}
+
+			tmpl := clust.templates[rc.template]
+			for _, name := range rc.create {
+				if !send(taskTypeCreateUnit, rc.reason(), name, "") {
+					metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+					return
+				}
+				clust.create(&job.Job{
+					Name:        name,
+					Unit:        tmpl.Unit,
+					TargetState: job.JobStateLaunched,
+				})
+			}
+		}
 
 		for _, j := range clust.jobs {
 			if !j.Scheduled() {
@@ -187,7 +311,19 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 			}
 			metrics.ReportClusterJob(j.Name, &j.TargetMachineID, true)
 
-			act, reason := decide(j)
+			// failures are reported by the machines, whether the Job
+			// changed or not
+			reason, failing := r.failedTooOften(j)
+			if !failing && !clust.affected(j) && !deferred[j.Name] {
+				continue
+			}
+
+			act := job.JobActionUnschedule
+			if failing {
+				metrics.ReportEngineReconcileFailure(metrics.UnitFailing)
+			} else {
+				act, reason = decide(j)
+			}
 			if act == job.JobActionReschedule && handle_reschedule(j, reason) {
 				log.Debugf("Job(%s) is rescheduled: %v", j.Name, reason)
 				continue
@@ -198,26 +334,123 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 				continue
 			}
 
+			if !budget.allow(taskTypeUnscheduleUnit, j.TargetMachineID) {
+				log.Debugf("Job(%s) unscheduling deferred by throttle, reason: %v", j.Name, reason)
+				budget.postpone(taskTypeUnscheduleUnit, 1)
+				r.deferred[j.Name] = true
+				continue
+			}
+
 			if !send(taskTypeUnscheduleUnit, reason, j.Name, j.TargetMachineID) {
 				log.Infof("Job(%s) send failed.", j.Name)
 				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
 				return
 			}
+			budget.take(taskTypeUnscheduleUnit, j.TargetMachineID)
 
 			metrics.ReportClusterJob(j.Name, &j.TargetMachineID, false)
 			log.Debugf("Job(%s) unscheduling.", j.Name)
 			clust.unschedule(j.Name)
 		}
 
+		metrics.ReportEngineRescheduleWaiting(waiting)
+
+		// Units of highest priority are scheduled first, so that they may
+		// preempt the units of lower priority still to be scheduled. Units
+		// of the same priority are scheduled in the order they started
+		// waiting.
+		var pending []*job.Job
 		for _, j := range clust.jobs {
 			if j.Scheduled() || j.TargetState == job.JobStateInactive {
 				continue
 			}
+			pending = append(pending, j)
+		}
+		r.trackPending(pending)
+		sort.Sort(byWaitingTime{jobs: pending, since: r.pendingSince})
+
+		gangs := make(map[string]bool)
+		for _, j := range pending {
+			// members of a gang are all scheduled along with the first
+			// of them, or not at all
+			if gang := j.Gang(); gang != "" {
+				if gangs[gang] {
+					continue
+				}
+				gangs[gang] = true
+
+				gp, err := decideGang(r.sched, clust, gang)
+				if err != nil {
+					log.Infof("Unable to schedule Gang(%s): %v", gang, err)
+					metrics.ReportEngineReconcileFailure(metrics.GangFailure)
+					continue
+				}
+
+				machIDs := make([]string, len(gp.decisions))
+				for i, dec := range gp.decisions {
+					machIDs[i] = dec.machineID
+				}
+				if !budget.allow(taskTypeAttemptScheduleUnit, machIDs...) {
+					log.Debugf("Gang(%s) scheduling deferred by throttle", gang)
+					budget.postpone(taskTypeAttemptScheduleUnit, len(gp.jobs))
+					continue
+				}
+
+				for i, gj := range gp.jobs {
+					reason := fmt.Sprintf("target state %s and unit not scheduled, placing Gang(%s)", gj.TargetState, gang)
+					if !send(taskTypeAttemptScheduleUnit, reason, gj.Name, gp.decisions[i].machineID) {
+						metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+						return
+					}
+					budget.take(taskTypeAttemptScheduleUnit, gp.decisions[i].machineID)
+					clust.schedule(gj.Name, gp.decisions[i].machineID)
+				}
+				continue
+			}
+
+			if budget.exhausted(taskTypeAttemptScheduleUnit) {
+				log.Debugf("Job(%s) scheduling deferred by throttle", j.Name)
+				budget.postpone(taskTypeAttemptScheduleUnit, 1)
+				continue
+			}
 
 			dec, err := r.sched.Decide(clust, j)
 			if err != nil {
-				log.Debugf("Unable to schedule Job(%s): %v", j.Name, err)
-				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+				pre, perr := decidePreemption(clust, j)
+				if perr != nil {
+					log.Debugf("Unable to schedule Job(%s): %v", j.Name, err)
+					metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+					continue
+				}
+
+				victims := make([]string, len(pre.victims))
+				for i := range pre.victims {
+					victims[i] = pre.machineID
+				}
+				if !budget.allow(taskTypeUnscheduleUnit, victims...) || !budget.allow(taskTypeAttemptScheduleUnit, pre.machineID) {
+					log.Debugf("Job(%s) preemption deferred by throttle", j.Name)
+					budget.postpone(taskTypeUnscheduleUnit, len(pre.victims))
+					budget.postpone(taskTypeAttemptScheduleUnit, 1)
+					continue
+				}
+
+				for _, victim := range pre.victims {
+					reason := fmt.Sprintf("preempted by Job(%s) of priority %d", j.Name, j.Priority())
+					log.Infof("Evicting Job(%s) of priority %d from Machine(%s): %s", victim.Name, victim.Priority(), pre.machineID, reason)
+					if !send(taskTypeUnscheduleUnit, reason, victim.Name, pre.machineID) {
+						metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+						return
+					}
+					budget.take(taskTypeUnscheduleUnit, pre.machineID)
+					metrics.ReportClusterJob(victim.Name, &pre.machineID, false)
+					clust.unschedule(victim.Name)
+				}
+				dec = &decision{machineID: pre.machineID}
+			}
+
+			if !budget.allow(taskTypeAttemptScheduleUnit, dec.machineID) {
+				log.Debugf("Job(%s) scheduling to Machine(%s) deferred by throttle", j.Name, dec.machineID)
+				budget.postpone(taskTypeAttemptScheduleUnit, 1)
 				continue
 			}
 
@@ -226,9 +459,39 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
 				return
 			}
+			budget.take(taskTypeAttemptScheduleUnit, dec.machineID)
 
 			clust.schedule(j.Name, dec.machineID)
 		}
+
+		if r.rebal == nil {
+			return
+		}
+		for moves := 0; moves < r.rebal.MaxMoves; moves++ {
+			m := r.rebal.nextMove(clust)
+			if m == nil {
+				break
+			}
+			if !budget.allow(taskTypeUnscheduleUnit, m.from) || !budget.allow(taskTypeAttemptScheduleUnit, m.to) {
+				log.Debugf("Moving Job(%s) deferred by throttle", m.jobName)
+				break
+			}
+
+			reason := m.reason()
+			if !send(taskTypeUnscheduleUnit, reason, m.jobName, m.from) {
+				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+				return
+			}
+			if !send(taskTypeAttemptScheduleUnit, reason, m.jobName, m.to) {
+				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+				return
+			}
+			budget.take(taskTypeUnscheduleUnit, m.from)
+			budget.take(taskTypeAttemptScheduleUnit, m.to)
+			log.Infof("Moving Job(%s): %s", m.jobName, reason)
+			metrics.ReportEngineRebalanceMove(m.from, m.to)
+			clust.schedule(m.jobName, m.to)
+		}
 	}()
 
 	return
@@ -240,7 +503,15 @@ func doTask(t *task, e *Engine) (err error) {
 		err = e.unscheduleUnit(t.JobName, t.MachineID)
 		metrics.ReportEngineTask(t.Type)
 	case taskTypeAttemptScheduleUnit:
-		e.attemptScheduleUnit(t.JobName, t.MachineID)
+		if !e.attemptScheduleUnit(t.JobName, t.MachineID) {
+			err = fmt.Errorf("Unit(%s) not scheduled to Machine(%s)", t.JobName, t.MachineID)
+		}
+		metrics.ReportEngineTask(t.Type)
+	case taskTypeCreateUnit:
+		err = e.createInstance(t.JobName)
+		metrics.ReportEngineTask(t.Type)
+	case taskTypeDestroyUnit:
+		err = e.destroyUnit(t.JobName)
 		metrics.ReportEngineTask(t.Type)
 	default:
 		err = fmt.Errorf("unrecognized task type %q", t.Type)
@@ -254,3 +525,61 @@ func doTask(t *task, e *Engine) (err error) {
 
 	return
 }
+
+// rescheduleGrace returns how long the Job, scheduled to a machine which
+// went away, is still to wait before being rescheduled. The delay of the
+// Job's own RescheduleDelay takes precedence over the cluster-wide one.
+func (r *Reconciler) rescheduleGrace(j *job.Job) time.Duration {
+	delay := r.rescheduleDelay
+	if d, ok := j.RescheduleDelay(); ok {
+		delay = d
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	since, ok := r.lostMachines[j.TargetMachineID]
+	if !ok {
+		since = r.clock.Now()
+		r.lostMachines[j.TargetMachineID] = since
+		log.Infof("Machine(%s) went away, keeping its units for up to %v", j.TargetMachineID, delay)
+	}
+	return delay - r.clock.Since(since)
+}
+
+// trackPending records when each of the given jobs, waiting to be
+// scheduled, was first found waiting, and forgets the jobs which are not
+// waiting anymore.
+func (r *Reconciler) trackPending(pending []*job.Job) {
+	waiting := make(map[string]bool, len(pending))
+	for _, j := range pending {
+		waiting[j.Name] = true
+		if _, ok := r.pendingSince[j.Name]; !ok {
+			r.pendingSince[j.Name] = r.clock.Now()
+		}
+	}
+
+	for name := range r.pendingSince {
+		if !waiting[name] {
+			delete(r.pendingSince, name)
+		}
+	}
+}
+
+// forgetLostMachines drops the lost machines which came back, or to which
+// no unit is scheduled anymore, so that their reschedule delay starts over
+// should they go away again.
+func (r *Reconciler) forgetLostMachines(clust *clusterState) {
+	targeted := make(map[string]bool)
+	for _, j := range clust.jobs {
+		if j.Scheduled() {
+			targeted[j.TargetMachineID] = true
+		}
+	}
+
+	for id := range r.lostMachines {
+		if _, ok := clust.machines[id]; ok || !targeted[id] {
+			delete(r.lostMachines, id)
+		}
+	}
+}
@@ -17,6 +17,9 @@ package engine
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
 
 	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/machine"
@@ -154,10 +157,71 @@ func TestCalculateClusterTasks(t *testing.T) {
 				},
 			},
 		},
+
+		// reschedule Job away from a draining machine
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{
+						Name:        "foo.service",
+						TargetState: job.JobStateLaunched,
+					},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{
+						Name:            "foo.service",
+						State:           &jsLaunched,
+						TargetMachineID: "XXX",
+					},
+				},
+				[]machine.MachineState{
+					machine.MachineState{ID: "XXX", Maintenance: machine.MaintenanceDraining},
+					machine.MachineState{ID: "YYY"},
+				},
+			),
+			tasks: []*task{
+				&task{
+					Type:      taskTypeUnscheduleUnit,
+					Reason:    "target Machine(XXX) is draining",
+					JobName:   "foo.service",
+					MachineID: "XXX",
+				},
+				&task{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "target state launched and unit not scheduled",
+					JobName:   "foo.service",
+					MachineID: "YYY",
+				},
+			},
+		},
+
+		// keep Job on a cordoned machine
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{
+						Name:        "foo.service",
+						TargetState: job.JobStateLaunched,
+					},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{
+						Name:            "foo.service",
+						State:           &jsLaunched,
+						TargetMachineID: "XXX",
+					},
+				},
+				[]machine.MachineState{
+					machine.MachineState{ID: "XXX", Maintenance: machine.MaintenanceCordoned},
+					machine.MachineState{ID: "YYY"},
+				},
+			),
+			tasks: []*task{},
+		},
 	}
 
 	for i, tt := range tests {
-		r := NewReconciler()
+		r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
 		tasks := make([]*task, 0)
 		for tsk := range r.calculateClusterTasks(tt.clust, make(chan struct{})) {
 			tasks = append(tasks, tsk)
@@ -168,3 +232,131 @@ func TestCalculateClusterTasks(t *testing.T) {
 		}
 	}
 }
+
+func TestCalculateClusterTasksSpreadBy(t *testing.T) {
+	var units []job.Unit
+	for _, name := range []string{"web@1.service", "web@2.service", "web@3.service", "web@4.service"} {
+		units = append(units, job.Unit{
+			Name:        name,
+			Unit:        newUnitWithXFleetValues(t, "SpreadBy=rack"),
+			TargetState: job.JobStateLaunched,
+		})
+	}
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "AAA", Metadata: map[string]string{"rack": "r1"}},
+		machine.MachineState{ID: "BBB", Metadata: map[string]string{"rack": "r1"}},
+		machine.MachineState{ID: "CCC", Metadata: map[string]string{"rack": "r1"}},
+		machine.MachineState{ID: "DDD", Metadata: map[string]string{"rack": "r2"}},
+		machine.MachineState{ID: "EEE"},
+	}
+	racks := map[string]string{"AAA": "r1", "BBB": "r1", "CCC": "r1", "DDD": "r2"}
+
+	for _, sched := range []Scheduler{&leastLoadedScheduler{}, &binPackScheduler{}, &roundRobinScheduler{}} {
+		clust := newClusterState(units, []job.ScheduledUnit{}, machines)
+		r := NewReconciler(sched, nil, 0)
+		perRack := make(map[string]int)
+		for tsk := range r.calculateClusterTasks(clust, make(chan struct{})) {
+			if tsk.Type != taskTypeAttemptScheduleUnit {
+				t.Errorf("%s: unexpected task %v", sched.Name(), tsk)
+				continue
+			}
+			perRack[racks[tsk.MachineID]]++
+		}
+
+		want := map[string]int{"r1": 2, "r2": 2}
+		if !reflect.DeepEqual(want, perRack) {
+			t.Errorf("%s: expected units per rack %v, got %v", sched.Name(), want, perRack)
+		}
+	}
+}
+
+func TestCalculateClusterTasksRescheduleDelay(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	newClust := func(unitOpts string, machines ...machine.MachineState) *clusterState {
+		return newClusterState(
+			[]job.Unit{
+				job.Unit{
+					Name:        "foo.service",
+					Unit:        newUnitWithXFleetValues(t, unitOpts),
+					TargetState: job.JobStateLaunched,
+				},
+			},
+			[]job.ScheduledUnit{
+				job.ScheduledUnit{
+					Name:            "foo.service",
+					State:           &jsLaunched,
+					TargetMachineID: "ZZZ",
+				},
+			},
+			machines,
+		)
+	}
+	rescheduled := []*task{
+		&task{
+			Type:      taskTypeUnscheduleUnit,
+			Reason:    "target Machine(ZZZ) went away",
+			JobName:   "foo.service",
+			MachineID: "ZZZ",
+		},
+		&task{
+			Type:      taskTypeAttemptScheduleUnit,
+			Reason:    "target state launched and unit not scheduled",
+			JobName:   "foo.service",
+			MachineID: "XXX",
+		},
+	}
+
+	tests := []struct {
+		unitOpts string
+		// machine ZZZ is back for the round at this index, if positive
+		back int
+		// elapsed time before each round, and the tasks expected from it
+		rounds []time.Duration
+		tasks  [][]*task
+	}{
+		// units stay on the lost machine until the delay elapses
+		{
+			rounds: []time.Duration{0, 30 * time.Second, 31 * time.Second},
+			tasks:  [][]*task{{}, {}, rescheduled},
+		},
+		// the unit's own delay takes precedence
+		{
+			unitOpts: "RescheduleDelay=0",
+			rounds:   []time.Duration{0},
+			tasks:    [][]*task{rescheduled},
+		},
+		{
+			unitOpts: "RescheduleDelay=2m",
+			rounds:   []time.Duration{0, 61 * time.Second, 60 * time.Second},
+			tasks:    [][]*task{{}, {}, rescheduled},
+		},
+		// the delay starts over when the machine comes back
+		{
+			back:   1,
+			rounds: []time.Duration{0, 50 * time.Second, 0, 50 * time.Second, 11 * time.Second},
+			tasks:  [][]*task{{}, {}, {}, {}, rescheduled},
+		},
+	}
+
+	for i, tt := range tests {
+		r := NewReconciler(&leastLoadedScheduler{}, nil, time.Minute)
+		fclock := clockwork.NewFakeClock()
+		r.clock = fclock
+
+		for round, elapsed := range tt.rounds {
+			fclock.Advance(elapsed)
+			machines := []machine.MachineState{machine.MachineState{ID: "XXX"}}
+			if round == tt.back && tt.back > 0 {
+				machines = append(machines, machine.MachineState{ID: "ZZZ"})
+			}
+
+			tasks := make([]*task, 0)
+			for tsk := range r.calculateClusterTasks(newClust(tt.unitOpts, machines...), make(chan struct{})) {
+				tasks = append(tasks, tsk)
+			}
+			if !reflect.DeepEqual(tt.tasks[round], tasks) {
+				t.Errorf("case %d, round %d: task mismatch\nexpected %v\n got %v", i, round, tt.tasks[round], tasks)
+			}
+		}
+	}
+}
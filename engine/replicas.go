@@ -0,0 +1,114 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/cea-hpc/fleet/unit"
+)
+
+// replicaChange lists the instances to create and destroy to bring a
+// replicated template to its number of replicas.
+type replicaChange struct {
+	template string
+	replicas int
+	// create lists instances in ascending order of number
+	create []string
+	// destroy lists instances in descending order of number, so that
+	// the highest-numbered instances are removed first
+	destroy []string
+}
+
+func (rc *replicaChange) reason() string {
+	return fmt.Sprintf("Template(%s) scaled to %d replicas", rc.template, rc.replicas)
+}
+
+// replicaChanges compares the instances of each replicated template, named
+// after the numbers 1 to N, to the N replicas of the template. Instances not
+// named after a positive number are left alone.
+func replicaChanges(clust *clusterState) []replicaChange {
+	numbers := make(map[string]map[int]bool)
+	for name := range clust.jobs {
+		uni := unit.NewUnitNameInfo(name)
+		if uni == nil || !uni.IsInstance() {
+			continue
+		}
+		if _, ok := clust.templates[uni.Template]; !ok {
+			continue
+		}
+		n := instanceNumber(uni.Instance)
+		if n == 0 {
+			continue
+		}
+		if numbers[uni.Template] == nil {
+			numbers[uni.Template] = make(map[int]bool)
+		}
+		numbers[uni.Template][n] = true
+	}
+
+	var templates sort.StringSlice
+	for name := range clust.templates {
+		templates = append(templates, name)
+	}
+	templates.Sort()
+
+	var changes []replicaChange
+	for _, name := range templates {
+		rc := replicaChange{template: name, replicas: clust.templates[name].Replicas}
+
+		for n := 1; n <= rc.replicas; n++ {
+			if !numbers[name][n] {
+				rc.create = append(rc.create, instanceName(name, n))
+			}
+		}
+
+		var extra []int
+		for n := range numbers[name] {
+			if n > rc.replicas {
+				extra = append(extra, n)
+			}
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(extra)))
+		for _, n := range extra {
+			rc.destroy = append(rc.destroy, instanceName(name, n))
+		}
+
+		if len(rc.create) > 0 || len(rc.destroy) > 0 {
+			changes = append(changes, rc)
+		}
+	}
+	return changes
+}
+
+// instanceNumber returns the number an instance is named after, or zero
+// if its instance name is not a positive integer in canonical form.
+func instanceNumber(instance string) int {
+	n, err := strconv.Atoi(instance)
+	if err != nil || n < 1 || strconv.Itoa(n) != instance {
+		return 0
+	}
+	return n
+}
+
+// instanceName returns the name of the n-th instance of a template, e.g.
+// web@3.service for web@.service.
+func instanceName(template string, n int) string {
+	ext := path.Ext(template)
+	return fmt.Sprintf("%s%d%s", template[:len(template)-len(ext)], n, ext)
+}
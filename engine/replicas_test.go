@@ -0,0 +1,163 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+)
+
+func TestReplicaChanges(t *testing.T) {
+	template := func(replicas int) job.Unit {
+		return job.Unit{
+			Name:        "web@.service",
+			Unit:        newUnitWithXFleetValues(t, "Replicas=5"),
+			TargetState: job.JobStateInactive,
+			Replicas:    replicas,
+		}
+	}
+	instances := func(names ...string) []job.Unit {
+		var units []job.Unit
+		for _, name := range names {
+			units = append(units, job.Unit{Name: name, TargetState: job.JobStateLaunched})
+		}
+		return units
+	}
+
+	tests := []struct {
+		units   []job.Unit
+		changes []replicaChange
+	}{
+		// templates without Replicas are left alone
+		{
+			units: append(instances("api@1.service"), job.Unit{Name: "api@.service"}),
+		},
+		// missing instances are created
+		{
+			units: append(instances("web@2.service"), template(3)),
+			changes: []replicaChange{
+				{template: "web@.service", replicas: 3, create: []string{"web@1.service", "web@3.service"}},
+			},
+		},
+		// the highest-numbered instances are destroyed first
+		{
+			units: append(instances("web@1.service", "web@2.service", "web@10.service", "web@3.service"), template(1)),
+			changes: []replicaChange{
+				{template: "web@.service", replicas: 1, destroy: []string{"web@10.service", "web@3.service", "web@2.service"}},
+			},
+		},
+		// instances not named after a number are not counted
+		{
+			units: append(instances("web@1.service", "web@foo.service", "web@02.service"), template(2)),
+			changes: []replicaChange{
+				{template: "web@.service", replicas: 2, create: []string{"web@2.service"}},
+			},
+		},
+		// nothing to do
+		{
+			units: append(instances("web@1.service", "web@2.service"), template(2)),
+		},
+		{
+			units: append(instances("web@1.service"), template(0)),
+			changes: []replicaChange{
+				{template: "web@.service", replicas: 0, destroy: []string{"web@1.service"}},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		clust := newClusterState(tt.units, []job.ScheduledUnit{}, []machine.MachineState{})
+		changes := replicaChanges(clust)
+		if !reflect.DeepEqual(tt.changes, changes) {
+			t.Errorf("case %d: expected changes %v, got %v", i, tt.changes, changes)
+		}
+	}
+}
+
+func TestCalculateClusterTasksReplicas(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	clust := newClusterState(
+		[]job.Unit{
+			job.Unit{
+				Name:        "web@.service",
+				Unit:        newUnitWithXFleetValues(t, "Replicas=1"),
+				TargetState: job.JobStateInactive,
+				Replicas:    1,
+			},
+			job.Unit{
+				Name:        "web@1.service",
+				TargetState: job.JobStateLaunched,
+			},
+			job.Unit{
+				Name:        "web@2.service",
+				TargetState: job.JobStateLaunched,
+			},
+			job.Unit{
+				Name:        "db@.service",
+				Unit:        newUnitWithXFleetValues(t, "Replicas=1"),
+				TargetState: job.JobStateInactive,
+				Replicas:    1,
+			},
+		},
+		[]job.ScheduledUnit{
+			job.ScheduledUnit{
+				Name:            "web@1.service",
+				State:           &jsLaunched,
+				TargetMachineID: "XXX",
+			},
+			job.ScheduledUnit{
+				Name:            "web@2.service",
+				State:           &jsLaunched,
+				TargetMachineID: "XXX",
+			},
+		},
+		[]machine.MachineState{
+			machine.MachineState{ID: "XXX"},
+			machine.MachineState{ID: "YYY"},
+		},
+	)
+
+	want := []*task{
+		&task{
+			Type:    taskTypeCreateUnit,
+			Reason:  "Template(db@.service) scaled to 1 replicas",
+			JobName: "db@1.service",
+		},
+		&task{
+			Type:      taskTypeDestroyUnit,
+			Reason:    "Template(web@.service) scaled to 1 replicas",
+			JobName:   "web@2.service",
+			MachineID: "XXX",
+		},
+		&task{
+			Type:      taskTypeAttemptScheduleUnit,
+			Reason:    "target state launched and unit not scheduled",
+			JobName:   "db@1.service",
+			MachineID: "YYY",
+		},
+	}
+
+	r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
+	tasks := make([]*task, 0)
+	for tsk := range r.calculateClusterTasks(clust, make(chan struct{})) {
+		tasks = append(tasks, tsk)
+	}
+	if !reflect.DeepEqual(want, tasks) {
+		t.Errorf("task mismatch\nexpected %v\n got %v", want, tasks)
+	}
+}
@@ -16,30 +16,229 @@ package engine
 
 import (
 	"fmt"
+	"math/rand"
 	"sort"
+	"time"
 
 	"github.com/cea-hpc/fleet/agent"
 	"github.com/cea-hpc/fleet/job"
 )
 
+// Names of the scheduling strategies an engine can be configured with.
+const (
+	SchedulerLeastLoaded  = "least-loaded"
+	SchedulerBinPack      = "bin-pack"
+	SchedulerRandomSpread = "random-spread"
+	SchedulerRoundRobin   = "round-robin"
+
+	DefaultScheduler = SchedulerLeastLoaded
+)
+
 type decision struct {
 	machineID string
 }
 
 type Scheduler interface {
+	// Name returns the name of the scheduling strategy
+	Name() string
 	Decide(*clusterState, *job.Job) (*decision, error)
 	DecideReschedule(*clusterState, *job.Job) (*decision, error)
 }
 
+// NewScheduler returns the Scheduler implementing the strategy identified
+// by the given name, or an error if no such strategy exists.
+func NewScheduler(name string) (Scheduler, error) {
+	switch name {
+	case SchedulerLeastLoaded:
+		return &leastLoadedScheduler{}, nil
+	case SchedulerBinPack:
+		return &binPackScheduler{}, nil
+	case SchedulerRandomSpread:
+		return &randomSpreadScheduler{
+			rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		}, nil
+	case SchedulerRoundRobin:
+		return &roundRobinScheduler{}, nil
+	}
+	return nil, fmt.Errorf("unknown scheduler %q", name)
+}
+
+// leastLoadedScheduler places a job on the agent carrying the lowest
+// weighted load, so that units are spread evenly across the cluster.
 type leastLoadedScheduler struct{}
 
+func (lls *leastLoadedScheduler) Name() string {
+	return SchedulerLeastLoaded
+}
+
 func (lls *leastLoadedScheduler) Decide(clust *clusterState, j *job.Job) (*decision, error) {
-	agents := lls.sortedAgents(clust)
+	return decideAmong(lls.sortedAgents(clust), j)
+}
+
+// DecideReschedule() decides scheduling in a much simpler way than
+// Decide(). It just tries to find out another free machine to be scheduled,
+// except for the current target machine. It does not have to run
+// as.AbleToRun(), because its job action must have been already decided
+// before getting into the function.
+func (lls *leastLoadedScheduler) DecideReschedule(clust *clusterState, j *job.Job) (*decision, error) {
+	return decideRescheduleAmong(lls.sortedAgents(clust), j)
+}
+
+// sortedAgents returns a list of AgentState objects sorted ascending
+// by the number of scheduled units
+func (lls *leastLoadedScheduler) sortedAgents(clust *clusterState) []*agent.AgentState {
+	agents := clust.agents()
+
+	sas := make(sortableAgentStates, 0)
+	for _, as := range agents {
+		sas = append(sas, as)
+	}
+	sort.Sort(sas)
+
+	return []*agent.AgentState(sas)
+}
+
+// binPackScheduler places a job on the most loaded agent still able to
+// run it, so that as many agents as possible are kept free of units.
+type binPackScheduler struct{}
+
+func (bps *binPackScheduler) Name() string {
+	return SchedulerBinPack
+}
+
+func (bps *binPackScheduler) Decide(clust *clusterState, j *job.Job) (*decision, error) {
+	return decideAmong(bps.sortedAgents(clust), j)
+}
+
+func (bps *binPackScheduler) DecideReschedule(clust *clusterState, j *job.Job) (*decision, error) {
+	return decideRescheduleAmong(bps.sortedAgents(clust), j)
+}
+
+// sortedAgents returns a list of AgentState objects sorted descending
+// by weighted load, falling back to ascending machine ID
+func (bps *binPackScheduler) sortedAgents(clust *clusterState) []*agent.AgentState {
+	agents := make([]*agent.AgentState, 0)
+	for _, as := range clust.agents() {
+		agents = append(agents, as)
+	}
+	sort.Sort(byLoadDescending(agents))
+
+	return agents
+}
+
+// randomSpreadScheduler places a job on an agent picked at random among
+// all of those able to run it.
+type randomSpreadScheduler struct {
+	rand *rand.Rand
+}
+
+func (rss *randomSpreadScheduler) Name() string {
+	return SchedulerRandomSpread
+}
+
+func (rss *randomSpreadScheduler) Decide(clust *clusterState, j *job.Job) (*decision, error) {
+	agents := sortedAgentsByID(clust)
+
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("zero agents available")
+	}
+
+	var able []*agent.AgentState
+	for _, as := range agents {
+		if act, _ := as.AbleToRun(j); act == job.JobActionUnschedule {
+			continue
+		}
+		able = append(able, as)
+	}
+
+	if len(able) == 0 {
+		return nil, fmt.Errorf("no agents able to run job")
+	}
+	able = bestRanked(agents, able, j)
+
+	dec := decision{
+		machineID: able[rss.rand.Intn(len(able))].MState.ID,
+	}
+
+	return &dec, nil
+}
+
+func (rss *randomSpreadScheduler) DecideReschedule(clust *clusterState, j *job.Job) (*decision, error) {
+	agents := sortedAgentsByID(clust)
 
 	if len(agents) == 0 {
 		return nil, fmt.Errorf("zero agents available")
 	}
 
+	var others []*agent.AgentState
+	for _, as := range agents {
+		if as.MState.ID == j.TargetMachineID {
+			continue
+		}
+		others = append(others, as)
+	}
+
+	if len(others) == 0 {
+		return nil, fmt.Errorf("no agents able to run job")
+	}
+	others = bestRanked(agents, others, j)
+
+	dec := decision{
+		machineID: others[rss.rand.Intn(len(others))].MState.ID,
+	}
+
+	return &dec, nil
+}
+
+// roundRobinScheduler places each job on the agent following, in machine
+// ID order, the one that received the previous job.
+type roundRobinScheduler struct {
+	// last is the ID of the machine chosen by the previous decision
+	last string
+}
+
+func (rrs *roundRobinScheduler) Name() string {
+	return SchedulerRoundRobin
+}
+
+func (rrs *roundRobinScheduler) Decide(clust *clusterState, j *job.Job) (*decision, error) {
+	dec, err := decideAmong(rrs.sortedAgents(clust), j)
+	if err == nil {
+		rrs.last = dec.machineID
+	}
+	return dec, err
+}
+
+func (rrs *roundRobinScheduler) DecideReschedule(clust *clusterState, j *job.Job) (*decision, error) {
+	dec, err := decideRescheduleAmong(rrs.sortedAgents(clust), j)
+	if err == nil {
+		rrs.last = dec.machineID
+	}
+	return dec, err
+}
+
+// sortedAgents returns a list of AgentState objects sorted by machine ID,
+// rotated so that it starts right after the last chosen machine
+func (rrs *roundRobinScheduler) sortedAgents(clust *clusterState) []*agent.AgentState {
+	agents := sortedAgentsByID(clust)
+	next := sort.Search(len(agents), func(i int) bool {
+		return agents[i].MState.ID > rrs.last
+	})
+
+	rotated := make([]*agent.AgentState, 0, len(agents))
+	rotated = append(rotated, agents[next:]...)
+	rotated = append(rotated, agents[:next]...)
+	return rotated
+}
+
+// decideAmong picks the first of the given agents able to run the job,
+// once ranked by the job's placement preferences.
+func decideAmong(agents []*agent.AgentState, j *job.Job) (*decision, error) {
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("zero agents available")
+	}
+	agents = rankCandidates(agents, j)
+
 	var target *agent.AgentState
 	for _, as := range agents {
 		if act, _ := as.AbleToRun(j); act == job.JobActionUnschedule {
@@ -62,17 +261,14 @@ func (lls *leastLoadedScheduler) Decide(clust *clusterState, j *job.Job) (*decis
 	return &dec, nil
 }
 
-// DecideReschedule() decides scheduling in a much simpler way than
-// Decide(). It just tries to find out another free machine to be scheduled,
-// except for the current target machine. It does not have to run
-// as.AbleToRun(), because its job action must have been already decided
-// before getting into the function.
-func (lls *leastLoadedScheduler) DecideReschedule(clust *clusterState, j *job.Job) (*decision, error) {
-	agents := lls.sortedAgents(clust)
-
+// decideRescheduleAmong picks the first of the given agents other than the
+// current target machine of the job, without checking as.AbleToRun(). Only
+// agents with room for the job under their limits are considered.
+func decideRescheduleAmong(agents []*agent.AgentState, j *job.Job) (*decision, error) {
 	if len(agents) == 0 {
 		return nil, fmt.Errorf("zero agents available")
 	}
+	agents = rankCandidates(agents, j)
 
 	found := false
 	var target *agent.AgentState
@@ -80,6 +276,9 @@ func (lls *leastLoadedScheduler) DecideReschedule(clust *clusterState, j *job.Jo
 		if as.MState.ID == j.TargetMachineID {
 			continue
 		}
+		if ok, _ := as.HasCapacity(j); !ok {
+			continue
+		}
 
 		as := as
 		target = as
@@ -98,18 +297,29 @@ func (lls *leastLoadedScheduler) DecideReschedule(clust *clusterState, j *job.Jo
 	return &dec, nil
 }
 
-// sortedAgents returns a list of AgentState objects sorted ascending
-// by the number of scheduled units
-func (lls *leastLoadedScheduler) sortedAgents(clust *clusterState) []*agent.AgentState {
-	agents := clust.agents()
-
-	sas := make(sortableAgentStates, 0)
-	for _, as := range agents {
-		sas = append(sas, as)
+// sortedAgentsByID returns a list of AgentState objects sorted ascending
+// by machine ID
+func sortedAgentsByID(clust *clusterState) []*agent.AgentState {
+	agents := make([]*agent.AgentState, 0)
+	for _, as := range clust.agents() {
+		agents = append(agents, as)
 	}
-	sort.Sort(sas)
+	sort.Sort(byMachineID(agents))
+	return agents
+}
 
-	return []*agent.AgentState(sas)
+// agentLoad returns the weighted load of the units scheduled to an agent.
+// Units without an explicit weight count as one.
+func agentLoad(as *agent.AgentState) uint16 {
+	var load uint16 = 0
+	for _, unit := range as.Units {
+		if unit.Weight == 0 {
+			load += 1
+		} else {
+			load += unit.Weight
+		}
+	}
+	return load
 }
 
 type sortableAgentStates []*agent.AgentState
@@ -118,21 +328,24 @@ func (sas sortableAgentStates) Len() int      { return len(sas) }
 func (sas sortableAgentStates) Swap(i, j int) { sas[i], sas[j] = sas[j], sas[i] }
 
 func (sas sortableAgentStates) Less(i, j int) bool {
-	var iLoad uint16 = 0
-	var jLoad uint16 = 0
-	for _, unit := range sas[i].Units {
-		if unit.Weight == 0 {
-			iLoad += 1
-		} else {
-			iLoad += unit.Weight
-		}
-	}
-	for _, unit := range sas[j].Units {
-		if unit.Weight == 0 {
-			jLoad += 1
-		} else {
-			jLoad += unit.Weight
-		}
-	}
+	iLoad := agentLoad(sas[i])
+	jLoad := agentLoad(sas[j])
 	return iLoad < jLoad || (iLoad == jLoad && sas[i].MState.ID < sas[j].MState.ID)
 }
+
+type byLoadDescending []*agent.AgentState
+
+func (lds byLoadDescending) Len() int      { return len(lds) }
+func (lds byLoadDescending) Swap(i, j int) { lds[i], lds[j] = lds[j], lds[i] }
+
+func (lds byLoadDescending) Less(i, j int) bool {
+	iLoad := agentLoad(lds[i])
+	jLoad := agentLoad(lds[j])
+	return iLoad > jLoad || (iLoad == jLoad && lds[i].MState.ID < lds[j].MState.ID)
+}
+
+type byMachineID []*agent.AgentState
+
+func (ids byMachineID) Len() int           { return len(ids) }
+func (ids byMachineID) Swap(i, j int)      { ids[i], ids[j] = ids[j], ids[i] }
+func (ids byMachineID) Less(i, j int) bool { return ids[i].MState.ID < ids[j].MState.ID }
@@ -15,6 +15,8 @@
 package engine
 
 import (
+	"fmt"
+	"math/rand"
 	"reflect"
 	"sort"
 	"testing"
@@ -22,6 +24,7 @@ import (
 	"github.com/cea-hpc/fleet/agent"
 	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/resource"
 )
 
 func TestSchedulerDecisions(t *testing.T) {
@@ -65,6 +68,514 @@ func TestSchedulerDecisions(t *testing.T) {
 	}
 }
 
+// newLoadedClusterState returns a clusterState made of three machines
+// carrying respectively two, one and zero launched units.
+func newLoadedClusterState() *clusterState {
+	units := []job.Unit{
+		job.Unit{Name: "1.service", TargetState: job.JobStateLaunched},
+		job.Unit{Name: "2.service", TargetState: job.JobStateLaunched},
+		job.Unit{Name: "3.service", TargetState: job.JobStateLaunched},
+	}
+	sUnits := []job.ScheduledUnit{
+		job.ScheduledUnit{Name: "1.service", TargetMachineID: "AAA"},
+		job.ScheduledUnit{Name: "2.service", TargetMachineID: "AAA"},
+		job.ScheduledUnit{Name: "3.service", TargetMachineID: "BBB"},
+	}
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "AAA", Metadata: map[string]string{"disk": "hdd"}},
+		machine.MachineState{ID: "BBB", Metadata: map[string]string{"disk": "ssd"}},
+		machine.MachineState{ID: "CCC", Metadata: map[string]string{"disk": "ssd"}},
+	}
+	return newClusterState(units, sUnits, machines)
+}
+
+func TestNewScheduler(t *testing.T) {
+	for _, name := range []string{
+		SchedulerLeastLoaded,
+		SchedulerBinPack,
+		SchedulerRandomSpread,
+		SchedulerRoundRobin,
+	} {
+		sched, err := NewScheduler(name)
+		if err != nil {
+			t.Errorf("scheduler %q: unexpected error: %v", name, err)
+			continue
+		}
+		if sched.Name() != name {
+			t.Errorf("scheduler %q: unexpected name %q", name, sched.Name())
+		}
+	}
+
+	if _, err := NewScheduler("bogus"); err == nil {
+		t.Errorf("expected error for unknown scheduler")
+	}
+}
+
+func TestStrategyDecisions(t *testing.T) {
+	tests := []struct {
+		sched Scheduler
+		clust *clusterState
+		job   *job.Job
+		dec   *decision
+	}{
+		// least-loaded picks the machine without units
+		{
+			sched: &leastLoadedScheduler{},
+			clust: newLoadedClusterState(),
+			job:   &job.Job{Name: "foo.service"},
+			dec:   &decision{machineID: "CCC"},
+		},
+
+		// bin-pack fails without machines
+		{
+			sched: &binPackScheduler{},
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, []machine.MachineState{}),
+			job:   &job.Job{Name: "foo.service"},
+			dec:   nil,
+		},
+
+		// bin-pack picks the first machine when loads are equal
+		{
+			sched: &binPackScheduler{},
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, []machine.MachineState{machine.MachineState{ID: "YYY"}, machine.MachineState{ID: "XXX"}}),
+			job:   &job.Job{Name: "foo.service"},
+			dec:   &decision{machineID: "XXX"},
+		},
+
+		// bin-pack picks the most loaded machine
+		{
+			sched: &binPackScheduler{},
+			clust: newLoadedClusterState(),
+			job:   &job.Job{Name: "foo.service"},
+			dec:   &decision{machineID: "AAA"},
+		},
+
+		// bin-pack skips the most loaded machine if unable to run the job
+		{
+			sched: &binPackScheduler{},
+			clust: newLoadedClusterState(),
+			job:   &job.Job{Name: "foo.service", Unit: newUnitWithMetadata(t, "disk=ssd")},
+			dec:   &decision{machineID: "BBB"},
+		},
+
+		// round-robin fails without machines
+		{
+			sched: &roundRobinScheduler{},
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, []machine.MachineState{}),
+			job:   &job.Job{Name: "foo.service"},
+			dec:   nil,
+		},
+
+		// round-robin starts from the first machine, regardless of load
+		{
+			sched: &roundRobinScheduler{},
+			clust: newLoadedClusterState(),
+			job:   &job.Job{Name: "foo.service"},
+			dec:   &decision{machineID: "AAA"},
+		},
+
+		// round-robin continues after the last chosen machine
+		{
+			sched: &roundRobinScheduler{last: "AAA"},
+			clust: newLoadedClusterState(),
+			job:   &job.Job{Name: "foo.service"},
+			dec:   &decision{machineID: "BBB"},
+		},
+
+		// round-robin wraps around after the last machine
+		{
+			sched: &roundRobinScheduler{last: "CCC"},
+			clust: newLoadedClusterState(),
+			job:   &job.Job{Name: "foo.service"},
+			dec:   &decision{machineID: "AAA"},
+		},
+
+		// round-robin skips machines unable to run the job
+		{
+			sched: &roundRobinScheduler{last: "BBB"},
+			clust: newLoadedClusterState(),
+			job:   &job.Job{Name: "foo.service", Unit: newUnitWithMetadata(t, "disk=hdd")},
+			dec:   &decision{machineID: "AAA"},
+		},
+
+		// random-spread fails without machines
+		{
+			sched: &randomSpreadScheduler{rand: rand.New(rand.NewSource(1))},
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, []machine.MachineState{}),
+			job:   &job.Job{Name: "foo.service"},
+			dec:   nil,
+		},
+
+		// random-spread picks the only machine able to run the job
+		{
+			sched: &randomSpreadScheduler{rand: rand.New(rand.NewSource(1))},
+			clust: newLoadedClusterState(),
+			job:   &job.Job{Name: "foo.service", Unit: newUnitWithMetadata(t, "disk=hdd")},
+			dec:   &decision{machineID: "AAA"},
+		},
+
+		// random-spread fails if no machine is able to run the job
+		{
+			sched: &randomSpreadScheduler{rand: rand.New(rand.NewSource(1))},
+			clust: newLoadedClusterState(),
+			job:   &job.Job{Name: "foo.service", Unit: newUnitWithMetadata(t, "disk=nvme")},
+			dec:   nil,
+		},
+	}
+
+	for i, tt := range tests {
+		dec, err := tt.sched.Decide(tt.clust, tt.job)
+
+		if err != nil && tt.dec != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		} else if err == nil && tt.dec == nil {
+			t.Errorf("case %d: expected error", i)
+			continue
+		}
+
+		if !reflect.DeepEqual(tt.dec, dec) {
+			t.Errorf("case %d: expected decision %#v, got %#v", i, tt.dec, dec)
+		}
+	}
+}
+
+func TestStrategyRescheduleDecisions(t *testing.T) {
+	tests := []struct {
+		sched Scheduler
+		job   *job.Job
+		dec   *decision
+	}{
+		{
+			sched: &leastLoadedScheduler{},
+			job:   &job.Job{Name: "foo.service", TargetMachineID: "CCC"},
+			dec:   &decision{machineID: "BBB"},
+		},
+		{
+			sched: &binPackScheduler{},
+			job:   &job.Job{Name: "foo.service", TargetMachineID: "AAA"},
+			dec:   &decision{machineID: "BBB"},
+		},
+		{
+			sched: &roundRobinScheduler{last: "AAA"},
+			job:   &job.Job{Name: "foo.service", TargetMachineID: "BBB"},
+			dec:   &decision{machineID: "CCC"},
+		},
+	}
+
+	for i, tt := range tests {
+		dec, err := tt.sched.DecideReschedule(newLoadedClusterState(), tt.job)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(tt.dec, dec) {
+			t.Errorf("case %d: expected decision %#v, got %#v", i, tt.dec, dec)
+		}
+	}
+}
+
+func TestRoundRobinSchedulerRotation(t *testing.T) {
+	clust := newLoadedClusterState()
+	sched := &roundRobinScheduler{}
+
+	want := []string{"AAA", "BBB", "CCC", "AAA", "BBB"}
+	for i, w := range want {
+		dec, err := sched.Decide(clust, &job.Job{Name: "foo.service"})
+		if err != nil {
+			t.Fatalf("decision %d: unexpected error: %v", i, err)
+		}
+		if dec.machineID != w {
+			t.Errorf("decision %d: expected machine %s, got %s", i, w, dec.machineID)
+		}
+	}
+}
+
+func TestRandomSpreadSchedulerSpread(t *testing.T) {
+	clust := newLoadedClusterState()
+	sched := &randomSpreadScheduler{rand: rand.New(rand.NewSource(1))}
+	j := &job.Job{Name: "foo.service", Unit: newUnitWithMetadata(t, "disk=ssd")}
+
+	picked := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		dec, err := sched.Decide(clust, j)
+		if err != nil {
+			t.Fatalf("decision %d: unexpected error: %v", i, err)
+		}
+		picked[dec.machineID]++
+	}
+
+	if picked["AAA"] != 0 {
+		t.Errorf("machine AAA picked %d times despite being unable to run the job", picked["AAA"])
+	}
+	for _, id := range []string{"BBB", "CCC"} {
+		if picked[id] == 0 {
+			t.Errorf("machine %s never picked", id)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		dec, err := sched.DecideReschedule(clust, &job.Job{Name: "foo.service", TargetMachineID: "BBB"})
+		if err != nil {
+			t.Fatalf("reschedule decision %d: unexpected error: %v", i, err)
+		}
+		if dec.machineID == "BBB" {
+			t.Fatalf("reschedule decision %d: picked current target machine", i)
+		}
+	}
+}
+
+func TestResourceAwareDecisions(t *testing.T) {
+	newClust := func() *clusterState {
+		units := []job.Unit{
+			job.Unit{Name: "1.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "ReserveMemory=1024")},
+			job.Unit{Name: "2.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "ReserveMemory=1024")},
+			job.Unit{Name: "3.service", TargetState: job.JobStateLaunched},
+		}
+		sUnits := []job.ScheduledUnit{
+			job.ScheduledUnit{Name: "1.service", TargetMachineID: "AAA"},
+			job.ScheduledUnit{Name: "2.service", TargetMachineID: "AAA"},
+			job.ScheduledUnit{Name: "3.service", TargetMachineID: "BBB"},
+		}
+		machines := []machine.MachineState{
+			machine.MachineState{ID: "AAA", TotalResources: resource.ResourceTuple{Cores: 800, Memory: 2048}},
+			machine.MachineState{ID: "BBB", TotalResources: resource.ResourceTuple{Cores: 800, Memory: 4096}},
+			machine.MachineState{ID: "CCC"},
+		}
+		return newClusterState(units, sUnits, machines)
+	}
+
+	j := &job.Job{Name: "foo.service", Unit: newUnitWithXFleetValues(t, "ReserveMemory=512")}
+	for _, sched := range []Scheduler{&leastLoadedScheduler{}, &binPackScheduler{}} {
+		dec, err := sched.Decide(newClust(), j)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", sched.Name(), err)
+			continue
+		}
+		if dec.machineID != "BBB" {
+			t.Errorf("%s: expected machine BBB, got %s", sched.Name(), dec.machineID)
+		}
+	}
+
+	j = &job.Job{Name: "foo.service", Unit: newUnitWithXFleetValues(t, "ReserveMemory=8192")}
+	if _, err := (&leastLoadedScheduler{}).Decide(newClust(), j); err == nil {
+		t.Errorf("expected error when no machine has enough free memory")
+	}
+}
+
+func TestCapacityLimitDecisions(t *testing.T) {
+	newClust := func() *clusterState {
+		units := []job.Unit{
+			job.Unit{Name: "1.service", TargetState: job.JobStateLaunched},
+			job.Unit{Name: "2.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Weight=50")},
+			job.Unit{Name: "3.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Weight=80")},
+		}
+		sUnits := []job.ScheduledUnit{
+			job.ScheduledUnit{Name: "1.service", TargetMachineID: "AAA"},
+			job.ScheduledUnit{Name: "2.service", TargetMachineID: "BBB"},
+			job.ScheduledUnit{Name: "3.service", TargetMachineID: "CCC"},
+		}
+		machines := []machine.MachineState{
+			machine.MachineState{ID: "AAA", MaxUnits: 1},
+			machine.MachineState{ID: "BBB", MaxWeight: 100},
+			machine.MachineState{ID: "CCC"},
+		}
+		return newClusterState(units, sUnits, machines)
+	}
+
+	tests := []struct {
+		job       *job.Job
+		machineID string
+	}{
+		// AAA is the least loaded but full
+		{&job.Job{Name: "foo.service"}, "BBB"},
+		// BBB has not enough weight left
+		{&job.Job{Name: "foo.service", Unit: newUnitWithXFleetValues(t, "Weight=51")}, "CCC"},
+		// nowhere to go
+		{&job.Job{Name: "foo.service", Unit: newUnitWithXFleetValues(t, "Weight=51\nConflicts=3.service")}, ""},
+	}
+	for i, tt := range tests {
+		dec, err := (&leastLoadedScheduler{}).Decide(newClust(), tt.job)
+		if tt.machineID == "" {
+			if err == nil {
+				t.Errorf("case %d: expected error, got decision %#v", i, dec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if dec.machineID != tt.machineID {
+			t.Errorf("case %d: expected machine %s, got %s", i, tt.machineID, dec.machineID)
+		}
+	}
+
+	// rescheduling never picks a full machine either
+	j := &job.Job{Name: "3.service", TargetMachineID: "CCC", Unit: newUnitWithXFleetValues(t, "Weight=80")}
+	if dec, err := (&leastLoadedScheduler{}).DecideReschedule(newClust(), j); err == nil {
+		t.Errorf("expected error when every other machine is full, got decision %#v", dec)
+	}
+}
+
+func TestSpreadDecisions(t *testing.T) {
+	newClust := func(webTargets ...string) *clusterState {
+		units := []job.Unit{
+			job.Unit{Name: "other.service", TargetState: job.JobStateLaunched},
+		}
+		sUnits := []job.ScheduledUnit{
+			job.ScheduledUnit{Name: "other.service", TargetMachineID: "CCC"},
+		}
+		for i, target := range webTargets {
+			name := fmt.Sprintf("web@%d.service", i+1)
+			units = append(units, job.Unit{Name: name, TargetState: job.JobStateLaunched})
+			sUnits = append(sUnits, job.ScheduledUnit{Name: name, TargetMachineID: target})
+		}
+		machines := []machine.MachineState{
+			machine.MachineState{ID: "AAA", Metadata: map[string]string{"rack": "r1"}},
+			machine.MachineState{ID: "BBB", Metadata: map[string]string{"rack": "r1"}},
+			machine.MachineState{ID: "CCC", Metadata: map[string]string{"rack": "r2"}},
+			machine.MachineState{ID: "DDD"},
+		}
+		return newClusterState(units, sUnits, machines)
+	}
+
+	rss := &randomSpreadScheduler{rand: rand.New(rand.NewSource(1))}
+	tests := []struct {
+		webTargets []string
+		dec        *decision
+		scheds     []Scheduler
+	}{
+		// the rack without any instance wins over less loaded machines
+		{
+			webTargets: []string{"AAA", "BBB"},
+			dec:        &decision{machineID: "CCC"},
+			scheds:     []Scheduler{&leastLoadedScheduler{}, &binPackScheduler{}, &roundRobinScheduler{}, rss},
+		},
+		// equally populated racks fall back to the strategy's order
+		{
+			webTargets: []string{"AAA", "CCC"},
+			dec:        &decision{machineID: "BBB"},
+			scheds:     []Scheduler{&leastLoadedScheduler{}},
+		},
+		{
+			webTargets: []string{"AAA", "CCC"},
+			dec:        &decision{machineID: "CCC"},
+			scheds:     []Scheduler{&binPackScheduler{}},
+		},
+	}
+
+	j := &job.Job{Name: "web@9.service", Unit: newUnitWithXFleetValues(t, "SpreadBy=rack")}
+	for i, tt := range tests {
+		for _, sched := range tt.scheds {
+			dec, err := sched.Decide(newClust(tt.webTargets...), j)
+			if err != nil {
+				t.Errorf("case %d: %s: unexpected error: %v", i, sched.Name(), err)
+				continue
+			}
+			if !reflect.DeepEqual(tt.dec, dec) {
+				t.Errorf("case %d: %s: expected decision %#v, got %#v", i, sched.Name(), tt.dec, dec)
+			}
+		}
+	}
+}
+
+func TestSpreadDecisionsFullMachine(t *testing.T) {
+	units := []job.Unit{
+		job.Unit{Name: "web@1.service", TargetState: job.JobStateLaunched},
+		job.Unit{Name: "other.service", TargetState: job.JobStateLaunched},
+	}
+	sUnits := []job.ScheduledUnit{
+		job.ScheduledUnit{Name: "web@1.service", TargetMachineID: "AAA"},
+		job.ScheduledUnit{Name: "other.service", TargetMachineID: "CCC"},
+	}
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "AAA", MaxUnits: 1, Metadata: map[string]string{"rack": "r1"}},
+		machine.MachineState{ID: "BBB", Metadata: map[string]string{"rack": "r1"}},
+		machine.MachineState{ID: "CCC", Metadata: map[string]string{"rack": "r2"}},
+	}
+
+	// the instance on the full AAA still counts for rack r1, so the less
+	// loaded BBB is not picked
+	j := &job.Job{Name: "web@2.service", Unit: newUnitWithXFleetValues(t, "SpreadBy=rack")}
+	for _, sched := range []Scheduler{&leastLoadedScheduler{}, &binPackScheduler{}, &roundRobinScheduler{}} {
+		dec, err := sched.Decide(newClusterState(units, sUnits, machines), j)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", sched.Name(), err)
+			continue
+		}
+		if dec.machineID != "CCC" {
+			t.Errorf("%s: expected decision for CCC, got %s", sched.Name(), dec.machineID)
+		}
+	}
+}
+
+func TestPreferenceDecisions(t *testing.T) {
+	tests := []struct {
+		sched  Scheduler
+		values string
+		dec    *decision
+	}{
+		// preferred metadata wins over load
+		{
+			sched:  &leastLoadedScheduler{},
+			values: "PreferMachineMetadata=disk=hdd",
+			dec:    &decision{machineID: "AAA"},
+		},
+		{
+			sched:  &randomSpreadScheduler{rand: rand.New(rand.NewSource(1))},
+			values: "PreferMachineMetadata=disk=hdd",
+			dec:    &decision{machineID: "AAA"},
+		},
+
+		// preferred anti-affinity steers bin-pack away from the fullest machine
+		{
+			sched:  &binPackScheduler{},
+			values: "PreferNotColocatedWith=1.service",
+			dec:    &decision{machineID: "BBB"},
+		},
+
+		// heavier preferences win
+		{
+			sched:  &binPackScheduler{},
+			values: "PreferMachineMetadata=disk=hdd\nPreferNotColocatedWith=1.service weight=5",
+			dec:    &decision{machineID: "BBB"},
+		},
+		{
+			sched:  &binPackScheduler{},
+			values: "PreferMachineMetadata=disk=hdd weight=10\nPreferNotColocatedWith=1.service weight=5",
+			dec:    &decision{machineID: "AAA"},
+		},
+
+		// fall back to the strategy when no machine is preferred
+		{
+			sched:  &leastLoadedScheduler{},
+			values: "PreferMachineMetadata=disk=nvme",
+			dec:    &decision{machineID: "CCC"},
+		},
+
+		// fall back to non-preferred machines able to run the job
+		{
+			sched:  &leastLoadedScheduler{},
+			values: "MachineMetadata=disk=ssd\nPreferMachineMetadata=disk=hdd",
+			dec:    &decision{machineID: "CCC"},
+		},
+	}
+
+	for i, tt := range tests {
+		j := &job.Job{Name: "foo.service", Unit: newUnitWithXFleetValues(t, tt.values)}
+		dec, err := tt.sched.Decide(newLoadedClusterState(), j)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.dec, dec) {
+			t.Errorf("case %d: expected decision %#v, got %#v", i, tt.dec, dec)
+		}
+	}
+}
+
 func TestAgentStateSorting(t *testing.T) {
 	tests := []struct {
 		in  []*agent.AgentState
@@ -0,0 +1,163 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+// maxSimulationRounds bounds the number of reconciliations run by Simulate,
+// should the cluster never settle.
+const maxSimulationRounds = 10
+
+// Placement is the outcome of a simulated reconciliation for one unit.
+type Placement struct {
+	Name string
+	// MachineID is the machine the unit ends up scheduled to, empty if
+	// the unit could not be placed.
+	MachineID string
+	// PreviousMachineID is the machine the unit was scheduled to before
+	// the simulation, empty if it was not scheduled.
+	PreviousMachineID string
+	// Reason tells why the unit could not be placed.
+	Reason string
+}
+
+// Simulate runs the reconciliation of an engine, using the given scheduler,
+// against the cluster held by the given FakeRegistry, to which decisions are
+// applied. Reconciliation is repeated until the units and their schedule no
+// longer change, or for maxSimulationRounds rounds. The resulting Placement
+// of every non-global unit with a loaded or launched target state is
+// returned, sorted by name.
+func Simulate(sched Scheduler, reg *registry.FakeRegistry) ([]Placement, error) {
+	e := &Engine{
+		rec:       NewReconciler(sched, nil, 0),
+		registry:  reg,
+		cRegistry: registry.NewFakeClusterRegistry(nil, engineVersion),
+	}
+
+	previous, err := loadClusterState(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	stop := make(chan struct{})
+	for round := 0; round < maxSimulationRounds; round++ {
+		before, err := readSimulationState(reg)
+		if err != nil {
+			return nil, err
+		}
+		e.rec.Reconcile(e, stop)
+		after, err := readSimulationState(reg)
+		if err != nil {
+			return nil, err
+		}
+		if reflect.DeepEqual(before, after) {
+			break
+		}
+	}
+
+	clust, err := loadClusterState(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	var placements []Placement
+	for name, j := range clust.jobs {
+		if j.TargetState == job.JobStateInactive {
+			continue
+		}
+		p := Placement{
+			Name:      name,
+			MachineID: j.TargetMachineID,
+		}
+		if pj, ok := previous.jobs[name]; ok {
+			p.PreviousMachineID = pj.TargetMachineID
+		}
+		if !j.Scheduled() {
+			p.Reason = unplacedReason(sched, clust, j)
+		}
+		placements = append(placements, p)
+	}
+	sort.Sort(byPlacementName(placements))
+
+	return placements, nil
+}
+
+// simulationState holds the units of the simulated cluster along with their
+// schedule, to tell whether a simulated reconciliation changed anything.
+type simulationState struct {
+	units  []job.Unit
+	sUnits []job.ScheduledUnit
+}
+
+func readSimulationState(reg registry.Registry) (*simulationState, error) {
+	units, err := reg.Units()
+	if err != nil {
+		return nil, err
+	}
+	sUnits, err := reg.Schedule()
+	if err != nil {
+		return nil, err
+	}
+	return &simulationState{units: units, sUnits: sUnits}, nil
+}
+
+// unplacedReason summarizes why no machine of the cluster is able to run
+// the unscheduled Job, grouping machines refusing it for the same reason.
+func unplacedReason(sched Scheduler, clust *clusterState, j *job.Job) string {
+	if gang := j.Gang(); gang != "" {
+		if _, err := decideGang(sched, clust, gang); err != nil {
+			return fmt.Sprintf("Gang(%s) cannot be placed: %v", gang, err)
+		}
+	}
+
+	agents := sortedAgentsByID(clust)
+	if len(agents) == 0 {
+		return "no machines in the cluster"
+	}
+
+	var reasons []string
+	counts := make(map[string]int)
+	for _, as := range agents {
+		if act, reason := as.AbleToRun(j); act == job.JobActionUnschedule {
+			if counts[reason] == 0 {
+				reasons = append(reasons, reason)
+			}
+			counts[reason]++
+		}
+	}
+	if len(reasons) == 0 {
+		return fmt.Sprintf("not placed after %d reconciliations", maxSimulationRounds)
+	}
+
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%s (%d of %d machines)", reason, counts[reason], len(agents))
+	}
+	return strings.Join(parts, "; ")
+}
+
+type byPlacementName []Placement
+
+func (pn byPlacementName) Len() int           { return len(pn) }
+func (pn byPlacementName) Swap(i, j int)      { pn[i], pn[j] = pn[j], pn[i] }
+func (pn byPlacementName) Less(i, j int) bool { return pn[i].Name < pn[j].Name }
@@ -0,0 +1,109 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+// simulationRegistry returns a FakeRegistry holding the given units,
+// schedule and machines.
+func simulationRegistry(units []job.Unit, sUnits []job.ScheduledUnit, machines []machine.MachineState) *registry.FakeRegistry {
+	targets := make(map[string]string, len(sUnits))
+	for _, su := range sUnits {
+		targets[su.Name] = su.TargetMachineID
+	}
+	jobs := make([]job.Job, len(units))
+	for i, u := range units {
+		jobs[i] = job.Job{
+			Name:            u.Name,
+			Unit:            u.Unit,
+			TargetState:     u.TargetState,
+			TargetMachineID: targets[u.Name],
+		}
+	}
+
+	reg := registry.NewFakeRegistry()
+	reg.SetJobs(jobs)
+	reg.SetMachines(machines)
+	return reg
+}
+
+func simulate(t *testing.T, units []job.Unit, sUnits []job.ScheduledUnit, machines []machine.MachineState) []Placement {
+	placements, err := Simulate(&leastLoadedScheduler{}, simulationRegistry(units, sUnits, machines))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return placements
+}
+
+func TestSimulate(t *testing.T) {
+	units := []job.Unit{
+		job.Unit{Name: "running.service", TargetState: job.JobStateLaunched},
+		job.Unit{Name: "orphan.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "MachineMetadata=rack=1")},
+		job.Unit{Name: "stopped.service", TargetState: job.JobStateInactive},
+		job.Unit{Name: "new.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Conflicts=running.service")},
+		job.Unit{Name: "web@.service", TargetState: job.JobStateInactive, Unit: newUnitWithXFleetValues(t, "Replicas=2\nMachineMetadata=rack=2"), Replicas: 2},
+		job.Unit{Name: "ssd.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "MachineMetadata=disk=ssd")},
+		job.Unit{Name: "global.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Global=true")},
+	}
+	sUnits := []job.ScheduledUnit{
+		job.ScheduledUnit{Name: "running.service", TargetMachineID: "AAA"},
+		job.ScheduledUnit{Name: "orphan.service", TargetMachineID: "ZZZ"},
+	}
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "AAA", Metadata: map[string]string{"rack": "1"}},
+		machine.MachineState{ID: "BBB", Metadata: map[string]string{"rack": "2"}},
+	}
+
+	got := simulate(t, units, sUnits, machines)
+	want := []Placement{
+		{Name: "new.service", MachineID: "BBB"},
+		{Name: "orphan.service", MachineID: "AAA", PreviousMachineID: "ZZZ"},
+		{Name: "running.service", MachineID: "AAA", PreviousMachineID: "AAA"},
+		{Name: "ssd.service", Reason: "local Machine metadata insufficient (2 of 2 machines)"},
+		{Name: "web@1.service", MachineID: "BBB"},
+		{Name: "web@2.service", MachineID: "BBB"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected placements:\nwant %#v\ngot  %#v", want, got)
+	}
+}
+
+func TestSimulateUnplacedReasons(t *testing.T) {
+	units := []job.Unit{
+		job.Unit{Name: "solver-1.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "Gang=solver\nGangSize=3")},
+		job.Unit{Name: "foo.service", TargetState: job.JobStateLaunched, Unit: newUnitWithXFleetValues(t, "MachineOf=bar.service")},
+	}
+
+	got := simulate(t, units, nil, []machine.MachineState{machine.MachineState{ID: "AAA"}})
+	want := []Placement{
+		{Name: "foo.service", Reason: "required peer Unit(bar.service) is not scheduled locally (1 of 1 machines)"},
+		{Name: "solver-1.service", Reason: "Gang(solver) cannot be placed: waiting for 2 of 3 members to be started"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected placements:\nwant %#v\ngot  %#v", want, got)
+	}
+
+	got = simulate(t, units[1:], nil, nil)
+	if len(got) != 1 || got[0].Reason != "no machines in the cluster" {
+		t.Errorf("unexpected placements %#v", got)
+	}
+}
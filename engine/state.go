@@ -15,18 +15,33 @@
 package engine
 
 import (
+	"reflect"
+	"sort"
 	"sync"
 
 	"github.com/cea-hpc/fleet/agent"
 	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/unit"
 )
 
 type clusterState struct {
-	jobs     map[string]*job.Job
-	gUnits   map[string]*job.Unit
-	machines map[string]*machine.MachineState
-	mu       *sync.RWMutex
+	jobs      map[string]*job.Job
+	gUnits    map[string]*job.Unit
+	templates map[string]*job.Unit
+	machines  map[string]*machine.MachineState
+	mu        *sync.RWMutex
+
+	// dirty and dirtyMachines record the Jobs, and the machines, which
+	// changed since the previous reconciliation of a long-lived
+	// clusterState. They are nil when every Job is to be reconciled.
+	dirty         map[string]bool
+	dirtyMachines map[string]bool
+
+	// unitStates holds the UnitStates reported by the machines the Jobs
+	// are scheduled to, when they are needed to follow the failures of
+	// the Jobs and every Job is reconciled. It is nil otherwise.
+	unitStates map[string]*unit.UnitState
 }
 
 func newClusterState(units []job.Unit, sUnits []job.ScheduledUnit, machines []machine.MachineState) *clusterState {
@@ -36,52 +51,177 @@ func newClusterState(units []job.Unit, sUnits []job.ScheduledUnit, machines []ma
 		sUnitMap[sUnit.Name] = &sUnit
 	}
 
-	jMap := make(map[string]*job.Job)
-	guMap := make(map[string]*job.Unit)
+	cs := &clusterState{
+		jobs:      make(map[string]*job.Job),
+		gUnits:    make(map[string]*job.Unit),
+		templates: make(map[string]*job.Unit),
+		mu:        new(sync.RWMutex),
+	}
 	for _, u := range units {
-		if u.IsGlobal() {
-			u := u
-			guMap[u.Name] = &u
-		} else {
-			j := job.Job{
-				Name:        u.Name,
-				Unit:        u.Unit,
-				TargetState: u.TargetState,
-			}
+		u := u
+		cs.add(&u, sUnitMap[u.Name])
+	}
 
-			if sUnit, ok := sUnitMap[u.Name]; ok {
-				j.TargetMachineID = sUnit.TargetMachineID
-				j.State = sUnit.State
-			}
+	mMap := make(map[string]*machine.MachineState, len(machines))
+	for _, ms := range machines {
+		ms := ms
+		mMap[ms.ID] = &ms
+	}
+	cs.machines = mMap
+
+	return cs
+}
 
-			jMap[j.Name] = &j
+// add records the Unit, along with its schedule if any, in the cluster.
+func (cs *clusterState) add(u *job.Unit, sUnit *job.ScheduledUnit) {
+	if _, ok := u.RequiredReplicas(); ok && !u.IsGlobal() {
+		cs.templates[u.Name] = u
+	}
+
+	if u.IsGlobal() {
+		cs.gUnits[u.Name] = u
+		return
+	}
+
+	j := job.Job{
+		Name:        u.Name,
+		Unit:        u.Unit,
+		TargetState: u.TargetState,
+	}
+
+	if sUnit != nil {
+		j.TargetMachineID = sUnit.TargetMachineID
+		j.State = sUnit.State
+	}
+
+	cs.jobs[j.Name] = &j
+}
+
+// setUnitStates records the given UnitStates, keeping only the ones
+// reported by the machine each Job is scheduled to.
+func (cs *clusterState) setUnitStates(states []*unit.UnitState) {
+	cs.unitStates = make(map[string]*unit.UnitState)
+	for _, us := range states {
+		if j, ok := cs.jobs[us.UnitName]; ok && j.Scheduled() && j.TargetMachineID == us.MachineID {
+			cs.unitStates[us.UnitName] = us
 		}
 	}
+}
+
+// beginRound forgets which Jobs and machines changed, so that the next
+// reconciliation of the clusterState is restricted to the ones updated
+// from now on. The UnitStates of the previous round are forgotten too, as
+// they are not kept up to date.
+func (cs *clusterState) beginRound() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.dirty = make(map[string]bool)
+	cs.dirtyMachines = make(map[string]bool)
+	cs.unitStates = nil
+}
+
+// update replaces the Unit of the given name with its latest version and
+// schedule in the Registry, or removes it if the Unit is nil. The Jobs
+// scheduled to the machines it leaves or lands on are affected too, since
+// they may conflict with it or depend on it.
+func (cs *clusterState) update(name string, u *job.Unit, sUnit *job.ScheduledUnit) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	global := false
+	if old, ok := cs.jobs[name]; ok {
+		cs.dirtyMachines[old.TargetMachineID] = true
+	} else if _, ok := cs.gUnits[name]; ok {
+		global = true
+	}
+
+	delete(cs.jobs, name)
+	delete(cs.gUnits, name)
+	delete(cs.templates, name)
+	cs.dirty[name] = true
+
+	if u != nil {
+		cs.add(u, sUnit)
+		if j, ok := cs.jobs[name]; ok {
+			cs.dirtyMachines[j.TargetMachineID] = true
+		} else {
+			global = true
+		}
+	}
+
+	// global units run everywhere
+	if global {
+		for id := range cs.machines {
+			cs.dirtyMachines[id] = true
+		}
+	}
+}
+
+// reconcileAll makes the next reconciliation of the clusterState cover
+// every Job, whatever changed.
+func (cs *clusterState) reconcileAll() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.dirty, cs.dirtyMachines = nil, nil
+}
+
+// full reports whether every Job of the clusterState is to be reconciled,
+// e.g. after a full load of the cluster.
+func (cs *clusterState) full() bool {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	return cs.dirty == nil
+}
+
+// setMachines replaces the machines of the cluster, recording the ones
+// which appeared, went away or changed.
+func (cs *clusterState) setMachines(machines []machine.MachineState) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
 
 	mMap := make(map[string]*machine.MachineState, len(machines))
 	for _, ms := range machines {
 		ms := ms
 		mMap[ms.ID] = &ms
+		if old, ok := cs.machines[ms.ID]; !ok || !reflect.DeepEqual(*old, ms) {
+			cs.dirtyMachines[ms.ID] = true
+		}
 	}
+	for id := range cs.machines {
+		if _, ok := mMap[id]; !ok {
+			cs.dirtyMachines[id] = true
+		}
+	}
+	cs.machines = mMap
+}
 
-	return &clusterState{
-		jobs:     jMap,
-		gUnits:   guMap,
-		machines: mMap,
-		mu:       new(sync.RWMutex),
+// affected reports whether the scheduled Job is to be reconciled. After a
+// full load of the cluster every Job is, otherwise only the Jobs which
+// changed, and the ones scheduled to a machine which changed or went away.
+func (cs *clusterState) affected(j *job.Job) bool {
+	if cs.dirty == nil {
+		return true
 	}
+	if cs.dirty[j.Name] || cs.dirtyMachines[j.TargetMachineID] {
+		return true
+	}
+	_, ok := cs.machines[j.TargetMachineID]
+	return !ok
 }
 
 func (cs *clusterState) agents() map[string]*agent.AgentState {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
 	agents := make(map[string]*agent.AgentState, len(cs.machines))
 	for _, ms := range cs.machines {
 		ms := ms
 		agents[ms.ID] = agent.NewAgentState(ms)
 	}
 
-	cs.mu.RLock()
-	defer cs.mu.RUnlock()
-
 	for _, j := range cs.jobs {
 		j := j
 		if !j.Scheduled() || j.TargetState == job.JobStateInactive {
@@ -98,18 +238,19 @@ func (cs *clusterState) agents() map[string]*agent.AgentState {
 		}
 	}
 
-	for _, gu := range cs.gUnits {
-		gu := gu
-		for _, a := range agents {
-			if !machine.HasMetadata(a.MState, gu.RequiredTargetMetadata()) {
-				continue
-			}
-
-			if cExists, _ := a.HasConflict(gu.Name, gu.Conflicts()); cExists {
-				continue
-			}
-			a.Units[gu.Name] = gu
-		}
+	// global units are placed in the order of their names, the way the
+	// fleet client accounts for them
+	names := make([]string, 0, len(cs.gUnits))
+	for name := range cs.gUnits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	gUnits := make([]*job.Unit, 0, len(names))
+	for _, name := range names {
+		gUnits = append(gUnits, cs.gUnits[name])
+	}
+	for _, a := range agents {
+		job.AddGlobalUnits(a.MState, a.Units, gUnits)
 	}
 
 	return agents
@@ -136,3 +277,17 @@ func (cs *clusterState) unschedule(jobName string) {
 	}
 	j.TargetMachineID = ""
 }
+
+func (cs *clusterState) create(j *job.Job) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.jobs[j.Name] = j
+}
+
+func (cs *clusterState) destroy(jobName string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	delete(cs.jobs, jobName)
+}
@@ -27,7 +27,11 @@ import (
 )
 
 func newUnitWithMetadata(t *testing.T, metadata string) unit.UnitFile {
-	contents := fmt.Sprintf("[X-Fleet]\nMachineMetadata=%s", metadata)
+	return newUnitWithXFleetValues(t, fmt.Sprintf("MachineMetadata=%s", metadata))
+}
+
+func newUnitWithXFleetValues(t *testing.T, values string) unit.UnitFile {
+	contents := fmt.Sprintf("[X-Fleet]\n%s", values)
 	u, err := unit.NewUnitFile(contents)
 	if err != nil {
 		t.Fatalf("error creating unit from %q: %v", contents, err)
@@ -0,0 +1,145 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"time"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/metrics"
+)
+
+// Throttle caps the number of tasks issued by the engine per
+// reconciliation, so that machines are not asked to start or stop a large
+// number of units at once, e.g. after a big machine went away. Zero means
+// no limit. Units left over are scheduled or unscheduled in the following
+// reconciliations.
+type Throttle struct {
+	// MaxSchedules and MaxUnschedules cap the number of units scheduled
+	// and unscheduled per reconciliation
+	MaxSchedules   int
+	MaxUnschedules int
+	// MaxSchedulesPerMachine and MaxUnschedulesPerMachine cap the
+	// number of units scheduled to and unscheduled from each machine per
+	// reconciliation
+	MaxSchedulesPerMachine   int
+	MaxUnschedulesPerMachine int
+}
+
+func (th *Throttle) limits(typ string) (max, maxPerMachine int) {
+	switch typ {
+	case taskTypeAttemptScheduleUnit:
+		return th.MaxSchedules, th.MaxSchedulesPerMachine
+	case taskTypeUnscheduleUnit:
+		return th.MaxUnschedules, th.MaxUnschedulesPerMachine
+	}
+	return 0, 0
+}
+
+// taskBudget counts the tasks issued in a reconciliation against the
+// limits of a Throttle, along with the tasks deferred to the next ones.
+type taskBudget struct {
+	th         *Throttle
+	issued     map[string]int
+	perMachine map[string]map[string]int
+	deferred   map[string]int
+}
+
+func newTaskBudget(th *Throttle) *taskBudget {
+	return &taskBudget{
+		th:         th,
+		issued:     make(map[string]int),
+		perMachine: make(map[string]map[string]int),
+		deferred:   make(map[string]int),
+	}
+}
+
+// allow reports whether tasks of the given type may be issued to each of
+// the given machines, a machine being given once per task.
+func (b *taskBudget) allow(typ string, machIDs ...string) bool {
+	if b.th == nil {
+		return true
+	}
+
+	max, maxPerMachine := b.th.limits(typ)
+	if max > 0 && b.issued[typ]+len(machIDs) > max {
+		return false
+	}
+	if maxPerMachine > 0 {
+		wanted := make(map[string]int)
+		for _, id := range machIDs {
+			wanted[id]++
+			if b.perMachine[typ][id]+wanted[id] > maxPerMachine {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// exhausted reports whether no more task of the given type may be issued
+// in the reconciliation, to any machine.
+func (b *taskBudget) exhausted(typ string) bool {
+	if b.th == nil {
+		return false
+	}
+
+	max, _ := b.th.limits(typ)
+	return max > 0 && b.issued[typ] >= max
+}
+
+// take records a task of the given type issued to the machine.
+func (b *taskBudget) take(typ, machID string) {
+	b.issued[typ]++
+	if b.perMachine[typ] == nil {
+		b.perMachine[typ] = make(map[string]int)
+	}
+	b.perMachine[typ][machID]++
+}
+
+// postpone records n tasks of the given type deferred to the next
+// reconciliations.
+func (b *taskBudget) postpone(typ string, n int) {
+	b.deferred[typ] += n
+}
+
+func (b *taskBudget) report() {
+	for _, typ := range []string{taskTypeAttemptScheduleUnit, taskTypeUnscheduleUnit} {
+		metrics.ReportEngineDeferredTasks(typ, b.deferred[typ])
+	}
+}
+
+// byWaitingTime sorts the jobs waiting to be scheduled by descending
+// priority, then from the one waiting for the longest time, so that jobs
+// deferred by a Throttle are not overtaken by new ones, then by name.
+type byWaitingTime struct {
+	jobs  []*job.Job
+	since map[string]time.Time
+}
+
+func (bw byWaitingTime) Len() int      { return len(bw.jobs) }
+func (bw byWaitingTime) Swap(i, j int) { bw.jobs[i], bw.jobs[j] = bw.jobs[j], bw.jobs[i] }
+
+func (bw byWaitingTime) Less(i, j int) bool {
+	iPrio, jPrio := bw.jobs[i].Priority(), bw.jobs[j].Priority()
+	if iPrio != jPrio {
+		return iPrio > jPrio
+	}
+	iSince, jSince := bw.since[bw.jobs[i].Name], bw.since[bw.jobs[j].Name]
+	if !iSince.Equal(jSince) {
+		return iSince.Before(jSince)
+	}
+	return bw.jobs[i].Name < bw.jobs[j].Name
+}
@@ -0,0 +1,157 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+)
+
+// countTasks returns the number of tasks of each type calculated for the
+// cluster, per type and machine.
+func countTasks(r *Reconciler, clust *clusterState) map[string]map[string]int {
+	count := make(map[string]map[string]int)
+	for tsk := range r.calculateClusterTasks(clust, make(chan struct{})) {
+		if count[tsk.Type] == nil {
+			count[tsk.Type] = make(map[string]int)
+		}
+		count[tsk.Type][tsk.MachineID]++
+		count[tsk.Type][""]++
+	}
+	return count
+}
+
+func TestCalculateClusterTasksThrottle(t *testing.T) {
+	// four units to schedule, and four scheduled to a machine which
+	// went away
+	var units []job.Unit
+	var sUnits []job.ScheduledUnit
+	for i := 1; i <= 8; i++ {
+		name := fmt.Sprintf("u%d.service", i)
+		units = append(units, job.Unit{Name: name, TargetState: job.JobStateLaunched})
+		if i > 4 {
+			sUnits = append(sUnits, job.ScheduledUnit{Name: name, TargetMachineID: "ZZZ"})
+		}
+	}
+	machines := []machine.MachineState{{ID: "XXX"}, {ID: "YYY"}}
+
+	for i, tt := range []struct {
+		throttle *Throttle
+
+		schedules   int
+		unschedules int
+		// maximum number of units scheduled to a single machine
+		perMachine int
+	}{
+		{nil, 8, 4, 4},
+		{&Throttle{}, 8, 4, 4},
+		{&Throttle{MaxSchedules: 3}, 3, 4, 2},
+		{&Throttle{MaxUnschedules: 1}, 5, 1, 3},
+		{&Throttle{MaxSchedulesPerMachine: 1}, 2, 4, 1},
+		{&Throttle{MaxUnschedulesPerMachine: 2}, 6, 2, 3},
+		{&Throttle{MaxSchedules: 5, MaxSchedulesPerMachine: 2}, 4, 4, 2},
+	} {
+		r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
+		r.throttle = tt.throttle
+
+		count := countTasks(r, newClusterState(units, sUnits, machines))
+		if got := count[taskTypeAttemptScheduleUnit][""]; got != tt.schedules {
+			t.Errorf("case %d: expected %d units scheduled, got %d", i, tt.schedules, got)
+		}
+		if got := count[taskTypeUnscheduleUnit][""]; got != tt.unschedules {
+			t.Errorf("case %d: expected %d units unscheduled, got %d", i, tt.unschedules, got)
+		}
+		for _, id := range []string{"XXX", "YYY"} {
+			if got := count[taskTypeAttemptScheduleUnit][id]; got > tt.perMachine {
+				t.Errorf("case %d: expected at most %d units scheduled to %s, got %d", i, tt.perMachine, id, got)
+			}
+		}
+	}
+}
+
+func TestCalculateClusterTasksThrottleGang(t *testing.T) {
+	var units []job.Unit
+	for i := 1; i <= 3; i++ {
+		units = append(units, job.Unit{
+			Name:        fmt.Sprintf("solver%d.service", i),
+			Unit:        newUnitWithXFleetValues(t, "Gang=solver"),
+			TargetState: job.JobStateLaunched,
+		})
+	}
+	machines := []machine.MachineState{{ID: "XXX"}, {ID: "YYY"}, {ID: "ZZZ"}}
+
+	// a gang is deferred as a whole
+	r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
+	r.throttle = &Throttle{MaxSchedules: 2}
+	if count := countTasks(r, newClusterState(units, nil, machines)); len(count) != 0 {
+		t.Errorf("Expected no task, got %v", count)
+	}
+
+	r.throttle = &Throttle{MaxSchedules: 3, MaxSchedulesPerMachine: 1}
+	if count := countTasks(r, newClusterState(units, nil, machines)); count[taskTypeAttemptScheduleUnit][""] != 3 {
+		t.Errorf("Expected the gang to be scheduled, got %v", count)
+	}
+}
+
+func TestCalculateClusterTasksWaitingOrder(t *testing.T) {
+	r := NewReconciler(&leastLoadedScheduler{}, nil, 0)
+	r.throttle = &Throttle{MaxSchedules: 1}
+	fclock := clockwork.NewFakeClock()
+	r.clock = fclock
+
+	unit := func(name, opts string) job.Unit {
+		return job.Unit{Name: name, Unit: newUnitWithXFleetValues(t, opts), TargetState: job.JobStateLaunched}
+	}
+	machines := []machine.MachineState{{ID: "XXX"}}
+
+	var units []job.Unit
+	scheduled := make(map[string]bool)
+	for round, tt := range []struct {
+		add    []job.Unit
+		expect string
+	}{
+		// units waiting for the same time go by name
+		{[]job.Unit{unit("c.service", ""), unit("b.service", "")}, "b.service"},
+		// units waiting for longer go first, whatever their name
+		{[]job.Unit{unit("d.service", ""), unit("a.service", "")}, "c.service"},
+		// but after units of higher priority
+		{[]job.Unit{unit("e.service", "Priority=10")}, "e.service"},
+		{nil, "a.service"},
+		{nil, "d.service"},
+	} {
+		fclock.Advance(time.Minute)
+		units = append(units, tt.add...)
+
+		var sUnits []job.ScheduledUnit
+		for name := range scheduled {
+			sUnits = append(sUnits, job.ScheduledUnit{Name: name, TargetMachineID: "XXX"})
+		}
+
+		var got []string
+		for tsk := range r.calculateClusterTasks(newClusterState(units, sUnits, machines), make(chan struct{})) {
+			got = append(got, tsk.JobName)
+			scheduled[tsk.JobName] = true
+		}
+		if len(got) != 1 || got[0] != tt.expect {
+			t.Errorf("round %d: expected %s to be scheduled, got %v", round, tt.expect, got)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/schema"
+)
+
+var (
+	cmdCordon = &cobra.Command{
+		Use:   "cordon MACHINE",
+		Short: "Exclude a machine from new unit placements",
+		Long: `Marks a machine as cordoned. The engine no longer schedules new units to a
+cordoned machine, while units already scheduled to it keep running.
+
+A machine can be selected by any unique prefix of its ID:
+fleetctl cordon 2444264c`,
+		Run: runWrapper(runCordonMachine),
+	}
+	cmdUncordon = &cobra.Command{
+		Use:   "uncordon MACHINE",
+		Short: "Make a cordoned or draining machine available for placements again",
+		Long: `Clears the maintenance mode of a machine set by cordon or drain, so that the
+engine may schedule units to it again. Units moved away by a drain are not
+moved back.`,
+		Run: runWrapper(runUncordonMachine),
+	}
+	cmdDrain = &cobra.Command{
+		Use:   "drain [--no-block|--block-attempts=N] MACHINE",
+		Short: "Move every non-global unit away from a machine",
+		Long: `Marks a machine as draining. Like cordon, this excludes the machine from new
+placements; in addition, the engine reschedules every non-global unit away from
+the machine.
+
+Drain blocks until the units which were scheduled to the machine are running
+elsewhere. This behaviour can be configured with the respective --block-attempts
+and --no-block options. Global units are left untouched. Units pinned to the
+machine with MachineID are stopped, and reported as they cannot run elsewhere:
+drain does not wait for them.
+
+Once maintenance is over, the machine can be put back in service:
+fleetctl uncordon 2444264c`,
+		Run: runWrapper(runDrainMachine),
+	}
+)
+
+func init() {
+	cmdFleet.AddCommand(cmdCordon)
+	cmdFleet.AddCommand(cmdUncordon)
+	cmdFleet.AddCommand(cmdDrain)
+
+	cmdDrain.Flags().IntVar(&sharedFlags.BlockAttempts, "block-attempts", 0, "Wait until the units are running elsewhere, performing up to N attempts before giving up. A value of 0 indicates no limit.")
+	cmdDrain.Flags().BoolVar(&sharedFlags.NoBlock, "no-block", false, "Do not wait until the units are running elsewhere before exiting.")
+}
+
+func runCordonMachine(cCmd *cobra.Command, args []string) (exit int) {
+	_, exit = setMachineMaintenance(args, machine.MaintenanceCordoned)
+	return
+}
+
+func runUncordonMachine(cCmd *cobra.Command, args []string) (exit int) {
+	_, exit = setMachineMaintenance(args, "")
+	return
+}
+
+func runDrainMachine(cCmd *cobra.Command, args []string) (exit int) {
+	machID, exit := setMachineMaintenance(args, machine.MaintenanceDraining)
+	if exit != 0 {
+		return
+	}
+
+	units, err := cAPI.Units()
+	if err != nil {
+		stderr("Error retrieving list of units from repository: %v", err)
+		return 1
+	}
+
+	var draining []string
+	for _, u := range units {
+		if u.MachineID != machID || suToGlobal(*u) {
+			continue
+		}
+		// a unit pinned to the machine is stopped, but cannot run
+		// anywhere else
+		if suToPinned(*u) {
+			stderr("Unit %s is pinned to machine %s, not waiting for it to run elsewhere", u.Name, machID)
+			continue
+		}
+		draining = append(draining, u.Name)
+	}
+
+	attempts := getBlockAttempts(cCmd)
+	if len(draining) == 0 || attempts < 0 {
+		return 0
+	}
+
+	for attempt := 0; attempts == 0 || attempt < attempts; attempt++ {
+		if _, err := waitForState(func() error { return assertUnitsDrained(draining, machID) }); err == nil {
+			stdout("Machine %s drained", machID)
+			return 0
+		}
+	}
+
+	stderr("Timed out waiting for units to leave machine %s", machID)
+	return 1
+}
+
+// setMachineMaintenance resolves the single machine named in args and
+// sets its maintenance mode, an empty mode putting it back in service.
+// It returns the ID of the machine.
+func setMachineMaintenance(args []string, mode string) (machID string, exit int) {
+	if len(args) != 1 {
+		stderr("One machine must be provided")
+		return "", 1
+	}
+
+	machID, err := findMachineInMachineList(args[0])
+	if err != nil {
+		stderr("Error looking up machine %s: %v", args[0], err)
+		return "", 1
+	}
+
+	if err := cAPI.SetMachineMaintenance(machID, mode); err != nil {
+		stderr("Error setting maintenance of machine %s: %v", machID, err)
+		return "", 1
+	}
+
+	if mode == "" {
+		stdout("Machine %s uncordoned", machID)
+	} else {
+		stdout("Machine %s %s", machID, mode)
+	}
+	return machID, 0
+}
+
+// findMachineInMachineList returns the ID of the only active machine whose
+// ID starts with lookup.
+func findMachineInMachineList(lookup string) (string, error) {
+	states, err := cAPI.Machines()
+	if err != nil {
+		return "", err
+	}
+
+	var match string
+	for _, ms := range states {
+		if !strings.HasPrefix(ms.ID, lookup) {
+			continue
+		}
+		if match != "" {
+			return "", fmt.Errorf("found more than one machine")
+		}
+		match = ms.ID
+	}
+
+	if match == "" {
+		return "", fmt.Errorf("machine does not exist")
+	}
+	return match, nil
+}
+
+// suToPinned determines whether the Unit is pinned to a single machine with
+// the MachineID option.
+func suToPinned(su schema.Unit) bool {
+	u := job.Unit{
+		Unit: *schema.MapSchemaUnitOptionsToUnitFile(su.Options),
+	}
+	_, ok := u.RequiredTarget()
+	return ok
+}
+
+// assertUnitsDrained returns an error as long as one of the named units is
+// still scheduled to machID, or has not been launched on another machine
+// while its desired state is launched.
+func assertUnitsDrained(names []string, machID string) error {
+	for _, name := range names {
+		u, err := cAPI.Unit(name)
+		if err != nil {
+			return fmt.Errorf("Error retrieving Unit(%s) from Registry: %v", name, err)
+		}
+		if u == nil {
+			continue
+		}
+		if u.MachineID == machID {
+			return fmt.Errorf("Waiting for Unit(%s) to leave Machine(%s)", name, machID)
+		}
+		launched := string(job.JobStateLaunched)
+		if u.DesiredState == launched && (u.MachineID == "" || u.CurrentState != launched) {
+			return fmt.Errorf("Waiting for Unit(%s) to be launched elsewhere", name)
+		}
+	}
+	return nil
+}
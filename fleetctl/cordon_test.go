@@ -0,0 +1,99 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cea-hpc/fleet/client"
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/unit"
+)
+
+func TestRunCordonMachine(t *testing.T) {
+	cAPI = newFakeRegistryForCommands("j", 2, false)
+	sharedFlags.NoBlock = true
+	defer func() { sharedFlags.NoBlock = false }()
+
+	for i, tt := range []struct {
+		run         func([]string) int
+		args        []string
+		exit        int
+		maintenance string
+	}{
+		{runCordon, []string{"c31e"}, 0, machine.MaintenanceCordoned},
+		{runUncordon, []string{"c31e44e1-f858-436e-933e-59c642517860"}, 0, ""},
+		{runDrain, []string{"c31e"}, 0, machine.MaintenanceDraining},
+		{runCordon, []string{"c31e"}, 0, machine.MaintenanceCordoned},
+		{runCordon, []string{"ffff"}, 1, machine.MaintenanceCordoned},
+		{runCordon, []string{}, 1, machine.MaintenanceCordoned},
+		{runUncordon, []string{"c31e", "5959"}, 1, machine.MaintenanceCordoned},
+	} {
+		if exit := tt.run(tt.args); exit != tt.exit {
+			t.Errorf("case %d: expected exit code %d, got %d", i, tt.exit, exit)
+		}
+
+		machines, err := cAPI.Machines()
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		for _, ms := range machines {
+			want := ""
+			if ms.ID == "c31e44e1-f858-436e-933e-59c642517860" {
+				want = tt.maintenance
+			}
+			if ms.Maintenance != want {
+				t.Errorf("case %d: expected Machine(%s) maintenance %q, got %q", i, ms.ID, want, ms.Maintenance)
+			}
+		}
+	}
+}
+
+func TestRunDrainMachineUnmovable(t *testing.T) {
+	machID := "c31e44e1-f858-436e-933e-59c642517860"
+	newUnit := func(contents string) unit.UnitFile {
+		u, err := unit.NewUnitFile(contents)
+		if err != nil {
+			t.Fatalf("unexpected error creating unit from %q: %v", contents, err)
+		}
+		return *u
+	}
+	reg := registry.NewFakeRegistry()
+	reg.SetMachines([]machine.MachineState{newMachineState(machID, "1.2.3.4", nil)})
+	reg.SetJobs([]job.Job{
+		{Name: "pinned.service", Unit: newUnit("[X-Fleet]\nMachineID=" + machID), TargetState: job.JobStateLaunched, TargetMachineID: machID},
+		{Name: "global.service", Unit: newUnit("[X-Fleet]\nGlobal=true"), TargetState: job.JobStateLaunched},
+	})
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	// drain does not wait forever for units which cannot run elsewhere
+	done := make(chan int)
+	go func() { done <- runDrain([]string{"c31e"}) }()
+	select {
+	case exit := <-done:
+		if exit != 0 {
+			t.Errorf("expected exit code 0, got %d", exit)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("drain kept waiting for units pinned to the machine or global")
+	}
+}
+
+func runCordon(args []string) int   { return runCordonMachine(cmdCordon, args) }
+func runUncordon(args []string) int { return runUncordonMachine(cmdUncordon, args) }
+func runDrain(args []string) int    { return runDrainMachine(cmdDrain, args) }
@@ -0,0 +1,105 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cea-hpc/fleet/machine"
+)
+
+var (
+	flagEventsUnit    string
+	flagEventsMachine string
+)
+
+var cmdEvents = &cobra.Command{
+	Use:   "events [-l|--full] [--no-legend] [--unit=UNIT] [--machine=MACHINE]",
+	Short: "List the recent scheduling decisions of the engine",
+	Long: `Lists the most recent scheduling decisions of the engine leaders, oldest first:
+units scheduled to a machine, unscheduled from it, rescheduled from a machine to
+another one, and instances of replicated templates created or destroyed. Each
+decision comes with its reason and the machine of the engine leader which took
+it. The engine keeps a bounded history of decisions, which survives leadership
+changes.
+
+List the decisions about a unit:
+fleetctl events --unit=foo.service
+
+List the decisions about a machine, including units moved away from it:
+fleetctl events --machine=2444264c`,
+	Run: runWrapper(runEvents),
+}
+
+func init() {
+	cmdFleet.AddCommand(cmdEvents)
+
+	cmdEvents.Flags().BoolVar(&sharedFlags.Full, "full", false, "Do not ellipsize fields on output")
+	cmdEvents.Flags().BoolVar(&sharedFlags.Full, "l", false, "Shorthand for --full")
+	cmdEvents.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
+	cmdEvents.Flags().StringVar(&flagEventsUnit, "unit", "", "Only list the decisions about the given unit")
+	cmdEvents.Flags().StringVar(&flagEventsMachine, "machine", "", "Only list the decisions about the given machine")
+}
+
+func runEvents(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 0 {
+		stderr("No arguments expected, see --unit and --machine")
+		return 1
+	}
+
+	var unitName string
+	if flagEventsUnit != "" {
+		unitName = unitNameMangle(flagEventsUnit)
+	}
+
+	// machines which went away are only matched by their full ID
+	machID := flagEventsMachine
+	if machID != "" {
+		if id, err := findMachineInMachineList(machID); err == nil {
+			machID = id
+		}
+	}
+
+	events, err := cAPI.EngineEvents(unitName, machID)
+	if err != nil {
+		stderr("Error retrieving engine events: %v", err)
+		return 1
+	}
+
+	noLegend, _ := cCmd.Flags().GetBool("no-legend")
+	if !noLegend {
+		fmt.Fprintln(out, "TIME\tTYPE\tUNIT\tMACHINE\tLEADER\tREASON")
+	}
+
+	full, _ := cCmd.Flags().GetBool("full")
+	legend := func(id string) string {
+		if id == "" {
+			return "-"
+		}
+		return machineIDLegend(machine.MachineState{ID: id}, full)
+	}
+	for _, ev := range events {
+		mach := legend(ev.MachineID)
+		if ev.PreviousMachineID != "" {
+			mach = fmt.Sprintf("%s->%s", legend(ev.PreviousMachineID), mach)
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\n", ev.Time, ev.Type, ev.UnitName, mach, legend(ev.LeaderID), ev.Reason)
+	}
+	out.Flush()
+
+	return
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/cea-hpc/fleet/client"
+	"github.com/cea-hpc/fleet/schema"
+)
+
+// eventsAPI records the filters of the engine events requested through it
+type eventsAPI struct {
+	client.API
+	unitName, machineID string
+}
+
+func (ea *eventsAPI) EngineEvents(unitName, machineID string) ([]*schema.EngineEvent, error) {
+	ea.unitName, ea.machineID = unitName, machineID
+	return []*schema.EngineEvent{
+		{Time: "2016-05-04T12:00:00Z", LeaderID: "c31e44e1-f858-436e-933e-59c642517860", Type: "reschedule", UnitName: "j1.service", MachineID: "c31e44e1-f858-436e-933e-59c642517860", PreviousMachineID: "595989bb-cbb7-49ce-8726-722d6e157b4e", Reason: "target Machine(595989bb-cbb7-49ce-8726-722d6e157b4e) is draining"},
+	}, nil
+}
+
+func TestRunEvents(t *testing.T) {
+	defer func() {
+		flagEventsUnit = ""
+		flagEventsMachine = ""
+	}()
+
+	for i, tt := range []struct {
+		unit    string
+		machine string
+		args    []string
+
+		exit      int
+		unitName  string
+		machineID string
+	}{
+		{"", "", nil, 0, "", ""},
+		{"j1", "", nil, 0, "j1.service", ""},
+		{"", "595989bb", nil, 0, "", "595989bb-cbb7-49ce-8726-722d6e157b4e"},
+		// machines which went away are passed as is
+		{"j1.service", "ffff", nil, 0, "j1.service", "ffff"},
+		{"", "", []string{"j1.service"}, 1, "", ""},
+	} {
+		ea := &eventsAPI{API: newFakeRegistryForCommands("j", 1, false)}
+		cAPI = ea
+		flagEventsUnit = tt.unit
+		flagEventsMachine = tt.machine
+		if exit := runEvents(cmdEvents, tt.args); exit != tt.exit {
+			t.Errorf("case %d: expected exit code %d, got %d", i, tt.exit, exit)
+		}
+		if ea.unitName != tt.unitName || ea.machineID != tt.machineID {
+			t.Errorf("case %d: expected events of unit %q and machine %q, got %q and %q", i, tt.unitName, tt.machineID, ea.unitName, ea.machineID)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cea-hpc/fleet/machine"
+)
+
+var cmdExplain = &cobra.Command{
+	Use:   "explain [-l|--full] [--no-legend] UNIT",
+	Short: "Explain which machines are able to run a unit",
+	Long: `Evaluates a submitted unit against every machine in the cluster, the way the
+engine would when scheduling it. For each machine, explain tells whether it is
+able to run the unit and why: a metadata mismatch, a conflict with another unit,
+a missing peer, or its ranking among the able machines.
+
+Machines are listed in the order the scheduler considers them, and the machines
+the engine leader keeps the unit away from after it failed too often on them
+are reported as unable to run it.
+
+The explanation is computed by the fleet API, so explain is not available with
+--driver=etcd.`,
+	Run: runWrapper(runExplainUnit),
+}
+
+func init() {
+	cmdFleet.AddCommand(cmdExplain)
+
+	cmdExplain.Flags().BoolVar(&sharedFlags.Full, "full", false, "Do not ellipsize fields on output")
+	cmdExplain.Flags().BoolVar(&sharedFlags.Full, "l", false, "Shorthand for --full")
+	cmdExplain.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
+}
+
+func runExplainUnit(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 1 {
+		stderr("One unit file must be provided")
+		return 1
+	}
+
+	name := unitNameMangle(args[0])
+	ue, err := cAPI.UnitExplanation(name)
+	if err != nil {
+		stderr("Error explaining Unit %s: %v", name, err)
+		return 1
+	}
+	if ue == nil {
+		stderr("Unit %s not found", name)
+		return 1
+	}
+
+	if ue.MachineID != "" {
+		stdout("Unit %s is scheduled to machine %s.", ue.Name, ue.MachineID)
+	}
+	stdout("Machines are ranked by the %s scheduler.", ue.Scheduler)
+
+	if len(ue.Machines) == 0 {
+		stdout("No machines found.")
+		return
+	}
+
+	noLegend, _ := cCmd.Flags().GetBool("no-legend")
+	if !noLegend {
+		fmt.Fprintln(out, "MACHINE\tABLE\tREASON")
+	}
+
+	full, _ := cCmd.Flags().GetBool("full")
+	for _, me := range ue.Machines {
+		ms := machine.MachineState{ID: me.MachineID, PublicIP: me.PrimaryIP}
+		able := "no"
+		if me.Able {
+			able = "yes"
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\n", machineFullLegend(ms, full), able, me.Reason)
+	}
+	out.Flush()
+
+	return
+}
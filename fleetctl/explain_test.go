@@ -0,0 +1,63 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cea-hpc/fleet/api"
+	"github.com/cea-hpc/fleet/client"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+func TestRunExplainUnit(t *testing.T) {
+	// Units are explained by the fleet API, so serve the fake Registry
+	rc := newFakeRegistryForCommands("j", 2, false).(*client.RegistryClient)
+	srv := httptest.NewServer(api.NewServeMux(rc.Registry, registry.NewFakeClusterRegistry(nil, 0), 100))
+	defer srv.Close()
+
+	ep, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("Failed parsing test server URL: %v", err)
+	}
+	cAPI, err = client.NewHTTPClient(&http.Client{}, *ep)
+	if err != nil {
+		t.Fatalf("Failed creating HTTP client: %v", err)
+	}
+
+	for i, tt := range []struct {
+		args []string
+		exit int
+	}{
+		{[]string{"j1"}, 0},
+		{[]string{"j2.service"}, 0},
+		{[]string{"y1.service"}, 1},
+		{[]string{}, 1},
+		{[]string{"j1", "j2"}, 1},
+	} {
+		if exit := runExplainUnit(cmdExplain, tt.args); exit != tt.exit {
+			t.Errorf("case %d: expected exit code %d, got %d", i, tt.exit, exit)
+		}
+	}
+
+	// The Registry driver cannot explain units
+	cAPI = rc
+	if exit := runExplainUnit(cmdExplain, []string{"j1"}); exit != 1 {
+		t.Errorf("expected exit code 1 without the fleet API, got %d", exit)
+	}
+}
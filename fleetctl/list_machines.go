@@ -17,6 +17,7 @@ package main
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -53,6 +54,42 @@ var (
 				return "-"
 			}
 		},
+		"maintenance": func(ms *machine.MachineState, full bool) string {
+			if ms.Maintenance == "" {
+				return "-"
+			}
+			return ms.Maintenance
+		},
+		"cores": func(ms *machine.MachineState, full bool) string {
+			if ms.TotalResources.Empty() {
+				return "-"
+			}
+			return fmt.Sprintf("%s/%s", formatCores(ms.FreeResources.Cores), formatCores(ms.TotalResources.Cores))
+		},
+		"memory": func(ms *machine.MachineState, full bool) string {
+			if ms.TotalResources.Empty() {
+				return "-"
+			}
+			return fmt.Sprintf("%dM/%dM", ms.FreeResources.Memory, ms.TotalResources.Memory)
+		},
+		"disk": func(ms *machine.MachineState, full bool) string {
+			if ms.TotalResources.Empty() {
+				return "-"
+			}
+			return fmt.Sprintf("%dM/%dM", ms.FreeResources.Disk, ms.TotalResources.Disk)
+		},
+		"units": func(ms *machine.MachineState, full bool) string {
+			if ms.MaxUnits == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%d/%d", ms.UsedUnits, ms.MaxUnits)
+		},
+		"weight": func(ms *machine.MachineState, full bool) string {
+			if ms.MaxWeight == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%d/%d", ms.UsedWeight, ms.MaxWeight)
+		},
 	}
 )
 
@@ -67,7 +104,16 @@ For easily parsable output, you can remove the column headers:
 fleetctl list-machines --no-legend
 
 Output the list without truncation:
-fleetctl list-machines --full`,
+fleetctl list-machines --full
+
+Show the free and total resources of each machine:
+fleetctl list-machines --fields=machine,cores,memory,disk
+
+Show the used and maximum number and weight of units of each machine:
+fleetctl list-machines --fields=machine,units,weight
+
+Show which machines are cordoned or draining:
+fleetctl list-machines --fields=machine,ip,maintenance`,
 	Run: runWrapper(runListMachines),
 }
 
@@ -139,6 +185,11 @@ func formatMetadata(metadata map[string]string) string {
 	return strings.Join(pairs, ",")
 }
 
+// formatCores renders a number of cores expressed in hundredths of a core
+func formatCores(cores int) string {
+	return strconv.FormatFloat(float64(cores)/100, 'f', -1, 64)
+}
+
 func machineToFieldKeys(m map[string]machineToField) (keys []string) {
 	for k, _ := range m {
 		keys = append(keys, k)
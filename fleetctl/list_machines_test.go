@@ -19,6 +19,7 @@ import (
 
 	"github.com/cea-hpc/fleet/machine"
 	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/resource"
 )
 
 func newTestRegistryForListMachines() registry.Registry {
@@ -67,6 +68,42 @@ func TestListMachinesFieldsToStrings(t *testing.T) {
 	assertEqual(t, "hostname", "machineHostname", val)
 }
 
+func TestListMachinesResourceFields(t *testing.T) {
+	ms := &machine.MachineState{
+		ID:             "4d389537d9d14bdabe8be54a9c29f68d",
+		TotalResources: resource.ResourceTuple{Cores: 800, Memory: 16384, Disk: 0},
+		FreeResources:  resource.ResourceTuple{Cores: 450, Memory: 8192, Disk: 0},
+	}
+
+	val := listMachinesFields["cores"](ms, false)
+	assertEqual(t, "cores", "4.5/8", val)
+
+	val = listMachinesFields["memory"](ms, false)
+	assertEqual(t, "memory", "8192M/16384M", val)
+
+	val = listMachinesFields["disk"](ms, false)
+	assertEqual(t, "disk", "0M/0M", val)
+}
+
+func TestListMachinesCapacityFields(t *testing.T) {
+	ms := &machine.MachineState{
+		ID:         "4d389537d9d14bdabe8be54a9c29f68d",
+		MaxUnits:   10,
+		UsedUnits:  3,
+		UsedWeight: 250,
+	}
+
+	val := listMachinesFields["units"](ms, false)
+	assertEqual(t, "units", "3/10", val)
+
+	val = listMachinesFields["weight"](ms, false)
+	assertEqual(t, "weight", "-", val)
+
+	ms.MaxWeight = 1000
+	val = listMachinesFields["weight"](ms, false)
+	assertEqual(t, "weight", "250/1000", val)
+}
+
 func TestListMachinesFieldsEmpty(t *testing.T) {
 	id := "4d389537d9d14bdabe8be54a9c29f68d"
 	ip := ""
@@ -80,7 +117,7 @@ func TestListMachinesFieldsEmpty(t *testing.T) {
 		Version:  ver,
 	}
 
-	for _, tt := range []string{"ip", "metadata"} {
+	for _, tt := range []string{"ip", "metadata", "cores", "memory", "disk", "units", "weight"} {
 		f := listMachinesFields[tt](ms, false)
 		assertEqual(t, tt, "-", f)
 	}
@@ -68,6 +68,12 @@ var (
 			}
 			return u.CurrentState
 		},
+		"replicas": func(u schema.Unit, full bool) string {
+			if _, ok := schema.MapSchemaUnitToUnit(&u).RequiredReplicas(); !ok {
+				return "-"
+			}
+			return strconv.FormatInt(u.Replicas, 10)
+		},
 		"hash": func(u schema.Unit, full bool) string {
 			uf := schema.MapSchemaUnitOptionsToUnitFile(u.Options)
 			if !full {
@@ -80,6 +80,63 @@ var (
 				return "-"
 			}
 		},
+		"health": func(us *schema.UnitState, full bool) string {
+			if us == nil || us.Healthy == nil {
+				return "-"
+			}
+			if *us.Healthy {
+				return "healthy"
+			}
+			return "unhealthy"
+		},
+		"failures": func(us *schema.UnitState, full bool) string {
+			if us == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%d", us.Failures)
+		},
+		"exit": func(us *schema.UnitState, full bool) string {
+			if us == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%d", us.ExecMainStatus)
+		},
+		"restarts": func(us *schema.UnitState, full bool) string {
+			if us == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%d", us.NRestarts)
+		},
+		"active-since": func(us *schema.UnitState, full bool) string {
+			if us == nil || us.ActiveEnterTimestamp == "" {
+				return "-"
+			}
+			return us.ActiveEnterTimestamp
+		},
+		"inactive-since": func(us *schema.UnitState, full bool) string {
+			if us == nil || us.InactiveEnterTimestamp == "" {
+				return "-"
+			}
+			return us.InactiveEnterTimestamp
+		},
+		"cpu": func(us *schema.UnitState, full bool) string {
+			if us == nil {
+				return "-"
+			}
+			return formatCPUUsage(us.CpuUsageNSec)
+		},
+		"memory": func(us *schema.UnitState, full bool) string {
+			if us == nil {
+				return "-"
+			}
+			return formatMemoryUsage(us.MemoryCurrent)
+		},
+		"tasks": func(us *schema.UnitState, full bool) string {
+			if us == nil {
+				return "-"
+			}
+			return formatTasksUsage(us.TasksCurrent)
+		},
 		"hash": func(us *schema.UnitState, full bool) string {
 			if us == nil || us.Hash == "" {
 				return "-"
@@ -43,7 +43,7 @@ func TestListUnitsFieldsToStrings(t *testing.T) {
 	cAPI = fakeAPI{}
 
 	// nil UnitState shouldn't happen, but just in case
-	for _, tt := range []string{"unit", "load", "active", "sub", "machine", "hash"} {
+	for _, tt := range []string{"unit", "load", "active", "sub", "machine", "hash", "health", "failures", "exit", "restarts", "active-since", "inactive-since", "cpu", "memory", "tasks"} {
 		f := listUnitsFields[tt](nil, false)
 		assertEqual(t, tt, "-", f)
 	}
@@ -109,4 +109,33 @@ func TestListUnitsFieldsToStrings(t *testing.T) {
 	suh := listUnitsFields["hash"](us, false)
 	assertEqual(t, "hash", uh, fuh)
 	assertEqual(t, "hash", uh[:7], suh)
+
+	assertEqual(t, "health", "-", listUnitsFields["health"](us, false))
+	healthy := true
+	us.Healthy = &healthy
+	assertEqual(t, "health", "healthy", listUnitsFields["health"](us, false))
+	healthy = false
+	assertEqual(t, "health", "unhealthy", listUnitsFields["health"](us, false))
+
+	assertEqual(t, "failures", "0", listUnitsFields["failures"](us, false))
+	us.Failures = 2
+	assertEqual(t, "failures", "2", listUnitsFields["failures"](us, false))
+
+	assertEqual(t, "active-since", "-", listUnitsFields["active-since"](us, false))
+	us.ExecMainStatus = 203
+	us.NRestarts = 4
+	us.ActiveEnterTimestamp = "2017-07-14T02:40:00Z"
+	us.InactiveEnterTimestamp = "2017-07-14T02:41:00Z"
+	assertEqual(t, "exit", "203", listUnitsFields["exit"](us, false))
+	assertEqual(t, "restarts", "4", listUnitsFields["restarts"](us, false))
+	assertEqual(t, "active-since", "2017-07-14T02:40:00Z", listUnitsFields["active-since"](us, false))
+	assertEqual(t, "inactive-since", "2017-07-14T02:41:00Z", listUnitsFields["inactive-since"](us, false))
+
+	assertEqual(t, "cpu", "-", listUnitsFields["cpu"](us, false))
+	us.CpuUsageNSec = 2500000000
+	us.MemoryCurrent = 64 << 20
+	us.TasksCurrent = 3
+	assertEqual(t, "cpu", "2.5s", listUnitsFields["cpu"](us, false))
+	assertEqual(t, "memory", "64.0M", listUnitsFields["memory"](us, false))
+	assertEqual(t, "tasks", "3", listUnitsFields["tasks"](us, false))
 }
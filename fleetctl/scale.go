@@ -0,0 +1,88 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cea-hpc/fleet/schema"
+)
+
+var cmdScale = &cobra.Command{
+	Use:   "scale TEMPLATE=REPLICAS...",
+	Short: "Set the number of instances of one or more replicated template units",
+	Long: `Sets the number of instances the engine keeps of template units setting the
+Replicas option in their [X-Fleet] section. The engine creates and schedules
+missing instances, numbered from 1, and destroys the highest-numbered instances
+first when scaling down.
+
+Scale a template to eight instances:
+fleetctl scale web@.service=8
+
+The current number of instances is shown by:
+fleetctl list-unit-files --fields=unit,replicas`,
+	Run: runWrapper(runScaleUnits),
+}
+
+func init() {
+	cmdFleet.AddCommand(cmdScale)
+}
+
+func runScaleUnits(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) == 0 {
+		stderr("No units given")
+		return 1
+	}
+
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			stderr("Invalid argument %q, expected TEMPLATE=REPLICAS", arg)
+			return 1
+		}
+
+		replicas, err := strconv.ParseUint(parts[1], 10, 16)
+		if err != nil {
+			stderr("Invalid number of replicas %q for unit %s", parts[1], parts[0])
+			return 1
+		}
+
+		name := unitNameMangle(parts[0])
+		u, err := cAPI.Unit(name)
+		if err != nil {
+			stderr("Error retrieving unit %s: %v", name, err)
+			return 1
+		}
+		if u == nil {
+			stderr("Unit %s not found", name)
+			return 1
+		}
+		if _, ok := schema.MapSchemaUnitToUnit(u).RequiredReplicas(); !ok {
+			stderr("Unit %s is not a template unit with Replicas", name)
+			return 1
+		}
+
+		if err := cAPI.ScaleUnit(name, int(replicas)); err != nil {
+			stderr("Error scaling unit %s: %v", name, err)
+			return 1
+		}
+		stdout("Scaled unit %s to %d replicas", name, replicas)
+	}
+
+	return 0
+}
@@ -0,0 +1,67 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/cea-hpc/fleet/client"
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/unit"
+)
+
+func TestRunScaleUnits(t *testing.T) {
+	newUnit := func(contents string) unit.UnitFile {
+		uf, err := unit.NewUnitFile(contents)
+		if err != nil {
+			t.Fatalf("Failed creating test unit: %v", err)
+		}
+		return *uf
+	}
+
+	for i, tt := range []struct {
+		args     []string
+		exit     int
+		replicas int
+	}{
+		{[]string{"web@.service=8"}, 0, 8},
+		{[]string{"web@=0"}, 0, 0},
+		{[]string{"web@.service=-1"}, 1, 5},
+		{[]string{"web@.service"}, 1, 5},
+		{[]string{}, 1, 5},
+		{[]string{"api@.service=8"}, 1, 5},
+		{[]string{"db@.service=8"}, 1, 5},
+	} {
+		reg := registry.NewFakeRegistry()
+		reg.SetJobs([]job.Job{
+			job.Job{Name: "web@.service", Unit: newUnit("[X-Fleet]\nReplicas=5")},
+			job.Job{Name: "api@.service", Unit: newUnit("[Service]\nExecStart=/bin/true")},
+		})
+		cAPI = &client.RegistryClient{Registry: reg}
+
+		if exit := runScaleUnits(cmdScale, tt.args); exit != tt.exit {
+			t.Errorf("case %d: expected exit code %d, got %d", i, tt.exit, exit)
+		}
+
+		u, err := reg.Unit("web@.service")
+		if err != nil || u == nil {
+			t.Fatalf("case %d: failed fetching unit: %v", i, err)
+		}
+		if u.Replicas != tt.replicas {
+			t.Errorf("case %d: expected %d replicas, got %d", i, tt.replicas, u.Replicas)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cea-hpc/fleet/api"
+	"github.com/cea-hpc/fleet/engine"
+	"github.com/cea-hpc/fleet/job"
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/registry"
+	"github.com/cea-hpc/fleet/schema"
+	"github.com/cea-hpc/fleet/unit"
+)
+
+var (
+	flagSimulateSnapshot  string
+	flagSimulateUnits     string
+	flagSimulateCapture   string
+	flagSimulateScheduler string
+)
+
+// clusterSnapshot is the state of a cluster simulated by fleetctl simulate:
+// the machines, as stored in the Registry, and the units, along with their
+// desired state and the machine they are scheduled to, as served by the API.
+// It is loaded into a FakeRegistry, which the engine reconciles.
+type clusterSnapshot struct {
+	Machines []machine.MachineState `json:"machines"`
+	Units    []*schema.Unit         `json:"units"`
+}
+
+var cmdSimulate = &cobra.Command{
+	Use:   "simulate [-l|--full] [--no-legend] [--scheduler=NAME] --snapshot=FILE [--units=DIR]",
+	Short: "Simulate the scheduling of units on a snapshot of a cluster",
+	Long: `Runs the scheduling of the engine against a snapshot of a cluster and a
+directory of proposed unit files, without making any change to the cluster.
+Proposed units are started, replacing the units of the snapshot with the same
+name; proposed template units are submitted. The resulting placement of every
+unit is printed, followed by the units which could not be placed and why.
+
+The exit status is 1 when any unit could not be placed.
+
+Capture a snapshot of the current cluster:
+fleetctl simulate --capture=cluster.json
+
+Simulate starting the units of a directory on that cluster:
+fleetctl simulate --snapshot=cluster.json --units=units/`,
+	Run: runWrapper(runSimulate),
+}
+
+func init() {
+	cmdFleet.AddCommand(cmdSimulate)
+
+	cmdSimulate.Flags().BoolVar(&sharedFlags.Full, "full", false, "Do not ellipsize fields on output")
+	cmdSimulate.Flags().BoolVar(&sharedFlags.Full, "l", false, "Shorthand for --full")
+	cmdSimulate.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
+	cmdSimulate.Flags().StringVar(&flagSimulateSnapshot, "snapshot", "", "Cluster snapshot to simulate the scheduling on")
+	cmdSimulate.Flags().StringVar(&flagSimulateUnits, "units", "", "Directory of proposed unit files")
+	cmdSimulate.Flags().StringVar(&flagSimulateCapture, "capture", "", "Write a snapshot of the current cluster to this file instead of simulating")
+	cmdSimulate.Flags().StringVar(&flagSimulateScheduler, "scheduler", engine.DefaultScheduler, "Scheduling strategy to simulate")
+}
+
+func runSimulate(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 0 {
+		stderr("No arguments expected, see --snapshot and --units")
+		return 1
+	}
+
+	if flagSimulateCapture != "" {
+		if err := captureSnapshot(flagSimulateCapture); err != nil {
+			stderr("Error capturing cluster snapshot: %v", err)
+			return 1
+		}
+		stdout("Cluster snapshot written to %s", flagSimulateCapture)
+		return
+	}
+
+	if flagSimulateSnapshot == "" {
+		stderr("No cluster snapshot given, see --snapshot")
+		return 1
+	}
+
+	sched, err := engine.NewScheduler(flagSimulateScheduler)
+	if err != nil {
+		stderr("Error creating scheduler: %v", err)
+		return 1
+	}
+
+	snap, err := readSnapshot(flagSimulateSnapshot)
+	if err != nil {
+		stderr("Error reading cluster snapshot %s: %v", flagSimulateSnapshot, err)
+		return 1
+	}
+
+	if flagSimulateUnits != "" {
+		proposed, err := readProposedUnits(flagSimulateUnits)
+		if err != nil {
+			stderr("Error reading proposed units from %s: %v", flagSimulateUnits, err)
+			return 1
+		}
+		snap.propose(proposed)
+	}
+
+	placements, err := engine.Simulate(sched, snap.fakeRegistry())
+	if err != nil {
+		stderr("Error simulating scheduling: %v", err)
+		return 1
+	}
+
+	machines := make(map[string]machine.MachineState, len(snap.Machines))
+	for _, ms := range snap.Machines {
+		machines[ms.ID] = ms
+	}
+	legend := func(id string) string {
+		if id == "" {
+			return "-"
+		}
+		ms, ok := machines[id]
+		if !ok {
+			ms = machine.MachineState{ID: id}
+		}
+		return machineFullLegend(ms, sharedFlags.Full)
+	}
+
+	stdout("Units are placed by the %s scheduler.", sched.Name())
+	if !sharedFlags.NoLegend {
+		fmt.Fprintln(out, "UNIT\tMACHINE\tPREVIOUS")
+	}
+	var unplaced []engine.Placement
+	for _, p := range placements {
+		if p.MachineID == "" {
+			unplaced = append(unplaced, p)
+			continue
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\n", p.Name, legend(p.MachineID), legend(p.PreviousMachineID))
+	}
+	out.Flush()
+
+	if len(unplaced) == 0 {
+		return
+	}
+
+	stdout("")
+	if !sharedFlags.NoLegend {
+		fmt.Fprintln(out, "UNPLACED\tREASON")
+	}
+	for _, p := range unplaced {
+		fmt.Fprintf(out, "%s\t%s\n", p.Name, p.Reason)
+	}
+	out.Flush()
+
+	return 1
+}
+
+// captureSnapshot writes the machines and units of the current cluster to
+// the given file.
+func captureSnapshot(file string) error {
+	machines, err := cAPI.Machines()
+	if err != nil {
+		return err
+	}
+	units, err := cAPI.Units()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(clusterSnapshot{Machines: machines, Units: units}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, b, 0644)
+}
+
+func readSnapshot(file string) (*clusterSnapshot, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var snap clusterSnapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// readProposedUnits reads and validates the unit files of the given
+// directory. Units are started, except for templates which are submitted.
+func readProposedUnits(dir string) ([]*schema.Unit, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var units []*schema.Unit
+	for _, fi := range files {
+		name := fi.Name()
+		if fi.IsDir() || !unit.RecognizedUnitType(name) {
+			continue
+		}
+		if err := api.ValidateName(name); err != nil {
+			return nil, err
+		}
+
+		uf, err := getUnitFromFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read Unit(%s): %v", name, err)
+		}
+		opts := schema.MapUnitFileToSchemaUnitOptions(uf)
+		if err := api.ValidateOptions(opts); err != nil {
+			return nil, fmt.Errorf("invalid Unit(%s): %v", name, err)
+		}
+
+		su := &schema.Unit{
+			Name:         name,
+			Options:      opts,
+			DesiredState: string(job.JobStateLaunched),
+		}
+		if uni := unit.NewUnitNameInfo(name); uni != nil && uni.IsTemplate() {
+			su.DesiredState = string(job.JobStateInactive)
+		}
+		if replicas, ok := schema.MapSchemaUnitToUnit(su).RequiredReplicas(); ok {
+			su.Replicas = int64(replicas)
+		}
+		units = append(units, su)
+	}
+	return units, nil
+}
+
+// propose adds the proposed units to the snapshot, replacing the units with
+// the same name.
+func (cs *clusterSnapshot) propose(proposed []*schema.Unit) {
+	index := make(map[string]int, len(cs.Units))
+	for i, su := range cs.Units {
+		index[su.Name] = i
+	}
+	for _, su := range proposed {
+		if i, ok := index[su.Name]; ok {
+			cs.Units[i] = su
+			continue
+		}
+		cs.Units = append(cs.Units, su)
+	}
+}
+
+// fakeRegistry returns a FakeRegistry holding the machines and units of the
+// snapshot, along with the schedule and replica count of the units.
+func (cs *clusterSnapshot) fakeRegistry() *registry.FakeRegistry {
+	jobs := make([]job.Job, 0, len(cs.Units))
+	for _, su := range cs.Units {
+		u := schema.MapSchemaUnitToUnit(su)
+		jobs = append(jobs, job.Job{
+			Name:            u.Name,
+			Unit:            u.Unit,
+			TargetState:     job.JobState(su.DesiredState),
+			TargetMachineID: su.MachineID,
+		})
+	}
+
+	reg := registry.NewFakeRegistry()
+	reg.SetMachines(cs.Machines)
+	reg.SetJobs(jobs)
+	for _, su := range cs.Units {
+		if su.Replicas > 0 {
+			reg.ScaleUnit(su.Name, int(su.Replicas))
+		}
+	}
+	return reg
+}
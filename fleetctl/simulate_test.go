@@ -0,0 +1,153 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cea-hpc/fleet/job"
+)
+
+func TestRunSimulate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fleetctl-simulate")
+	if err != nil {
+		t.Fatalf("Failed creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, contents string) string {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed writing %s: %v", file, err)
+		}
+		return file
+	}
+	mkdir := func(name string) string {
+		d := filepath.Join(dir, name)
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatalf("Failed creating %s: %v", d, err)
+		}
+		return d
+	}
+
+	snapshot := filepath.Join(dir, "cluster.json")
+	placeable := mkdir("placeable")
+	writeFile("placeable/web@.service", "[X-Fleet]\nReplicas=3\n")
+	writeFile("placeable/README", "not a unit")
+	unplaceable := mkdir("unplaceable")
+	writeFile("unplaceable/ssd.service", "[X-Fleet]\nMachineMetadata=disk=ssd\n")
+	invalid := mkdir("invalid")
+	writeFile("invalid/bad.service", "[X-Fleet]\nMachineID=AAA\nGlobal=true\n")
+
+	defer func() {
+		flagSimulateSnapshot = ""
+		flagSimulateUnits = ""
+		flagSimulateCapture = ""
+	}()
+
+	// capture the fake cluster first
+	cAPI = newFakeRegistryForCommands("j", 2, false)
+	flagSimulateCapture = snapshot
+	if exit := runSimulate(cmdSimulate, nil); exit != 0 {
+		t.Fatalf("Expected exit code 0 capturing snapshot, got %d", exit)
+	}
+	flagSimulateCapture = ""
+
+	snap, err := readSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed reading captured snapshot: %v", err)
+	}
+	if len(snap.Machines) != 2 || len(snap.Units) != 2 {
+		t.Errorf("Unexpected snapshot with %d machines and %d units", len(snap.Machines), len(snap.Units))
+	}
+
+	for i, tt := range []struct {
+		snapshot string
+		units    string
+		args     []string
+		exit     int
+	}{
+		{snapshot, "", nil, 0},
+		{snapshot, placeable, nil, 0},
+		{snapshot, unplaceable, nil, 1},
+		{snapshot, invalid, nil, 1},
+		{snapshot, filepath.Join(dir, "missing"), nil, 1},
+		{"", placeable, nil, 1},
+		{filepath.Join(dir, "missing.json"), "", nil, 1},
+		{snapshot, "", []string{"foo.service"}, 1},
+	} {
+		flagSimulateSnapshot = tt.snapshot
+		flagSimulateUnits = tt.units
+		if exit := runSimulate(cmdSimulate, tt.args); exit != tt.exit {
+			t.Errorf("case %d: expected exit code %d, got %d", i, tt.exit, exit)
+		}
+	}
+}
+
+func TestClusterSnapshotPropose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fleetctl-simulate")
+	if err != nil {
+		t.Fatalf("Failed creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, contents := range map[string]string{
+		"j1.service":    "[Service]\nExecStart=/bin/true\n",
+		"web@.service":  "[X-Fleet]\nReplicas=2\n",
+		"other.service": "",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed writing %s: %v", name, err)
+		}
+	}
+	proposed, err := readProposedUnits(dir)
+	if err != nil {
+		t.Fatalf("Failed reading proposed units: %v", err)
+	}
+
+	cAPI = newFakeRegistryForCommands("j", 1, false)
+	machines, _ := cAPI.Machines()
+	units, _ := cAPI.Units()
+	snap := &clusterSnapshot{Machines: machines, Units: units}
+	snap.propose(proposed)
+
+	reg := snap.fakeRegistry()
+	jUnits, _ := reg.Units()
+	if len(jUnits) != 3 {
+		t.Fatalf("Expected 3 units, got %d", len(jUnits))
+	}
+	for _, u := range jUnits {
+		switch u.Name {
+		case "j1.service", "other.service":
+			if u.TargetState != job.JobStateLaunched {
+				t.Errorf("Unit %s: expected target state %s, got %s", u.Name, job.JobStateLaunched, u.TargetState)
+			}
+		case "web@.service":
+			if u.TargetState != job.JobStateInactive || u.Replicas != 2 {
+				t.Errorf("Unit %s: unexpected target state %s and %d replicas", u.Name, u.TargetState, u.Replicas)
+			}
+		}
+	}
+	// the replaced j1.service is no longer scheduled
+	sUnits, _ := reg.Schedule()
+	for _, su := range sUnits {
+		if su.TargetMachineID != "" {
+			t.Errorf("Expected no scheduled units, got %v", su)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/schema"
+)
+
+var (
+	flagTopSort  string
+	flagTopLimit int
+
+	// topSortKeys are the resource usages units can be ranked by
+	topSortKeys = map[string]func(us *schema.UnitState) int64{
+		"cpu":    func(us *schema.UnitState) int64 { return us.CpuUsageNSec },
+		"memory": func(us *schema.UnitState) int64 { return us.MemoryCurrent },
+		"tasks":  func(us *schema.UnitState) int64 { return us.TasksCurrent },
+	}
+)
+
+var cmdTop = &cobra.Command{
+	Use:   "top [-l|--full] [--no-legend] [--sort=cpu|memory|tasks] [--limit=N]",
+	Short: "List the units using the most resources across the cluster",
+	Long: `Lists the units using the most resources across the cluster, heaviest first.
+The CPU time consumed by each unit, the memory it uses and the number of tasks
+running in it are accounted by systemd on its machine, and published by the
+agent along with the state of the unit. Usage is only accounted for the units
+with CPUAccounting, MemoryAccounting and TasksAccounting enabled, either in
+their unit file or through the DefaultCPUAccounting, DefaultMemoryAccounting
+and DefaultTasksAccounting settings of systemd; a "-" is shown otherwise.
+
+List the 10 units using the most memory:
+fleetctl top --sort=memory --limit=10`,
+	Run: runWrapper(runTop),
+}
+
+func init() {
+	cmdFleet.AddCommand(cmdTop)
+
+	cmdTop.Flags().BoolVar(&sharedFlags.Full, "full", false, "Do not ellipsize fields on output")
+	cmdTop.Flags().BoolVar(&sharedFlags.Full, "l", false, "Shorthand for --full")
+	cmdTop.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
+	cmdTop.Flags().StringVar(&flagTopSort, "sort", "cpu", "Resource to rank units by: cpu, memory or tasks")
+	cmdTop.Flags().IntVar(&flagTopLimit, "limit", 20, "Maximum number of units to list, 0 to list them all")
+}
+
+func runTop(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 0 {
+		stderr("No arguments expected")
+		return 1
+	}
+	if _, ok := topSortKeys[flagTopSort]; !ok {
+		stderr("Unknown resource %q to sort by, expected cpu, memory or tasks", flagTopSort)
+		return 1
+	}
+	if flagTopLimit < 0 {
+		stderr("Invalid limit %d, expected 0 or more", flagTopLimit)
+		return 1
+	}
+
+	states, err := cAPI.UnitStates()
+	if err != nil {
+		stderr("Error retrieving list of units from repository: %v", err)
+		return 1
+	}
+
+	noLegend, _ := cCmd.Flags().GetBool("no-legend")
+	if !noLegend {
+		fmt.Fprintln(out, "UNIT\tMACHINE\tCPU\tMEMORY\tTASKS")
+	}
+
+	full, _ := cCmd.Flags().GetBool("full")
+	for _, us := range topUnitStates(states, flagTopSort, flagTopLimit) {
+		mach := machineIDLegend(machine.MachineState{ID: us.MachineID}, full)
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\n", us.Name, mach, formatCPUUsage(us.CpuUsageNSec), formatMemoryUsage(us.MemoryCurrent), formatTasksUsage(us.TasksCurrent))
+	}
+	out.Flush()
+
+	return
+}
+
+// topUnitStates returns at most limit UnitStates, or all of them if limit is
+// 0, ranked by decreasing usage of the given resource. Units using the same
+// amount of it are ranked by name.
+func topUnitStates(states []*schema.UnitState, sortBy string, limit int) []*schema.UnitState {
+	key := topSortKeys[sortBy]
+	sorted := make([]*schema.UnitState, 0, len(states))
+	for _, us := range states {
+		if us != nil {
+			sorted = append(sorted, us)
+		}
+	}
+	sort.Sort(byUsage{states: sorted, key: key})
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
+
+// byUsage sorts UnitStates by decreasing usage, then by name
+type byUsage struct {
+	states []*schema.UnitState
+	key    func(us *schema.UnitState) int64
+}
+
+func (b byUsage) Len() int      { return len(b.states) }
+func (b byUsage) Swap(i, j int) { b.states[i], b.states[j] = b.states[j], b.states[i] }
+func (b byUsage) Less(i, j int) bool {
+	if ki, kj := b.key(b.states[i]), b.key(b.states[j]); ki != kj {
+		return ki > kj
+	}
+	return b.states[i].Name < b.states[j].Name
+}
+
+func formatCPUUsage(nsec int64) string {
+	if nsec == 0 {
+		return "-"
+	}
+	return time.Duration(nsec).Round(10 * time.Millisecond).String()
+}
+
+func formatMemoryUsage(bytes int64) string {
+	if bytes == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fM", float64(bytes)/(1<<20))
+}
+
+func formatTasksUsage(tasks int64) string {
+	if tasks == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", tasks)
+}
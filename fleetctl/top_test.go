@@ -0,0 +1,97 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cea-hpc/fleet/schema"
+)
+
+func TestTopUnitStates(t *testing.T) {
+	states := []*schema.UnitState{
+		{Name: "a.service", CpuUsageNSec: 3000000000, MemoryCurrent: 1 << 20, TasksCurrent: 4},
+		{Name: "b.service", CpuUsageNSec: 1000000000, MemoryCurrent: 1 << 30, TasksCurrent: 4},
+		nil,
+		{Name: "c.service"},
+		{Name: "d.service", CpuUsageNSec: 2000000000, MemoryCurrent: 1 << 25, TasksCurrent: 1},
+	}
+
+	for i, tt := range []struct {
+		sortBy string
+		limit  int
+		want   []string
+	}{
+		{"cpu", 0, []string{"a.service", "d.service", "b.service", "c.service"}},
+		{"memory", 0, []string{"b.service", "d.service", "a.service", "c.service"}},
+		// ties are ranked by name
+		{"tasks", 0, []string{"a.service", "b.service", "d.service", "c.service"}},
+		{"memory", 2, []string{"b.service", "d.service"}},
+		{"cpu", 10, []string{"a.service", "d.service", "b.service", "c.service"}},
+	} {
+		var got []string
+		for _, us := range topUnitStates(states, tt.sortBy, tt.limit) {
+			got = append(got, us.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("case %d: expected %v, got %v", i, tt.want, got)
+		}
+	}
+}
+
+func TestRunTop(t *testing.T) {
+	defer func() {
+		flagTopSort = "cpu"
+		flagTopLimit = 20
+	}()
+
+	cAPI = newFakeRegistryForCommands("j", 2, false)
+	for i, tt := range []struct {
+		sortBy string
+		limit  int
+		args   []string
+		exit   int
+	}{
+		{"cpu", 20, nil, 0},
+		{"tasks", 0, nil, 0},
+		{"disk", 20, nil, 1},
+		{"memory", -1, nil, 1},
+		{"cpu", 20, []string{"j1.service"}, 1},
+	} {
+		flagTopSort = tt.sortBy
+		flagTopLimit = tt.limit
+		if exit := runTop(cmdTop, tt.args); exit != tt.exit {
+			t.Errorf("case %d: expected exit code %d, got %d", i, tt.exit, exit)
+		}
+	}
+}
+
+func TestFormatUsage(t *testing.T) {
+	for i, tt := range []struct {
+		got, want string
+	}{
+		{formatCPUUsage(0), "-"},
+		{formatCPUUsage(90504000000), "1m30.5s"},
+		{formatMemoryUsage(0), "-"},
+		{formatMemoryUsage(3 << 19), "1.5M"},
+		{formatTasksUsage(0), "-"},
+		{formatTasksUsage(12), "12"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("case %d: expected %q, got %q", i, tt.want, tt.got)
+		}
+	}
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/cea-hpc/fleet/agent"
 	"github.com/cea-hpc/fleet/config"
+	"github.com/cea-hpc/fleet/engine"
 	"github.com/cea-hpc/fleet/log"
 	"github.com/cea-hpc/fleet/pkg"
 	"github.com/cea-hpc/fleet/registry"
@@ -85,9 +86,25 @@ func Main() {
 	cfgset.String("etcd_key_prefix", registry.DefaultKeyPrefix, "Keyspace for fleet data in etcd")
 	cfgset.Float64("etcd_request_timeout", 1.0, "Amount of time in seconds to allow a single etcd request before considering it failed.")
 	cfgset.Float64("engine_reconcile_interval", 2.0, "Interval at which the engine should reconcile the cluster schedule in etcd.")
+	cfgset.String("engine_scheduler", engine.DefaultScheduler, "Strategy used by the engine to place units: least-loaded, bin-pack, random-spread or round-robin")
+	cfgset.Int("engine_rebalance_threshold", 0, "Difference of weighted load between machines above which the engine moves units to rebalance the cluster, disabled when zero")
+	cfgset.Int("engine_rebalance_max_moves", 1, "Maximum number of units moved by the engine per reconciliation to rebalance the cluster")
+	cfgset.String("engine_reschedule_delay", "0s", "Time units stay scheduled to a machine which went away before the engine reschedules them, unless they set RescheduleDelay")
+	cfgset.Float64("engine_resync_interval", 0, "Interval in seconds at which the engine reads the whole cluster state from etcd, reading only the changed units in between. The whole cluster state is read at every reconciliation when zero.")
+	cfgset.Bool("engine_warm_standby", false, "Keep the cluster state up to date while the engine is not the leader, so that it resumes reconciliation at once when it becomes leader. Requires engine_resync_interval.")
+	cfgset.Int("engine_max_schedules", 0, "Maximum number of units scheduled by the engine per reconciliation, unlimited when zero")
+	cfgset.Int("engine_max_unschedules", 0, "Maximum number of units unscheduled by the engine per reconciliation, unlimited when zero")
+	cfgset.Int("engine_max_schedules_per_machine", 0, "Maximum number of units scheduled by the engine to each machine per reconciliation, unlimited when zero")
+	cfgset.Int("engine_max_unschedules_per_machine", 0, "Maximum number of units unscheduled by the engine from each machine per reconciliation, unlimited when zero")
 	cfgset.String("public_ip", "", "IP address that fleet machine should publish")
 	cfgset.String("metadata", "", "List of key-value metadata to assign to the fleet machine")
+	cfgset.Int("total_cores", 0, "Number of CPU cores the fleet machine offers to units, detected when zero")
+	cfgset.Int("total_memory", 0, "Memory in MB the fleet machine offers to units, detected when zero")
+	cfgset.Int("total_disk", 0, "Disk space in MB the fleet machine offers to units")
+	cfgset.Int("max_units", 0, "Maximum number of units scheduled to the fleet machine, unlimited when zero")
+	cfgset.Int("max_weight", 0, "Maximum total Weight of the units scheduled to the fleet machine, unlimited when zero")
 	cfgset.String("agent_ttl", agent.DefaultTTL, "TTL in seconds of fleet machine state in etcd")
+	cfgset.Int("agent_task_parallelism", 1, "Maximum number of units the agent loads, starts, stops or unloads at once")
 	cfgset.String("units_directory", "/run/fleet/units/", "Path to the fleet units directory")
 	cfgset.Bool("systemd_user", false, "When true use systemd --user)")
 	cfgset.Int("token_limit", 100, "Maximum number of entries per page returned from API requests")
@@ -216,27 +233,43 @@ func getConfig(flagset *flag.FlagSet, userCfgFile string) (*config.Config, error
 	gconf.ParseSet("", flagset)
 
 	cfg := config.Config{
-		Verbosity:               (*flagset.Lookup("verbosity")).Value.(flag.Getter).Get().(int),
-		EtcdServers:             (*flagset.Lookup("etcd_servers")).Value.(flag.Getter).Get().(pkg.StringSlice),
-		EtcdUsername:            (*flagset.Lookup("etcd_username")).Value.(flag.Getter).Get().(string),
-		EtcdPassword:            (*flagset.Lookup("etcd_password")).Value.(flag.Getter).Get().(string),
-		EtcdKeyPrefix:           (*flagset.Lookup("etcd_key_prefix")).Value.(flag.Getter).Get().(string),
-		EtcdKeyFile:             (*flagset.Lookup("etcd_keyfile")).Value.(flag.Getter).Get().(string),
-		EtcdCertFile:            (*flagset.Lookup("etcd_certfile")).Value.(flag.Getter).Get().(string),
-		EtcdCAFile:              (*flagset.Lookup("etcd_cafile")).Value.(flag.Getter).Get().(string),
-		EtcdRequestTimeout:      (*flagset.Lookup("etcd_request_timeout")).Value.(flag.Getter).Get().(float64),
-		EngineReconcileInterval: (*flagset.Lookup("engine_reconcile_interval")).Value.(flag.Getter).Get().(float64),
-		PublicIP:                (*flagset.Lookup("public_ip")).Value.(flag.Getter).Get().(string),
-		RawMetadata:             (*flagset.Lookup("metadata")).Value.(flag.Getter).Get().(string),
-		AgentTTL:                (*flagset.Lookup("agent_ttl")).Value.(flag.Getter).Get().(string),
-		DisableEngine:           (*flagset.Lookup("disable_engine")).Value.(flag.Getter).Get().(bool),
-		DisableWatches:          (*flagset.Lookup("disable_watches")).Value.(flag.Getter).Get().(bool),
-		EnableGRPC:              (*flagset.Lookup("enable_grpc")).Value.(flag.Getter).Get().(bool),
-		VerifyUnits:             (*flagset.Lookup("verify_units")).Value.(flag.Getter).Get().(bool),
-		UnitsDirectory:          (*flagset.Lookup("units_directory")).Value.(flag.Getter).Get().(string),
-		SystemdUser:             (*flagset.Lookup("systemd_user")).Value.(flag.Getter).Get().(bool),
-		TokenLimit:              (*flagset.Lookup("token_limit")).Value.(flag.Getter).Get().(int),
-		AuthorizedKeysFile:      (*flagset.Lookup("authorized_keys_file")).Value.(flag.Getter).Get().(string),
+		Verbosity:                      (*flagset.Lookup("verbosity")).Value.(flag.Getter).Get().(int),
+		EtcdServers:                    (*flagset.Lookup("etcd_servers")).Value.(flag.Getter).Get().(pkg.StringSlice),
+		EtcdUsername:                   (*flagset.Lookup("etcd_username")).Value.(flag.Getter).Get().(string),
+		EtcdPassword:                   (*flagset.Lookup("etcd_password")).Value.(flag.Getter).Get().(string),
+		EtcdKeyPrefix:                  (*flagset.Lookup("etcd_key_prefix")).Value.(flag.Getter).Get().(string),
+		EtcdKeyFile:                    (*flagset.Lookup("etcd_keyfile")).Value.(flag.Getter).Get().(string),
+		EtcdCertFile:                   (*flagset.Lookup("etcd_certfile")).Value.(flag.Getter).Get().(string),
+		EtcdCAFile:                     (*flagset.Lookup("etcd_cafile")).Value.(flag.Getter).Get().(string),
+		EtcdRequestTimeout:             (*flagset.Lookup("etcd_request_timeout")).Value.(flag.Getter).Get().(float64),
+		EngineReconcileInterval:        (*flagset.Lookup("engine_reconcile_interval")).Value.(flag.Getter).Get().(float64),
+		EngineScheduler:                (*flagset.Lookup("engine_scheduler")).Value.(flag.Getter).Get().(string),
+		EngineRebalanceThreshold:       (*flagset.Lookup("engine_rebalance_threshold")).Value.(flag.Getter).Get().(int),
+		EngineRebalanceMaxMoves:        (*flagset.Lookup("engine_rebalance_max_moves")).Value.(flag.Getter).Get().(int),
+		EngineRescheduleDelay:          (*flagset.Lookup("engine_reschedule_delay")).Value.(flag.Getter).Get().(string),
+		EngineResyncInterval:           (*flagset.Lookup("engine_resync_interval")).Value.(flag.Getter).Get().(float64),
+		EngineWarmStandby:              (*flagset.Lookup("engine_warm_standby")).Value.(flag.Getter).Get().(bool),
+		EngineMaxSchedules:             (*flagset.Lookup("engine_max_schedules")).Value.(flag.Getter).Get().(int),
+		EngineMaxUnschedules:           (*flagset.Lookup("engine_max_unschedules")).Value.(flag.Getter).Get().(int),
+		EngineMaxSchedulesPerMachine:   (*flagset.Lookup("engine_max_schedules_per_machine")).Value.(flag.Getter).Get().(int),
+		EngineMaxUnschedulesPerMachine: (*flagset.Lookup("engine_max_unschedules_per_machine")).Value.(flag.Getter).Get().(int),
+		PublicIP:                       (*flagset.Lookup("public_ip")).Value.(flag.Getter).Get().(string),
+		RawMetadata:                    (*flagset.Lookup("metadata")).Value.(flag.Getter).Get().(string),
+		TotalCores:                     (*flagset.Lookup("total_cores")).Value.(flag.Getter).Get().(int),
+		TotalMemory:                    (*flagset.Lookup("total_memory")).Value.(flag.Getter).Get().(int),
+		TotalDisk:                      (*flagset.Lookup("total_disk")).Value.(flag.Getter).Get().(int),
+		MaxUnits:                       (*flagset.Lookup("max_units")).Value.(flag.Getter).Get().(int),
+		MaxWeight:                      (*flagset.Lookup("max_weight")).Value.(flag.Getter).Get().(int),
+		AgentTTL:                       (*flagset.Lookup("agent_ttl")).Value.(flag.Getter).Get().(string),
+		AgentTaskParallelism:           (*flagset.Lookup("agent_task_parallelism")).Value.(flag.Getter).Get().(int),
+		DisableEngine:                  (*flagset.Lookup("disable_engine")).Value.(flag.Getter).Get().(bool),
+		DisableWatches:                 (*flagset.Lookup("disable_watches")).Value.(flag.Getter).Get().(bool),
+		EnableGRPC:                     (*flagset.Lookup("enable_grpc")).Value.(flag.Getter).Get().(bool),
+		VerifyUnits:                    (*flagset.Lookup("verify_units")).Value.(flag.Getter).Get().(bool),
+		UnitsDirectory:                 (*flagset.Lookup("units_directory")).Value.(flag.Getter).Get().(string),
+		SystemdUser:                    (*flagset.Lookup("systemd_user")).Value.(flag.Getter).Get().(bool),
+		TokenLimit:                     (*flagset.Lookup("token_limit")).Value.(flag.Getter).Get().(int),
+		AuthorizedKeysFile:             (*flagset.Lookup("authorized_keys_file")).Value.(flag.Getter).Get().(string),
 	}
 
 	if cfg.VerifyUnits {
@@ -16,10 +16,18 @@ package job
 
 import (
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/coreos/go-semver/semver"
+
+	"github.com/cea-hpc/fleet/machine"
 	"github.com/cea-hpc/fleet/pkg"
+	"github.com/cea-hpc/fleet/resource"
 	"github.com/cea-hpc/fleet/unit"
 )
 
@@ -56,6 +64,53 @@ const (
 	fleetGlobal = "Global"
 	// Unit weight
 	fleetWeight = "Weight"
+	// Number of CPU cores reserved for the unit, fractions allowed
+	fleetReserveCores = "ReserveCores"
+	// Memory reserved for the unit, in MB
+	fleetReserveMemory = "ReserveMemory"
+	// Disk space reserved for the unit, in MB
+	fleetReserveDisk = "ReserveDisk"
+	// Machine metadata key across which the units of a group are spread
+	fleetSpreadBy = "SpreadBy"
+	// Name of the group of units spread together, defaults to the template name
+	fleetSpreadGroup = "SpreadGroup"
+	// Favour machines with this specific metadata
+	fleetPreferMachineMetadata = "PreferMachineMetadata"
+	// Favour machines not hosting units matching these glob patterns
+	fleetPreferNotColocatedWith = "PreferNotColocatedWith"
+	// Priority of the unit, allowing it to preempt units of lower priority
+	fleetPriority = "Priority"
+	// Allow the engine to move the unit to another machine when rebalancing
+	fleetMovable = "Movable"
+	// Time a unit stays scheduled to a lost machine before being rescheduled
+	fleetRescheduleDelay = "RescheduleDelay"
+	// Number of instances the engine keeps of a template unit
+	fleetReplicas = "Replicas"
+	// Name of a group of units scheduled all at once or not at all
+	fleetGang = "Gang"
+	// Number of units a gang is made of
+	fleetGangSize = "GangSize"
+	// Units which must be active somewhere in the cluster before the unit is started
+	fleetStartAfter = "StartAfter"
+	// Capabilities the machine must advertise
+	fleetMachineCapabilities = "MachineCapabilities"
+	// Lowest fleet version the machine must run
+	fleetMinFleetVersion = "MinFleetVersion"
+	// Check run by the agent to determine whether the unit works
+	fleetHealthCheck = "HealthCheck"
+	// Time between two health checks
+	fleetHealthCheckInterval = "HealthCheckInterval"
+	// Number of consecutive failed health checks after which the unit is unhealthy
+	fleetHealthCheckThreshold = "HealthCheckThreshold"
+	// Number of failures after which the unit is moved away from its machine
+	fleetMaxFailures = "MaxFailures"
+	// Time within which failures of the unit are counted
+	fleetFailureWindow = "FailureWindow"
+
+	// preferenceWeightPrefix introduces the weight of a soft preference
+	preferenceWeightPrefix = "weight="
+	// defaultPreferenceWeight is used for preferences without explicit weight
+	defaultPreferenceWeight = 1
 
 	deprecatedXPrefix          = "X-"
 	deprecatedXConditionPrefix = "X-Condition"
@@ -75,6 +130,27 @@ var validRequirements = pkg.NewUnsafeSet(
 	fleetGlobal,
 	fleetReplaces,
 	fleetWeight,
+	fleetReserveCores,
+	fleetReserveMemory,
+	fleetReserveDisk,
+	fleetSpreadBy,
+	fleetSpreadGroup,
+	fleetPreferMachineMetadata,
+	fleetPreferNotColocatedWith,
+	fleetPriority,
+	fleetMovable,
+	fleetRescheduleDelay,
+	fleetReplicas,
+	fleetGang,
+	fleetGangSize,
+	fleetStartAfter,
+	fleetMachineCapabilities,
+	fleetMinFleetVersion,
+	fleetHealthCheck,
+	fleetHealthCheckInterval,
+	fleetHealthCheckThreshold,
+	fleetMaxFailures,
+	fleetFailureWindow,
 )
 
 func ParseJobState(s string) (JobState, error) {
@@ -89,6 +165,13 @@ func ParseJobState(s string) (JobState, error) {
 	return js, err
 }
 
+// Preference is a soft placement requirement: machines satisfying any of
+// its Values are favoured by its Weight, while other machines stay eligible.
+type Preference struct {
+	Values []string
+	Weight int
+}
+
 // Job is a legacy construct encapsulating a scheduled unit in fleet
 type Job struct {
 	Name            string
@@ -96,6 +179,10 @@ type Job struct {
 	TargetState     JobState
 	TargetMachineID string
 	Unit            unit.UnitFile
+
+	// ExcludedMachines holds the IDs of the machines the engine keeps
+	// the Job away from for now, after it failed too often on them
+	ExcludedMachines []string
 }
 
 // ScheduledUnit represents a Unit known by fleet and encapsulates its current scheduling state. This does not include Global units.
@@ -112,6 +199,11 @@ type Unit struct {
 	Unit        unit.UnitFile
 	TargetState JobState
 	Weight      uint16
+
+	// Replicas is the number of instances the engine keeps of a
+	// replicated template unit. It starts from the Replicas requirement
+	// of the template and changes as the template is scaled.
+	Replicas int
 }
 
 // IsGlobal returns whether a Unit is considered a global unit
@@ -183,6 +275,91 @@ func (u *Unit) RequiredTargetMetadata() map[string]pkg.Set {
 	return j.RequiredTargetMetadata()
 }
 
+func (u *Unit) MetadataRequirement() machine.MetadataRequirement {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.MetadataRequirement()
+}
+
+func (u *Unit) Resources() resource.ResourceTuple {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.Resources()
+}
+
+// EffectiveWeight returns the Weight of the Unit, read from its unit file
+// unless the Unit already carries it.
+func (u *Unit) EffectiveWeight() int {
+	if u.Weight != 0 {
+		return int(u.Weight)
+	}
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return int(j.Weight())
+}
+
+func (u *Unit) SpreadGroup() string {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.SpreadGroup()
+}
+
+func (u *Unit) Priority() int {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.Priority()
+}
+
+func (u *Unit) RequiredReplicas() (int, bool) {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.RequiredReplicas()
+}
+
+func (u *Unit) RequiredCapabilities() []string {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.RequiredCapabilities()
+}
+
+func (u *Unit) HealthCheck() (*HealthCheck, bool) {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.HealthCheck()
+}
+
+func (u *Unit) MinFleetVersion() (*semver.Version, bool) {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.MinFleetVersion()
+}
+
+func (u *Unit) StartAfter() []string {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.StartAfter()
+}
+
 // requirements returns all relevant options from the [X-Fleet] section of a unit file.
 // Relevant options are identified with a `X-` prefix in the unit.
 // This prefix is stripped from relevant options before being returned.
@@ -260,6 +437,344 @@ func (j *Job) Weight() uint16 {
 	}
 }
 
+// Resources returns the CPU, memory and disk space reserved by the Job
+// through its ReserveCores, ReserveMemory and ReserveDisk requirements.
+// Invalid values are ignored; use ValidateResources to detect them.
+func (j *Job) Resources() resource.ResourceTuple {
+	res, _ := j.resources()
+	return res
+}
+
+// ValidateResources ensures that the resource reservations in the [X-Fleet]
+// section of the job's associated unit file can be parsed. If not, an error
+// is returned.
+func (j *Job) ValidateResources() error {
+	_, err := j.resources()
+	return err
+}
+
+func (j *Job) resources() (res resource.ResourceTuple, err error) {
+	requirements := j.requirements()
+
+	// Last value found wins
+	if values := requirements[fleetReserveCores]; len(values) != 0 {
+		last := values[len(values)-1]
+		cores, perr := strconv.ParseFloat(last, 64)
+		// the hundredths of cores must fit in 31 bits, like megabytes
+		if perr != nil || math.IsNaN(cores) || cores < 0 || cores*100 > math.MaxInt32 {
+			err = fmt.Errorf("invalid value %q for %s", last, fleetReserveCores)
+		} else {
+			res.Cores = int(math.Round(cores * 100))
+		}
+	}
+
+	for _, mbReq := range []struct {
+		key  string
+		dest *int
+	}{
+		{fleetReserveMemory, &res.Memory},
+		{fleetReserveDisk, &res.Disk},
+	} {
+		key := mbReq.key
+		values := requirements[key]
+		if len(values) == 0 {
+			continue
+		}
+		last := values[len(values)-1]
+		mb, perr := strconv.ParseUint(last, 10, 31)
+		if perr != nil {
+			err = fmt.Errorf("invalid value %q for %s", last, key)
+			continue
+		}
+		*mbReq.dest = int(mb)
+	}
+
+	return
+}
+
+// Priority returns the scheduling priority of the Job, 0 if none is set.
+// When no machine is able to run a Job, units of strictly lower priority
+// may be unscheduled to make room for it. Invalid values are ignored; use
+// ValidatePriority to detect them.
+func (j *Job) Priority() int {
+	prio, _ := j.priority()
+	return prio
+}
+
+// ValidatePriority ensures that the Priority in the [X-Fleet] section of
+// the job's associated unit file is a valid integer. If not, an error is
+// returned.
+func (j *Job) ValidatePriority() error {
+	_, err := j.priority()
+	return err
+}
+
+func (j *Job) priority() (int, error) {
+	values := j.requirements()[fleetPriority]
+	if len(values) == 0 {
+		return 0, nil
+	}
+	// Last value found wins
+	last := values[len(values)-1]
+	prio, err := strconv.ParseInt(last, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q for %s", last, fleetPriority)
+	}
+	return int(prio), nil
+}
+
+// Movable determines whether the engine may move the Job to another machine
+// to rebalance the load of the cluster. Jobs are movable unless their
+// Movable requirement is set to a false value.
+func (j *Job) Movable() bool {
+	values := j.requirements()[fleetMovable]
+	if len(values) == 0 {
+		return true
+	}
+	// Last value found wins
+	last := values[len(values)-1]
+	return !isFalsyValue(last)
+}
+
+// RescheduleDelay returns how long the Job should stay scheduled to a
+// machine which went away before the engine moves it elsewhere. If no such
+// requirement exists, or if it is invalid, a zero duration along with a bool
+// false will be returned.
+func (j *Job) RescheduleDelay() (time.Duration, bool) {
+	delay, ok, err := j.rescheduleDelay()
+	if err != nil {
+		return 0, false
+	}
+	return delay, ok
+}
+
+// ValidateRescheduleDelay ensures that the RescheduleDelay in the [X-Fleet]
+// section of the job's associated unit file is a non-negative duration. If
+// not, an error is returned.
+func (j *Job) ValidateRescheduleDelay() error {
+	_, _, err := j.rescheduleDelay()
+	return err
+}
+
+// rescheduleDelay parses the RescheduleDelay requirement, either a duration
+// such as "90s" or "5m", or a plain number of seconds.
+func (j *Job) rescheduleDelay() (time.Duration, bool, error) {
+	values := j.requirements()[fleetRescheduleDelay]
+	if len(values) == 0 {
+		return 0, false, nil
+	}
+	// Last value found wins
+	last := values[len(values)-1]
+	delay, err := parseDuration(last)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid value %q for %s", last, fleetRescheduleDelay)
+	}
+	if delay < 0 {
+		return 0, false, fmt.Errorf("invalid value %q for %s: must not be negative", last, fleetRescheduleDelay)
+	}
+	return delay, true, nil
+}
+
+// RequiredReplicas returns the number of instances the engine should keep
+// of the Job, which must be a template unit, as set by its Replicas
+// requirement. If the Job is not a template, or if no valid requirement
+// exists, zero along with a bool false will be returned.
+func (j *Job) RequiredReplicas() (int, bool) {
+	if uni := unit.NewUnitNameInfo(j.Name); uni == nil || !uni.IsTemplate() {
+		return 0, false
+	}
+	replicas, ok, err := j.replicas()
+	if err != nil {
+		return 0, false
+	}
+	return replicas, ok
+}
+
+// ValidateReplicas ensures that the Replicas in the [X-Fleet] section of
+// the job's associated unit file is a non-negative integer. If not, an
+// error is returned.
+func (j *Job) ValidateReplicas() error {
+	_, _, err := j.replicas()
+	return err
+}
+
+func (j *Job) replicas() (int, bool, error) {
+	values := j.requirements()[fleetReplicas]
+	if len(values) == 0 {
+		return 0, false, nil
+	}
+	// Last value found wins
+	last := values[len(values)-1]
+	replicas, err := strconv.ParseUint(last, 10, 16)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid value %q for %s", last, fleetReplicas)
+	}
+	return int(replicas), true, nil
+}
+
+// Gang returns the name of the group of units the Job is scheduled with,
+// all at once or not at all. An empty string is returned for Jobs belonging
+// to no gang.
+func (j *Job) Gang() string {
+	values := j.requirements()[fleetGang]
+	if len(values) == 0 {
+		return ""
+	}
+	// Last value found wins
+	return values[len(values)-1]
+}
+
+// GangSize returns the number of units the gang of the Job is made of, as
+// set by its GangSize requirement. Zero is returned if no valid requirement
+// exists.
+func (j *Job) GangSize() int {
+	size, _ := j.gangSize()
+	return size
+}
+
+// ValidateGang ensures that the GangSize in the [X-Fleet] section of the
+// job's associated unit file is a positive integer, and that it is only
+// used along with a Gang. If not, an error is returned.
+func (j *Job) ValidateGang() error {
+	size, err := j.gangSize()
+	if err != nil {
+		return err
+	}
+	if size != 0 && j.Gang() == "" {
+		return fmt.Errorf("%s cannot be used without %s", fleetGangSize, fleetGang)
+	}
+	return nil
+}
+
+func (j *Job) gangSize() (int, error) {
+	values := j.requirements()[fleetGangSize]
+	if len(values) == 0 {
+		return 0, nil
+	}
+	// Last value found wins
+	last := values[len(values)-1]
+	size, err := strconv.ParseUint(last, 10, 16)
+	if err != nil || size == 0 {
+		return 0, fmt.Errorf("invalid value %q for %s", last, fleetGangSize)
+	}
+	return int(size), nil
+}
+
+// StartAfter returns the names of the Units which must be reported active
+// somewhere in the cluster before the Job is started.
+func (j *Job) StartAfter() []string {
+	return splitCombine(j.requirements()[fleetStartAfter])
+}
+
+// ValidateStartAfter ensures that the StartAfter values in the [X-Fleet]
+// section of the job's associated unit file are unit names other than the
+// Job's own. If not, an error is returned.
+func (j *Job) ValidateStartAfter() error {
+	for _, name := range j.StartAfter() {
+		if !unit.RecognizedUnitType(name) {
+			return fmt.Errorf("invalid value %q for %s: not a unit name", name, fleetStartAfter)
+		}
+		if name == j.Name {
+			return fmt.Errorf("invalid value %q for %s: a unit cannot start after itself", name, fleetStartAfter)
+		}
+	}
+	return nil
+}
+
+// SpreadBy returns the machine metadata key across whose distinct values
+// the Job and the other members of its spread group should be evenly
+// distributed. If no such requirement exists, an empty string along with a
+// bool false will be returned.
+func (j *Job) SpreadBy() (string, bool) {
+	values := j.requirements()[fleetSpreadBy]
+	if len(values) == 0 {
+		return "", false
+	}
+	// Last value found wins
+	key := values[len(values)-1]
+	return key, len(key) != 0
+}
+
+// SpreadGroup returns the name of the group of units the Job is spread
+// with. An explicit SpreadGroup requirement takes precedence; otherwise
+// instances of a template unit form a group named after the template.
+// An empty string is returned for Jobs belonging to no group.
+func (j *Job) SpreadGroup() string {
+	if values := j.requirements()[fleetSpreadGroup]; len(values) != 0 {
+		return values[len(values)-1]
+	}
+
+	if uni := unit.NewUnitNameInfo(j.Name); uni != nil && uni.IsInstance() {
+		return uni.Template
+	}
+
+	return ""
+}
+
+// PreferredMachineMetadata returns the soft metadata requirements of the
+// Job. Each Preference holds metadata terms as parsed by
+// machine.ParseMetadataTerm, any of which a machine must satisfy to earn the
+// Preference's weight.
+func (j *Job) PreferredMachineMetadata() []Preference {
+	prefs, _ := j.preferences(fleetPreferMachineMetadata)
+	return prefs
+}
+
+// PreferredNotColocatedWith returns the soft anti-affinity requirements of
+// the Job. Each Preference holds glob patterns of unit names; a machine
+// earns the Preference's weight unless it hosts a unit matching any of them.
+func (j *Job) PreferredNotColocatedWith() []Preference {
+	prefs, _ := j.preferences(fleetPreferNotColocatedWith)
+	return prefs
+}
+
+// ValidatePreferences ensures that the soft placement requirements in the
+// [X-Fleet] section of the job's associated unit file are well-formed. If
+// not, an error is returned.
+func (j *Job) ValidatePreferences() error {
+	prefs, err := j.preferences(fleetPreferMachineMetadata)
+	if err != nil {
+		return err
+	}
+	for _, pref := range prefs {
+		for _, expr := range pref.Values {
+			if _, err := machine.ParseMetadataTerm(expr); err != nil {
+				return fmt.Errorf("invalid value for %s: %v", fleetPreferMachineMetadata, err)
+			}
+		}
+	}
+
+	_, err = j.preferences(fleetPreferNotColocatedWith)
+	return err
+}
+
+// preferences parses every value of the given requirement into a
+// Preference. Values are whitespace-separated terms, with an optional
+// `weight=N` term giving the positive weight of the Preference.
+func (j *Job) preferences(key string) (prefs []Preference, err error) {
+	for _, value := range j.requirements()[key] {
+		pref := Preference{Weight: defaultPreferenceWeight}
+		for _, term := range strings.Fields(value) {
+			if !strings.HasPrefix(term, preferenceWeightPrefix) {
+				pref.Values = append(pref.Values, term)
+				continue
+			}
+			weight, perr := strconv.ParseUint(strings.TrimPrefix(term, preferenceWeightPrefix), 10, 16)
+			if perr != nil || weight == 0 {
+				err = fmt.Errorf("invalid weight %q for %s", term, key)
+				continue
+			}
+			pref.Weight = int(weight)
+		}
+		if len(pref.Values) == 0 {
+			err = fmt.Errorf("no value given for %s", key)
+			continue
+		}
+		prefs = append(prefs, pref)
+	}
+	return
+}
+
 // Peers returns a list of Job names that must be scheduled to the same
 // machine as this Job.
 func (j *Job) Peers() []string {
@@ -305,7 +820,8 @@ func (j *Job) RequiredTarget() (string, bool) {
 
 // RequiredTargetMetadata return all machine-related metadata from a Job's
 // requirements. Valid metadata fields are strings of the form `key=value`,
-// where both key and value are not the empty string.
+// where both key and value are not the empty string. Richer expressions are
+// only returned by MetadataRequirement.
 func (j *Job) RequiredTargetMetadata() map[string]pkg.Set {
 	metadata := make(map[string]pkg.Set)
 
@@ -334,6 +850,259 @@ func (j *Job) RequiredTargetMetadata() map[string]pkg.Set {
 	return metadata
 }
 
+// MetadataRequirement returns the machine metadata requirement of the Job.
+// Every MachineMetadata value is a clause of alternative terms separated by
+// "|", and all clauses must be satisfied. Values made of a single `key=value`
+// term are merged per key, so that several of them accept any of the values
+// as they always did. Invalid values are ignored; use ValidateMetadata to
+// detect them.
+func (j *Job) MetadataRequirement() machine.MetadataRequirement {
+	req, _ := j.metadataRequirement()
+	return req
+}
+
+// RequiredCapabilities returns the capabilities, such as GRPC, which a
+// machine must advertise to run the Job.
+func (j *Job) RequiredCapabilities() []string {
+	return splitCombine(j.requirements()[fleetMachineCapabilities])
+}
+
+// MinFleetVersion returns the lowest version of fleet a machine must run to
+// be eligible for the Job. If no valid requirement exists, nil along with a
+// bool false will be returned.
+func (j *Job) MinFleetVersion() (*semver.Version, bool) {
+	min, err := j.minFleetVersion()
+	if err != nil || min == nil {
+		return nil, false
+	}
+	return min, true
+}
+
+// ValidateMinFleetVersion ensures that the MinFleetVersion in the [X-Fleet]
+// section of the job's associated unit file is a semantic version. If not,
+// an error is returned.
+func (j *Job) ValidateMinFleetVersion() error {
+	_, err := j.minFleetVersion()
+	return err
+}
+
+func (j *Job) minFleetVersion() (*semver.Version, error) {
+	values := j.requirements()[fleetMinFleetVersion]
+	if len(values) == 0 {
+		return nil, nil
+	}
+	// Last value found wins
+	last := values[len(values)-1]
+	min, err := semver.NewVersion(strings.TrimPrefix(last, "v"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q for %s: %v", last, fleetMinFleetVersion, err)
+	}
+	return min, nil
+}
+
+// Types of health checks
+const (
+	// HealthCheckExec runs a command, healthy when it exits successfully
+	HealthCheckExec = "exec"
+	// HealthCheckHTTP gets a URL, healthy when the response status is
+	// below 400
+	HealthCheckHTTP = "http"
+	// HealthCheckTCP dials an address, healthy when the connection is
+	// accepted
+	HealthCheckTCP = "tcp"
+
+	DefaultHealthCheckInterval  = 10 * time.Second
+	DefaultHealthCheckThreshold = 3
+)
+
+// HealthCheck describes how the agent running a Job determines whether it
+// actually works.
+type HealthCheck struct {
+	// Type is one of HealthCheckExec, HealthCheckHTTP and HealthCheckTCP
+	Type string
+	// Target is the command to run, the URL to get or the address to dial
+	Target string
+	// Interval is the time between two checks, each of them timing out
+	// after this time as well
+	Interval time.Duration
+	// Threshold is the number of consecutive failed checks after which
+	// the Job is considered unhealthy
+	Threshold int
+	// User and Group are the User and Group the service of the Job runs
+	// as, empty if it runs as root; exec checks run with them as well
+	User  string
+	Group string
+}
+
+// HealthCheck returns the health check of the Job, made of the type and
+// target of its HealthCheck requirement, e.g. "http http://localhost/", and
+// of its HealthCheckInterval and HealthCheckThreshold requirements. If no
+// valid requirement exists, nil along with a bool false will be returned.
+func (j *Job) HealthCheck() (*HealthCheck, bool) {
+	hc, err := j.healthCheck()
+	if err != nil || hc == nil {
+		return nil, false
+	}
+	return hc, true
+}
+
+// ValidateHealthCheck ensures that the health check requirements in the
+// [X-Fleet] section of the job's associated unit file are valid. If not, an
+// error is returned.
+func (j *Job) ValidateHealthCheck() error {
+	_, err := j.healthCheck()
+	return err
+}
+
+func (j *Job) healthCheck() (*HealthCheck, error) {
+	reqs := j.requirements()
+	hc := HealthCheck{
+		Interval:  DefaultHealthCheckInterval,
+		Threshold: DefaultHealthCheckThreshold,
+	}
+
+	// Last value found wins
+	if values := reqs[fleetHealthCheckInterval]; len(values) > 0 {
+		last := values[len(values)-1]
+		interval, err := parseDuration(last)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid value %q for %s: must be a positive duration", last, fleetHealthCheckInterval)
+		}
+		hc.Interval = interval
+	}
+	if values := reqs[fleetHealthCheckThreshold]; len(values) > 0 {
+		last := values[len(values)-1]
+		threshold, err := strconv.ParseUint(last, 10, 31)
+		if err != nil || threshold == 0 {
+			return nil, fmt.Errorf("invalid value %q for %s: must be a positive integer", last, fleetHealthCheckThreshold)
+		}
+		hc.Threshold = int(threshold)
+	}
+
+	values := reqs[fleetHealthCheck]
+	if len(values) == 0 {
+		return nil, nil
+	}
+	last := values[len(values)-1]
+	fields := strings.SplitN(strings.TrimSpace(last), " ", 2)
+	if len(fields) != 2 || strings.TrimSpace(fields[1]) == "" {
+		return nil, fmt.Errorf("invalid value %q for %s: must be a type followed by a target", last, fleetHealthCheck)
+	}
+	hc.Type, hc.Target = fields[0], strings.TrimSpace(fields[1])
+
+	// Last value found wins
+	service := j.Unit.Contents["Service"]
+	if values := service["User"]; len(values) > 0 {
+		hc.User = values[len(values)-1]
+	}
+	if values := service["Group"]; len(values) > 0 {
+		hc.Group = values[len(values)-1]
+	}
+
+	switch hc.Type {
+	case HealthCheckExec:
+	case HealthCheckHTTP:
+		u, err := url.Parse(hc.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid value %q for %s: not an HTTP URL", last, fleetHealthCheck)
+		}
+	case HealthCheckTCP:
+		if _, _, err := net.SplitHostPort(hc.Target); err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s: %v", last, fleetHealthCheck, err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid value %q for %s: unknown type %q", last, fleetHealthCheck, hc.Type)
+	}
+	return &hc, nil
+}
+
+// DefaultFailureWindow is the time within which failures of a Job are
+// counted when its FailureWindow requirement is not set.
+const DefaultFailureWindow = 10 * time.Minute
+
+// MaxFailures returns the number of times the Job may fail on a machine
+// within the returned window before being moved to another machine, as
+// defined by its MaxFailures and FailureWindow requirements. If no valid
+// MaxFailures requirement exists, a bool false will be returned.
+func (j *Job) MaxFailures() (int, time.Duration, bool) {
+	max, window, err := j.maxFailures()
+	if err != nil || max == 0 {
+		return 0, 0, false
+	}
+	return max, window, true
+}
+
+// ValidateMaxFailures ensures that the MaxFailures in the [X-Fleet] section
+// of the job's associated unit file is a positive integer, and that its
+// FailureWindow is a positive duration. If not, an error is returned.
+func (j *Job) ValidateMaxFailures() error {
+	_, _, err := j.maxFailures()
+	return err
+}
+
+func (j *Job) maxFailures() (int, time.Duration, error) {
+	reqs := j.requirements()
+	window := DefaultFailureWindow
+
+	// Last value found wins
+	if values := reqs[fleetFailureWindow]; len(values) > 0 {
+		last := values[len(values)-1]
+		d, err := parseDuration(last)
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("invalid value %q for %s: must be a positive duration", last, fleetFailureWindow)
+		}
+		window = d
+	}
+
+	values := reqs[fleetMaxFailures]
+	if len(values) == 0 {
+		return 0, 0, nil
+	}
+	last := values[len(values)-1]
+	max, err := strconv.ParseUint(last, 10, 31)
+	if err != nil || max == 0 {
+		return 0, 0, fmt.Errorf("invalid value %q for %s: must be a positive integer", last, fleetMaxFailures)
+	}
+	return int(max), window, nil
+}
+
+// ValidateMetadata ensures that the MachineMetadata expressions in the
+// [X-Fleet] section of the job's associated unit file can be parsed. If not,
+// an error is returned.
+func (j *Job) ValidateMetadata() error {
+	_, err := j.metadataRequirement()
+	return err
+}
+
+func (j *Job) metadataRequirement() (req machine.MetadataRequirement, err error) {
+	// index in req of the clause holding the equality terms of each key
+	equalities := make(map[string]int)
+
+	for _, key := range []string{
+		deprecatedXConditionPrefix + fleetMachineMetadata,
+		fleetMachineMetadata,
+	} {
+		for _, expr := range j.requirements()[key] {
+			clause, perr := machine.ParseMetadataClause(expr)
+			if perr != nil {
+				err = perr
+				continue
+			}
+
+			if len(clause) == 1 && clause[0].Op == machine.MetadataOpEqual {
+				if idx, ok := equalities[clause[0].Key]; ok {
+					req[idx] = append(req[idx], clause[0])
+					continue
+				}
+				equalities[clause[0].Key] = len(req)
+			}
+			req = append(req, clause)
+		}
+	}
+
+	return
+}
+
 func (j *Job) Scheduled() bool {
 	return len(j.TargetMachineID) > 0
 }
@@ -353,6 +1122,20 @@ func unitPrintf(s string, nu unit.UnitNameInfo) (out string) {
 	return
 }
 
+// parseDuration parses a duration such as "90s" or "5m", or a plain number
+// of seconds.
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		secs, serr := strconv.ParseUint(s, 10, 32)
+		if serr != nil {
+			return 0, err
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	return d, nil
+}
+
 // isTruthyValue returns true if a given string is any of "truthy" value,
 // i.e. "true", "yes", "1", "on", or "t".
 func isTruthyValue(s string) bool {
@@ -360,6 +1143,11 @@ func isTruthyValue(s string) bool {
 	return chl == "true" || chl == "yes" || chl == "1" || chl == "on" || chl == "t"
 }
 
+func isFalsyValue(s string) bool {
+	chl := strings.ToLower(s)
+	return chl == "false" || chl == "no" || chl == "0" || chl == "off" || chl == "f"
+}
+
 // splitCombine retrieves each word from an input string slice, to put each
 // one again into a single slice.
 func splitCombine(inStrs []string) []string {
@@ -23,6 +23,7 @@ func (u *Unit) ToPB() pb.Unit {
 		Name:         u.Name,
 		Unit:         u.Unit.ToPB(),
 		DesiredState: u.TargetState.ToPB(),
+		Replicas:     int32(u.Replicas),
 	}
 }
 
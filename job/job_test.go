@@ -16,10 +16,14 @@ package job
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/cea-hpc/fleet/pkg"
+	"github.com/cea-hpc/fleet/resource"
 	"github.com/cea-hpc/fleet/unit"
 )
 
@@ -606,6 +610,15 @@ func TestValidateRequirements(t *testing.T) {
 		"MachineMetadata=true=false",
 		"Global=true",
 		"Replaces=foo",
+		"ReserveCores=1.5",
+		"ReserveMemory=512",
+		"ReserveDisk=1024",
+		"SpreadBy=rack",
+		"SpreadGroup=web",
+		"PreferMachineMetadata=disk=ssd",
+		"PreferNotColocatedWith=db.service",
+		"HealthCheck=tcp localhost:22",
+		"MaxFailures=3",
 	}
 	for i, req := range tests {
 		contents := fmt.Sprintf("[X-Fleet]\n%s", req)
@@ -636,3 +649,646 @@ func TestBadValidateRequirements(t *testing.T) {
 		}
 	}
 }
+
+func TestJobResources(t *testing.T) {
+	tests := []struct {
+		contents string
+		want     resource.ResourceTuple
+		valid    bool
+	}{
+		// no reservations at all
+		{``, resource.ResourceTuple{}, true},
+		// every kind of reservation
+		{`[X-Fleet]
+ReserveCores=2
+ReserveMemory=1024
+ReserveDisk=4096
+`, resource.ResourceTuple{Cores: 200, Memory: 1024, Disk: 4096}, true},
+		// fractions of cores are allowed
+		{`[X-Fleet]
+ReserveCores=0.25
+`, resource.ResourceTuple{Cores: 25}, true},
+		// fractions not exactly representable are rounded, not truncated
+		{`[X-Fleet]
+ReserveCores=0.29
+`, resource.ResourceTuple{Cores: 29}, true},
+		// last value wins
+		{`[X-Fleet]
+ReserveMemory=128
+ReserveMemory=256
+`, resource.ResourceTuple{Memory: 256}, true},
+		// invalid values are ignored but reported
+		{`[X-Fleet]
+ReserveCores=-1
+ReserveMemory=512
+`, resource.ResourceTuple{Memory: 512}, false},
+		{`[X-Fleet]
+ReserveMemory=1G
+`, resource.ResourceTuple{}, false},
+		{`[X-Fleet]
+ReserveDisk=-10
+`, resource.ResourceTuple{}, false},
+		{`[X-Fleet]
+ReserveCores=NaN
+`, resource.ResourceTuple{}, false},
+		{`[X-Fleet]
+ReserveCores=Inf
+`, resource.ResourceTuple{}, false},
+		{`[X-Fleet]
+ReserveCores=-Inf
+`, resource.ResourceTuple{}, false},
+		{`[X-Fleet]
+ReserveCores=1e300
+`, resource.ResourceTuple{}, false},
+		{`[X-Fleet]
+ReserveCores=21474837
+`, resource.ResourceTuple{}, false},
+		// the largest reservation
+		{`[X-Fleet]
+ReserveCores=21474836.47
+`, resource.ResourceTuple{Cores: math.MaxInt32}, true},
+	}
+	for i, tt := range tests {
+		j := NewJob("echo.service", *newUnit(t, tt.contents))
+		if got := j.Resources(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("case %d: unexpected resources: got %#v, want %#v", i, got, tt.want)
+		}
+		err := j.ValidateResources()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
+
+func TestJobSpread(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		key      string
+		spread   bool
+		group    string
+	}{
+		// no requirements, no group
+		{"foo.service", ``, "", false, ""},
+		// instances are grouped by template
+		{"web@1.service", `[X-Fleet]
+SpreadBy=rack
+`, "rack", true, "web@.service"},
+		// explicit groups take precedence
+		{"web@1.service", `[X-Fleet]
+SpreadBy=rack
+SpreadGroup=frontend
+`, "rack", true, "frontend"},
+		{"api.service", `[X-Fleet]
+SpreadBy=chassis
+SpreadGroup=frontend
+`, "chassis", true, "frontend"},
+		// empty keys are ignored
+		{"api.service", `[X-Fleet]
+SpreadBy=
+`, "", false, ""},
+	}
+	for i, tt := range tests {
+		j := NewJob(tt.name, *newUnit(t, tt.contents))
+		key, spread := j.SpreadBy()
+		if key != tt.key || spread != tt.spread {
+			t.Errorf("case %d: unexpected SpreadBy: got (%q, %t), want (%q, %t)", i, key, spread, tt.key, tt.spread)
+		}
+		if group := j.SpreadGroup(); group != tt.group {
+			t.Errorf("case %d: unexpected SpreadGroup: got %q, want %q", i, group, tt.group)
+		}
+	}
+}
+
+func TestJobPriority(t *testing.T) {
+	tests := []struct {
+		contents string
+		priority int
+		valid    bool
+	}{
+		// no priority
+		{``, 0, true},
+		{`[X-Fleet]
+Priority=100
+`, 100, true},
+		{`[X-Fleet]
+Priority=-10
+`, -10, true},
+		// last value wins
+		{`[X-Fleet]
+Priority=1
+Priority=2
+`, 2, true},
+		// invalid values are ignored
+		{`[X-Fleet]
+Priority=high
+`, 0, false},
+		{`[X-Fleet]
+Priority=4294967296
+`, 0, false},
+	}
+	for i, tt := range tests {
+		j := NewJob("foo.service", *newUnit(t, tt.contents))
+		if prio := j.Priority(); prio != tt.priority {
+			t.Errorf("case %d: unexpected priority: got %d, want %d", i, prio, tt.priority)
+		}
+		err := j.ValidatePriority()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
+
+func TestJobPreferences(t *testing.T) {
+	tests := []struct {
+		contents string
+		metadata []Preference
+		colocate []Preference
+		valid    bool
+	}{
+		// no preferences at all
+		{``, nil, nil, true},
+		// default and explicit weights
+		{`[X-Fleet]
+PreferMachineMetadata=disk=ssd
+PreferMachineMetadata=region=us-east region=us-west weight=5
+PreferNotColocatedWith=db@*.service weight=2
+`, []Preference{
+			{Values: []string{"disk=ssd"}, Weight: 1},
+			{Values: []string{"region=us-east", "region=us-west"}, Weight: 5},
+		}, []Preference{
+			{Values: []string{"db@*.service"}, Weight: 2},
+		}, true},
+		// invalid weights are reported
+		{`[X-Fleet]
+PreferNotColocatedWith=db.service weight=0
+`, nil, []Preference{
+			{Values: []string{"db.service"}, Weight: 1},
+		}, false},
+		{`[X-Fleet]
+PreferMachineMetadata=disk=ssd weight=heavy
+`, []Preference{
+			{Values: []string{"disk=ssd"}, Weight: 1},
+		}, nil, false},
+		// a weight alone is not a preference
+		{`[X-Fleet]
+PreferNotColocatedWith=weight=3
+`, nil, nil, false},
+		// metadata terms must be valid expressions
+		{`[X-Fleet]
+PreferMachineMetadata=cores>=many
+`, []Preference{
+			{Values: []string{"cores>=many"}, Weight: 1},
+		}, nil, false},
+	}
+	for i, tt := range tests {
+		j := NewJob("echo.service", *newUnit(t, tt.contents))
+		if got := j.PreferredMachineMetadata(); !reflect.DeepEqual(got, tt.metadata) {
+			t.Errorf("case %d: unexpected metadata preferences: got %#v, want %#v", i, got, tt.metadata)
+		}
+		if got := j.PreferredNotColocatedWith(); !reflect.DeepEqual(got, tt.colocate) {
+			t.Errorf("case %d: unexpected colocation preferences: got %#v, want %#v", i, got, tt.colocate)
+		}
+		err := j.ValidatePreferences()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
+
+func TestJobMetadataRequirement(t *testing.T) {
+	tests := []struct {
+		contents string
+		want     []string
+		valid    bool
+	}{
+		// no requirements
+		{``, nil, true},
+		// equalities on a single key are merged
+		{`[X-Fleet]
+MachineMetadata=region=us-east
+MachineMetadata=region=us-west
+X-ConditionMachineMetadata=disk=ssd
+`, []string{"disk=ssd", "region=us-east|region=us-west"}, true},
+		// other expressions are kept as separate clauses
+		{`[X-Fleet]
+MachineMetadata=region!=eu
+MachineMetadata=cores>=32|gpu
+MachineMetadata=!maintenance
+`, []string{"cores>=32|gpu", "region!=eu", "!maintenance"}, true},
+		// invalid expressions are ignored but reported
+		{`[X-Fleet]
+MachineMetadata=ignored=
+MachineMetadata=oh=yeah
+`, []string{"oh=yeah"}, false},
+		{`[X-Fleet]
+MachineMetadata=cores>=lots
+`, nil, false},
+	}
+	for i, tt := range tests {
+		j := NewJob("echo.service", *newUnit(t, tt.contents))
+		var got []string
+		for _, clause := range j.MetadataRequirement() {
+			got = append(got, clause.String())
+		}
+		sort.Strings(got)
+		want := tt.want
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("case %d: unexpected requirement: got %#v, want %#v", i, got, want)
+		}
+		err := j.ValidateMetadata()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
+
+func TestJobMovable(t *testing.T) {
+	tests := []struct {
+		contents string
+		movable  bool
+	}{
+		{``, true},
+		{`[X-Fleet]
+Movable=true
+`, true},
+		{`[X-Fleet]
+Movable=false
+`, false},
+		{`[X-Fleet]
+Movable=no
+`, false},
+		// last value wins
+		{`[X-Fleet]
+Movable=false
+Movable=yes
+`, true},
+	}
+	for i, tt := range tests {
+		j := NewJob("foo.service", *newUnit(t, tt.contents))
+		if movable := j.Movable(); movable != tt.movable {
+			t.Errorf("case %d: expected Movable %t, got %t", i, tt.movable, movable)
+		}
+	}
+}
+
+func TestJobRescheduleDelay(t *testing.T) {
+	tests := []struct {
+		contents string
+		delay    time.Duration
+		ok       bool
+		valid    bool
+	}{
+		// no delay
+		{``, 0, false, true},
+		{`[X-Fleet]
+RescheduleDelay=90s
+`, 90 * time.Second, true, true},
+		{`[X-Fleet]
+RescheduleDelay=5m
+`, 5 * time.Minute, true, true},
+		// plain numbers are seconds
+		{`[X-Fleet]
+RescheduleDelay=30
+`, 30 * time.Second, true, true},
+		// an explicit zero delay overrides the cluster-wide one
+		{`[X-Fleet]
+RescheduleDelay=0
+`, 0, true, true},
+		// last value wins
+		{`[X-Fleet]
+RescheduleDelay=1m
+RescheduleDelay=2m
+`, 2 * time.Minute, true, true},
+		// invalid values are ignored
+		{`[X-Fleet]
+RescheduleDelay=soon
+`, 0, false, false},
+		{`[X-Fleet]
+RescheduleDelay=-1m
+`, 0, false, false},
+	}
+	for i, tt := range tests {
+		j := NewJob("foo.service", *newUnit(t, tt.contents))
+		delay, ok := j.RescheduleDelay()
+		if delay != tt.delay || ok != tt.ok {
+			t.Errorf("case %d: unexpected reschedule delay: got %v/%t, want %v/%t", i, delay, ok, tt.delay, tt.ok)
+		}
+		err := j.ValidateRescheduleDelay()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
+
+func TestJobRequiredReplicas(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		replicas int
+		ok       bool
+		valid    bool
+	}{
+		{"web@.service", ``, 0, false, true},
+		{"web@.service", `[X-Fleet]
+Replicas=5
+`, 5, true, true},
+		{"web@.service", `[X-Fleet]
+Replicas=0
+`, 0, true, true},
+		// last value wins
+		{"web@.service", `[X-Fleet]
+Replicas=5
+Replicas=8
+`, 8, true, true},
+		// only templates are replicated
+		{"web@1.service", `[X-Fleet]
+Replicas=5
+`, 0, false, true},
+		{"web.service", `[X-Fleet]
+Replicas=5
+`, 0, false, true},
+		// invalid values are ignored
+		{"web@.service", `[X-Fleet]
+Replicas=-1
+`, 0, false, false},
+		{"web@.service", `[X-Fleet]
+Replicas=many
+`, 0, false, false},
+	}
+	for i, tt := range tests {
+		j := NewJob(tt.name, *newUnit(t, tt.contents))
+		replicas, ok := j.RequiredReplicas()
+		if replicas != tt.replicas || ok != tt.ok {
+			t.Errorf("case %d: unexpected replicas: got %d/%t, want %d/%t", i, replicas, ok, tt.replicas, tt.ok)
+		}
+		err := j.ValidateReplicas()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
+
+func TestJobGang(t *testing.T) {
+	tests := []struct {
+		contents string
+		gang     string
+		size     int
+		valid    bool
+	}{
+		{``, "", 0, true},
+		{`[X-Fleet]
+Gang=solver
+`, "solver", 0, true},
+		{`[X-Fleet]
+Gang=solver
+GangSize=4
+`, "solver", 4, true},
+		// last value wins
+		{`[X-Fleet]
+Gang=solver
+Gang=mesher
+`, "mesher", 0, true},
+		// a gang size needs a gang
+		{`[X-Fleet]
+GangSize=4
+`, "", 4, false},
+		// invalid sizes are ignored
+		{`[X-Fleet]
+Gang=solver
+GangSize=0
+`, "solver", 0, false},
+		{`[X-Fleet]
+Gang=solver
+GangSize=all
+`, "solver", 0, false},
+	}
+	for i, tt := range tests {
+		j := NewJob("foo.service", *newUnit(t, tt.contents))
+		if gang := j.Gang(); gang != tt.gang {
+			t.Errorf("case %d: expected gang %q, got %q", i, tt.gang, gang)
+		}
+		if size := j.GangSize(); size != tt.size {
+			t.Errorf("case %d: expected gang size %d, got %d", i, tt.size, size)
+		}
+		err := j.ValidateGang()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
+
+func TestJobStartAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		after    []string
+		valid    bool
+	}{
+		{"foo.service", ``, []string{}, true},
+		{"foo.service", `[X-Fleet]
+StartAfter=db.service
+`, []string{"db.service"}, true},
+		{"foo.service", `[X-Fleet]
+StartAfter=db.service cache.service
+StartAfter=%p.socket
+`, []string{"db.service", "cache.service", "foo.socket"}, true},
+		{"foo.service", `[X-Fleet]
+StartAfter=db
+`, []string{"db"}, false},
+		{"foo.service", `[X-Fleet]
+StartAfter=foo.service
+`, []string{"foo.service"}, false},
+	}
+	for i, tt := range tests {
+		j := NewJob(tt.name, *newUnit(t, tt.contents))
+		if after := j.StartAfter(); !reflect.DeepEqual(after, tt.after) {
+			t.Errorf("case %d: expected %v, got %v", i, tt.after, after)
+		}
+		err := j.ValidateStartAfter()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
+
+func TestJobMachineCapabilitiesAndVersion(t *testing.T) {
+	tests := []struct {
+		contents string
+		caps     []string
+		min      string
+		valid    bool
+	}{
+		{``, []string{}, "", true},
+		{`[X-Fleet]
+MachineCapabilities=GRPC
+`, []string{"GRPC"}, "", true},
+		{`[X-Fleet]
+MachineCapabilities=GRPC DISABLE_ENGINE
+MinFleetVersion=1.1.0
+`, []string{"GRPC", "DISABLE_ENGINE"}, "1.1.0", true},
+		// a leading v is accepted, last value wins
+		{`[X-Fleet]
+MinFleetVersion=0.9.0
+MinFleetVersion=v1.2.3
+`, []string{}, "1.2.3", true},
+		{`[X-Fleet]
+MinFleetVersion=1.1
+`, []string{}, "", false},
+	}
+	for i, tt := range tests {
+		j := NewJob("foo.service", *newUnit(t, tt.contents))
+		if caps := j.RequiredCapabilities(); !reflect.DeepEqual(caps, tt.caps) {
+			t.Errorf("case %d: expected capabilities %v, got %v", i, tt.caps, caps)
+		}
+		min, ok := j.MinFleetVersion()
+		if ok != (tt.min != "") {
+			t.Errorf("case %d: unexpected MinFleetVersion presence %t", i, ok)
+		} else if ok && min.String() != tt.min {
+			t.Errorf("case %d: expected MinFleetVersion %s, got %s", i, tt.min, min)
+		}
+		err := j.ValidateMinFleetVersion()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
+
+func TestJobHealthCheck(t *testing.T) {
+	tests := []struct {
+		contents string
+		hc       *HealthCheck
+		valid    bool
+	}{
+		// no health check
+		{``, nil, true},
+		{`[X-Fleet]
+HealthCheckInterval=5s
+`, nil, true},
+		{`[X-Fleet]
+HealthCheck=exec /usr/bin/pg_isready -q
+`, &HealthCheck{HealthCheckExec, "/usr/bin/pg_isready -q", DefaultHealthCheckInterval, DefaultHealthCheckThreshold, "", ""}, true},
+		// exec checks run as the user of the service
+		{`[Service]
+User=postgres
+Group=nogroup
+Group=postgres
+
+[X-Fleet]
+HealthCheck=exec /usr/bin/pg_isready -q
+`, &HealthCheck{HealthCheckExec, "/usr/bin/pg_isready -q", DefaultHealthCheckInterval, DefaultHealthCheckThreshold, "postgres", "postgres"}, true},
+		{`[X-Fleet]
+HealthCheck=http https://localhost:8443/health
+HealthCheckInterval=30
+HealthCheckThreshold=1
+`, &HealthCheck{HealthCheckHTTP, "https://localhost:8443/health", 30 * time.Second, 1, "", ""}, true},
+		// last value wins
+		{`[X-Fleet]
+HealthCheck=exec /bin/false
+HealthCheck=tcp 127.0.0.1:5432
+HealthCheckInterval=1m
+`, &HealthCheck{HealthCheckTCP, "127.0.0.1:5432", time.Minute, DefaultHealthCheckThreshold, "", ""}, true},
+		// invalid values
+		{`[X-Fleet]
+HealthCheck=exec
+`, nil, false},
+		{`[X-Fleet]
+HealthCheck=ping 127.0.0.1
+`, nil, false},
+		{`[X-Fleet]
+HealthCheck=http localhost/health
+`, nil, false},
+		{`[X-Fleet]
+HealthCheck=tcp localhost
+`, nil, false},
+		{`[X-Fleet]
+HealthCheck=tcp localhost:22
+HealthCheckInterval=0
+`, nil, false},
+		{`[X-Fleet]
+HealthCheck=tcp localhost:22
+HealthCheckThreshold=none
+`, nil, false},
+	}
+	for i, tt := range tests {
+		j := NewJob("foo.service", *newUnit(t, tt.contents))
+		hc, ok := j.HealthCheck()
+		if ok != (tt.hc != nil) || !reflect.DeepEqual(hc, tt.hc) {
+			t.Errorf("case %d: unexpected health check: got %#v/%t, want %#v", i, hc, ok, tt.hc)
+		}
+		err := j.ValidateHealthCheck()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
+
+func TestJobMaxFailures(t *testing.T) {
+	tests := []struct {
+		contents string
+		max      int
+		window   time.Duration
+		valid    bool
+	}{
+		// no limit
+		{``, 0, 0, true},
+		{`[X-Fleet]
+FailureWindow=5m
+`, 0, 0, true},
+		{`[X-Fleet]
+MaxFailures=3
+`, 3, DefaultFailureWindow, true},
+		// last value wins
+		{`[X-Fleet]
+MaxFailures=3
+MaxFailures=5
+FailureWindow=1h
+FailureWindow=600
+`, 5, 10 * time.Minute, true},
+		// invalid values
+		{`[X-Fleet]
+MaxFailures=0
+`, 0, 0, false},
+		{`[X-Fleet]
+MaxFailures=many
+`, 0, 0, false},
+		{`[X-Fleet]
+MaxFailures=3
+FailureWindow=0
+`, 0, 0, false},
+	}
+	for i, tt := range tests {
+		j := NewJob("foo.service", *newUnit(t, tt.contents))
+		max, window, ok := j.MaxFailures()
+		if ok != (tt.max != 0) || max != tt.max || window != tt.window {
+			t.Errorf("case %d: unexpected failure limit: got %d/%v/%t, want %d/%v", i, max, window, ok, tt.max, tt.window)
+		}
+		err := j.ValidateMaxFailures()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected non-nil error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: unexpected nil error", i)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/cea-hpc/fleet/log"
+	"github.com/cea-hpc/fleet/machine"
+)
+
+// MeetsMachineRequirements determines whether the machine advertises the
+// capabilities required by the Unit and runs at least its required fleet
+// version. If not, the reason is returned.
+func (u *Unit) MeetsMachineRequirements(ms *machine.MachineState) (bool, string) {
+	for _, c := range u.RequiredCapabilities() {
+		if !ms.Capabilities.Has(c) {
+			return false, fmt.Sprintf("local Machine lacks capability %q", c)
+		}
+	}
+
+	if min, ok := u.MinFleetVersion(); ok {
+		ver, err := ms.FleetVersion()
+		if err != nil {
+			return false, fmt.Sprintf("local Machine runs unknown fleet version %q", ms.Version)
+		}
+		if ver.LessThan(*min) {
+			return false, fmt.Sprintf("local Machine runs fleet %s, %s required", ver, min)
+		}
+	}
+
+	return true, ""
+}
+
+// FindConflicts determines whether the Unit of the given name and conflicts
+// conflicts with any of the given Units, either way. If so, the conflicting
+// name is returned.
+func FindConflicts(name string, conflicts []string, units map[string]*Unit) (bool, []string) {
+	for _, eUnit := range units {
+		if name == eUnit.Name {
+			continue
+		}
+
+		if matchesAny(eUnit.Conflicts(), name) {
+			return true, []string{name}
+		}
+		if matchesAny(conflicts, eUnit.Name) {
+			return true, []string{eUnit.Name}
+		}
+	}
+
+	return false, []string{}
+}
+
+// AddGlobalUnits adds the global Units which run on the machine to the Units
+// scheduled to it, considering the global Units in the given order. A global
+// Unit runs on every machine matching its metadata and machine requirements,
+// unless it conflicts with a Unit already running there. Global Units whose
+// target state is inactive do not run anywhere.
+func AddGlobalUnits(ms *machine.MachineState, units map[string]*Unit, gUnits []*Unit) {
+	for _, gu := range gUnits {
+		if gu.TargetState == JobStateInactive {
+			continue
+		}
+		if !machine.MatchesMetadata(ms, gu.MetadataRequirement()) {
+			continue
+		}
+		if ok, _ := gu.MeetsMachineRequirements(ms); !ok {
+			continue
+		}
+		if found, _ := FindConflicts(gu.Name, gu.Conflicts(), units); found {
+			continue
+		}
+		units[gu.Name] = gu
+	}
+}
+
+// matchesAny determines whether the name matches any of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			log.Debugf("Received error while matching pattern '%s': %v", pattern, err)
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cea-hpc/fleet/machine"
+)
+
+func TestAddGlobalUnits(t *testing.T) {
+	ms := &machine.MachineState{
+		ID:       "XXX",
+		Metadata: map[string]string{"region": "us"},
+		Version:  "1.0.0",
+	}
+	globalUnit := func(name, opts string, target JobState) *Unit {
+		return &Unit{Name: name, Unit: *newUnit(t, "[X-Fleet]\nGlobal=true\n"+opts), TargetState: target}
+	}
+
+	tests := []struct {
+		gUnits []*Unit
+		want   []string
+	}{
+		{
+			[]*Unit{
+				globalUnit("a.service", "MachineMetadata=region=us", JobStateLaunched),
+				globalUnit("b.service", "MachineMetadata=region=eu", JobStateLaunched),
+				globalUnit("c.service", "", JobStateInactive),
+				globalUnit("d.service", "MachineCapabilities=GRPC", JobStateLaunched),
+				globalUnit("e.service", "MinFleetVersion=2.0.0", JobStateLoaded),
+			},
+			[]string{"a.service", "foo.service"},
+		},
+		// global units conflicting with a scheduled unit, or with a
+		// global unit placed before them, do not run on the machine
+		{
+			[]*Unit{
+				globalUnit("a.service", "Conflicts=foo.service", JobStateLaunched),
+				globalUnit("b.service", "", JobStateLaunched),
+				globalUnit("c.service", "Conflicts=b.service", JobStateLaunched),
+			},
+			[]string{"b.service", "foo.service"},
+		},
+	}
+
+	for i, tt := range tests {
+		units := map[string]*Unit{
+			"foo.service": &Unit{Name: "foo.service", TargetState: JobStateLaunched},
+		}
+		AddGlobalUnits(ms, units, tt.gUnits)
+
+		var got []string
+		for name := range units {
+			got = append(got, name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: expected units %v, got %v", i, tt.want, got)
+		}
+	}
+}
+
+func TestUnitMeetsMachineRequirements(t *testing.T) {
+	tests := []struct {
+		opts   string
+		ms     machine.MachineState
+		meets  bool
+		reason string
+	}{
+		{"", machine.MachineState{}, true, ""},
+		{"MachineCapabilities=GRPC", machine.MachineState{Capabilities: machine.Capabilities{"GRPC": true}}, true, ""},
+		{"MachineCapabilities=GRPC", machine.MachineState{}, false, `local Machine lacks capability "GRPC"`},
+		{"MinFleetVersion=1.2.0", machine.MachineState{Version: "1.2.0"}, true, ""},
+		{"MinFleetVersion=1.2.0", machine.MachineState{Version: "1.1.9"}, false, "local Machine runs fleet 1.1.9, 1.2.0 required"},
+		{"MinFleetVersion=1.2.0", machine.MachineState{Version: "dev"}, false, `local Machine runs unknown fleet version "dev"`},
+	}
+
+	for i, tt := range tests {
+		u := Unit{Name: "foo.service", Unit: *newUnit(t, "[X-Fleet]\n"+tt.opts)}
+		meets, reason := u.MeetsMachineRequirements(&tt.ms)
+		if meets != tt.meets || reason != tt.reason {
+			t.Errorf("case %d: expected (%t, %q), got (%t, %q)", i, tt.meets, tt.reason, meets, reason)
+		}
+	}
+}
@@ -15,10 +15,14 @@
 package machine
 
 import (
+	"bufio"
 	"errors"
 	"io/ioutil"
 	"net"
+	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -26,11 +30,13 @@ import (
 	"github.com/vishvananda/netlink"
 
 	"github.com/cea-hpc/fleet/log"
+	"github.com/cea-hpc/fleet/resource"
 	"github.com/cea-hpc/fleet/unit"
 )
 
 const (
 	machineIDPath = "/etc/machine-id"
+	meminfoPath   = "/proc/meminfo"
 )
 
 func NewCoreOSMachine(static MachineState, um unit.UnitManager) *CoreOSMachine {
@@ -109,9 +115,10 @@ func (m *CoreOSMachine) currentState() *MachineState {
 	}
 	publicIP := getLocalIP()
 	return &MachineState{
-		ID:       id,
-		PublicIP: publicIP,
-		Metadata: make(map[string]string, 0),
+		ID:             id,
+		PublicIP:       publicIP,
+		Metadata:       make(map[string]string, 0),
+		TotalResources: readLocalResources("/"),
 	}
 }
 
@@ -134,6 +141,50 @@ func readLocalMachineID(root string) (string, error) {
 	return mID, nil
 }
 
+// readLocalResources detects the number of cores and the amount of memory
+// of the local host. Disk space is left for the configuration to declare.
+func readLocalResources(root string) resource.ResourceTuple {
+	res := resource.ResourceTuple{
+		Cores: runtime.NumCPU() * 100,
+	}
+
+	memory, err := readLocalMemory(root)
+	if err != nil {
+		log.Debugf("Unable to detect total memory: %v", err)
+	} else {
+		res.Memory = memory
+	}
+
+	return res
+}
+
+// readLocalMemory returns the total memory of the local host in MB
+func readLocalMemory(root string) (int, error) {
+	f, err := os.Open(filepath.Join(root, meminfoPath))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kb, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, err
+		}
+		return kb / 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, errors.New("MemTotal not found")
+}
+
 func getLocalIP() (got string) {
 	iface := getDefaultGatewayIface()
 	if iface == nil {
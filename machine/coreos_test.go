@@ -65,6 +65,38 @@ func TestReadLocalMachineIDFound(t *testing.T) {
 	}
 }
 
+func TestReadLocalMemory(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "fleet-")
+	if err != nil {
+		t.Fatalf("Failed creating tempdir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := readLocalMemory(dir); err == nil {
+		t.Fatal("Expected error for missing meminfo, but got nil")
+	}
+
+	tmpMeminfoPath := filepath.Join(dir, "/proc/meminfo")
+	err = os.MkdirAll(filepath.Dir(tmpMeminfoPath), os.FileMode(0755))
+	if err != nil {
+		t.Fatalf("Failed setting up fake meminfo path: %v", err)
+	}
+
+	meminfo := "MemTotal:       16318268 kB\nMemFree:         1198276 kB\n"
+	err = ioutil.WriteFile(tmpMeminfoPath, []byte(meminfo), os.FileMode(0644))
+	if err != nil {
+		t.Fatalf("Failed writing fake meminfo file: %v", err)
+	}
+
+	memory, err := readLocalMemory(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error reading memory: %v", err)
+	}
+	if memory != 15935 {
+		t.Fatalf("Received incorrect memory %d, expected 15935", memory)
+	}
+}
+
 func TestUsableAddress(t *testing.T) {
 	tests := []struct {
 		ip net.IP
@@ -0,0 +1,226 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package machine
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cea-hpc/fleet/log"
+)
+
+// Operators of the terms of a metadata expression.
+const (
+	MetadataOpEqual        = "="
+	MetadataOpNotEqual     = "!="
+	MetadataOpMatch        = "=~"
+	MetadataOpNotMatch     = "!~"
+	MetadataOpGreater      = ">"
+	MetadataOpGreaterEqual = ">="
+	MetadataOpLess         = "<"
+	MetadataOpLessEqual    = "<="
+	MetadataOpExists       = "exists"
+	MetadataOpNotExists    = "!exists"
+
+	// metadataOrSeparator separates the alternative terms of a clause
+	metadataOrSeparator = "|"
+)
+
+// metadataOps lists the binary operators, longest first so that parsing
+// picks e.g. ">=" over ">".
+var metadataOps = []string{
+	MetadataOpNotEqual,
+	MetadataOpNotMatch,
+	MetadataOpMatch,
+	MetadataOpGreaterEqual,
+	MetadataOpLessEqual,
+	MetadataOpEqual,
+	MetadataOpGreater,
+	MetadataOpLess,
+}
+
+// MetadataTerm is a single condition on the value of a metadata key.
+type MetadataTerm struct {
+	Key   string
+	Op    string
+	Value string
+
+	re  *regexp.Regexp
+	num float64
+}
+
+// MetadataClause is satisfied by metadata satisfying any of its terms.
+type MetadataClause []MetadataTerm
+
+// MetadataRequirement is satisfied by metadata satisfying all of its clauses.
+type MetadataRequirement []MetadataClause
+
+// ParseMetadataTerm parses a single condition on machine metadata, in one
+// of the following forms:
+//   - key=value or key!=value: value equality or inequality
+//   - key=~regex or key!~regex: regular expression match or mismatch
+//   - key>N, key>=N, key<N or key<=N: numeric comparison
+//   - key or !key: existence or non-existence of the key
+func ParseMetadataTerm(expr string) (term MetadataTerm, err error) {
+	if len(expr) == 0 {
+		return term, fmt.Errorf("empty metadata expression")
+	}
+
+	i := strings.IndexAny(expr, "=!<>")
+	switch {
+	case i == -1:
+		term = MetadataTerm{Key: expr, Op: MetadataOpExists}
+		return term, nil
+	case i == 0 && expr[0] == '!' && !strings.ContainsAny(expr[1:], "=!<>~"):
+		if len(expr) == 1 {
+			return term, fmt.Errorf("invalid metadata expression %q: missing key", expr)
+		}
+		term = MetadataTerm{Key: expr[1:], Op: MetadataOpNotExists}
+		return term, nil
+	case i == 0:
+		return term, fmt.Errorf("invalid metadata expression %q: missing key", expr)
+	}
+
+	term.Key = expr[:i]
+	rest := expr[i:]
+	for _, op := range metadataOps {
+		if strings.HasPrefix(rest, op) {
+			term.Op = op
+			term.Value = rest[len(op):]
+			break
+		}
+	}
+	if term.Op == "" {
+		return term, fmt.Errorf("invalid metadata expression %q: unknown operator", expr)
+	}
+	if len(term.Value) == 0 {
+		return term, fmt.Errorf("invalid metadata expression %q: missing value", expr)
+	}
+
+	switch term.Op {
+	case MetadataOpEqual, MetadataOpNotEqual:
+		if strings.ContainsAny(term.Value, "=!<>") {
+			return term, fmt.Errorf("invalid metadata expression %q: unexpected operator in value", expr)
+		}
+	case MetadataOpMatch, MetadataOpNotMatch:
+		term.re, err = regexp.Compile(term.Value)
+		if err != nil {
+			return term, fmt.Errorf("invalid metadata expression %q: %v", expr, err)
+		}
+	default:
+		term.num, err = strconv.ParseFloat(term.Value, 64)
+		if err != nil {
+			return term, fmt.Errorf("invalid metadata expression %q: %q is not a number", expr, term.Value)
+		}
+	}
+
+	return term, nil
+}
+
+// ParseMetadataClause parses alternative metadata terms separated by "|".
+func ParseMetadataClause(expr string) (MetadataClause, error) {
+	var clause MetadataClause
+	for _, s := range strings.Split(expr, metadataOrSeparator) {
+		term, err := ParseMetadataTerm(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		clause = append(clause, term)
+	}
+	return clause, nil
+}
+
+// Matches determines whether the given metadata satisfies the term. Keys
+// missing from the metadata satisfy the negative operators only.
+func (t MetadataTerm) Matches(metadata map[string]string) bool {
+	local, ok := metadata[t.Key]
+	switch t.Op {
+	case MetadataOpExists:
+		return ok
+	case MetadataOpNotExists:
+		return !ok
+	case MetadataOpEqual:
+		return ok && local == t.Value
+	case MetadataOpNotEqual:
+		return !ok || local != t.Value
+	case MetadataOpMatch:
+		return ok && t.re.MatchString(local)
+	case MetadataOpNotMatch:
+		return !ok || !t.re.MatchString(local)
+	}
+
+	if !ok {
+		return false
+	}
+	num, err := strconv.ParseFloat(local, 64)
+	if err != nil {
+		log.Debugf("Local Metadata(%s) value %q is not a number", t.Key, local)
+		return false
+	}
+	switch t.Op {
+	case MetadataOpGreater:
+		return num > t.num
+	case MetadataOpGreaterEqual:
+		return num >= t.num
+	case MetadataOpLess:
+		return num < t.num
+	case MetadataOpLessEqual:
+		return num <= t.num
+	}
+	return false
+}
+
+func (t MetadataTerm) String() string {
+	switch t.Op {
+	case MetadataOpExists:
+		return t.Key
+	case MetadataOpNotExists:
+		return "!" + t.Key
+	}
+	return t.Key + t.Op + t.Value
+}
+
+// Matches determines whether the given metadata satisfies any of the terms
+// of the clause.
+func (c MetadataClause) Matches(metadata map[string]string) bool {
+	for _, term := range c {
+		if term.Matches(metadata) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c MetadataClause) String() string {
+	terms := make([]string, len(c))
+	for i, term := range c {
+		terms[i] = term.String()
+	}
+	return strings.Join(terms, metadataOrSeparator)
+}
+
+// MatchesMetadata determines if the Metadata of a given MachineState
+// satisfies every clause of the requirement.
+func MatchesMetadata(state *MachineState, req MetadataRequirement) bool {
+	for _, clause := range req {
+		if !clause.Matches(state.Metadata) {
+			log.Debugf("Local Metadata does not meet requirement %s", clause)
+			return false
+		}
+	}
+	return true
+}
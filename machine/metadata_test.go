@@ -0,0 +1,137 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package machine
+
+import (
+	"testing"
+)
+
+func TestParseMetadataTerm(t *testing.T) {
+	for i, tt := range []struct {
+		expr  string
+		key   string
+		op    string
+		value string
+	}{
+		{"region=us-east", "region", MetadataOpEqual, "us-east"},
+		{"region!=eu", "region", MetadataOpNotEqual, "eu"},
+		{"host=~^node[0-9]+$", "host", MetadataOpMatch, "^node[0-9]+$"},
+		{"host!~^login", "host", MetadataOpNotMatch, "^login"},
+		{"cores>=32", "cores", MetadataOpGreaterEqual, "32"},
+		{"cores>8", "cores", MetadataOpGreater, "8"},
+		{"memory<=65536", "memory", MetadataOpLessEqual, "65536"},
+		{"load<0.5", "load", MetadataOpLess, "0.5"},
+		{"gpu", "gpu", MetadataOpExists, ""},
+		{"!gpu", "gpu", MetadataOpNotExists, ""},
+	} {
+		term, err := ParseMetadataTerm(tt.expr)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if term.Key != tt.key || term.Op != tt.op || term.Value != tt.value {
+			t.Errorf("case %d: got (%q, %q, %q), want (%q, %q, %q)", i, term.Key, term.Op, term.Value, tt.key, tt.op, tt.value)
+		}
+		if term.String() != tt.expr {
+			t.Errorf("case %d: got string %q, want %q", i, term.String(), tt.expr)
+		}
+	}
+
+	for i, expr := range []string{
+		"",
+		"!",
+		"=value",
+		"!region=eu",
+		"region=",
+		"foo=asdf=WHAT",
+		"cores>=many",
+		"host=~[",
+		"region<>eu",
+	} {
+		if _, err := ParseMetadataTerm(expr); err == nil {
+			t.Errorf("case %d: expected error for %q", i, expr)
+		}
+	}
+}
+
+func TestMetadataTermMatches(t *testing.T) {
+	metadata := map[string]string{
+		"region": "us-east",
+		"host":   "node42",
+		"cores":  "32",
+		"arch":   "x86_64",
+	}
+
+	for i, tt := range []struct {
+		expr string
+		want bool
+	}{
+		{"region=us-east", true},
+		{"region=eu", false},
+		{"region!=eu", true},
+		{"region!=us-east", false},
+		{"zone!=a", true},
+		{"host=~^node[0-9]+$", true},
+		{"host=~^login", false},
+		{"host!~^login", true},
+		{"zone!~^a", true},
+		{"cores>=32", true},
+		{"cores>32", false},
+		{"cores<64", true},
+		{"cores<=16", false},
+		{"arch>1", false},
+		{"memory>1", false},
+		{"region", true},
+		{"gpu", false},
+		{"!gpu", true},
+		{"!region", false},
+	} {
+		term, err := ParseMetadataTerm(tt.expr)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		if got := term.Matches(metadata); got != tt.want {
+			t.Errorf("case %d: %q matched %t, want %t", i, tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesMetadata(t *testing.T) {
+	ms := &MachineState{Metadata: map[string]string{"region": "us-east", "cores": "16"}}
+
+	for i, tt := range []struct {
+		clauses []string
+		want    bool
+	}{
+		{[]string{}, true},
+		{[]string{"region=us-east", "cores>=8"}, true},
+		{[]string{"region=us-east", "cores>=32"}, false},
+		{[]string{"region=eu|cores>=8"}, true},
+		{[]string{"region=eu | gpu"}, false},
+		{[]string{"region=eu|region=us-east", "!gpu"}, true},
+	} {
+		var req MetadataRequirement
+		for _, expr := range tt.clauses {
+			clause, err := ParseMetadataClause(expr)
+			if err != nil {
+				t.Fatalf("case %d: unexpected error: %v", i, err)
+			}
+			req = append(req, clause)
+		}
+		if got := MatchesMetadata(ms, req); got != tt.want {
+			t.Errorf("case %d: MatchesMetadata returned %t, want %t", i, got, tt.want)
+		}
+	}
+}
@@ -14,8 +14,22 @@
 
 package machine
 
+import (
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+
+	"github.com/cea-hpc/fleet/resource"
+)
+
 const (
 	shortIDLen = 8
+
+	// MaintenanceCordoned machines are not eligible for new units
+	MaintenanceCordoned = "cordoned"
+	// MaintenanceDraining machines are cordoned, and the units scheduled
+	// to them are moved to other machines
+	MaintenanceDraining = "draining"
 )
 
 // MachineState represents a point-in-time snapshot of the
@@ -26,6 +40,26 @@ type MachineState struct {
 	Metadata     map[string]string
 	Capabilities Capabilities
 	Version      string
+	// TotalResources is the capacity of the host, including the
+	// resources reserved for the host itself.
+	TotalResources resource.ResourceTuple
+	// FreeResources is the capacity left once the host and the units
+	// scheduled to it have been accounted for. It is not advertised by
+	// the machine but computed by the clients listing machines.
+	FreeResources resource.ResourceTuple
+	// Maintenance is the maintenance mode the machine was put in by an
+	// operator, if any. It is not advertised by the machine but set
+	// through the Registry.
+	Maintenance string
+	// MaxUnits and MaxWeight limit the number of units scheduled to the
+	// machine and the sum of their Weight. Zero means no limit.
+	MaxUnits  int
+	MaxWeight int
+	// UsedUnits and UsedWeight are the number of units scheduled to the
+	// machine and the sum of their Weight. Like FreeResources, they are
+	// computed by the clients listing machines.
+	UsedUnits  int
+	UsedWeight int
 }
 
 func (ms MachineState) ShortID() string {
@@ -47,6 +81,30 @@ func (ms MachineState) MatchID(ID string) bool {
 	return ms.ID == ID || ms.ShortID() == ID
 }
 
+// Cordoned determines whether the machine is excluded from the placement of
+// new units, either because it is cordoned or because it is being drained.
+func (ms MachineState) Cordoned() bool {
+	return ms.Maintenance == MaintenanceCordoned || ms.Maintenance == MaintenanceDraining
+}
+
+// FleetVersion parses the version of fleet the machine runs. An error is
+// returned if the machine advertises no valid version.
+func (ms MachineState) FleetVersion() (*semver.Version, error) {
+	return semver.NewVersion(strings.TrimPrefix(ms.Version, "v"))
+}
+
+// Limited determines whether the machine limits the number or the total
+// Weight of the units scheduled to it.
+func (ms MachineState) Limited() bool {
+	return ms.MaxUnits != 0 || ms.MaxWeight != 0
+}
+
+// Draining determines whether the units scheduled to the machine should be
+// moved to other machines.
+func (ms MachineState) Draining() bool {
+	return ms.Maintenance == MaintenanceDraining
+}
+
 // stackState is used to merge two MachineStates. Values configured on the top
 // MachineState always take precedence over those on the bottom.
 func stackState(top, bottom MachineState) MachineState {
@@ -75,5 +133,25 @@ func stackState(top, bottom MachineState) MachineState {
 		state.Version = top.Version
 	}
 
+	if top.TotalResources.Cores != 0 {
+		state.TotalResources.Cores = top.TotalResources.Cores
+	}
+
+	if top.TotalResources.Memory != 0 {
+		state.TotalResources.Memory = top.TotalResources.Memory
+	}
+
+	if top.TotalResources.Disk != 0 {
+		state.TotalResources.Disk = top.TotalResources.Disk
+	}
+
+	if top.MaxUnits != 0 {
+		state.MaxUnits = top.MaxUnits
+	}
+
+	if top.MaxWeight != 0 {
+		state.MaxWeight = top.MaxWeight
+	}
+
 	return state
 }
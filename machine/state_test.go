@@ -14,7 +14,11 @@
 
 package machine
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/cea-hpc/fleet/resource"
+)
 
 func TestStackState(t *testing.T) {
 	top := MachineState{
@@ -22,12 +26,23 @@ func TestStackState(t *testing.T) {
 		PublicIP: "1.2.3.4",
 		Metadata: map[string]string{"ping": "pong"},
 		Version:  "1",
+		TotalResources: resource.ResourceTuple{
+			Disk: 10240,
+		},
+		MaxUnits: 20,
 	}
 	bottom := MachineState{
 		ID:       "595989bb-cbb7-49ce-8726-722d6e157b4e",
 		PublicIP: "5.6.7.8",
 		Metadata: map[string]string{"foo": "bar"},
 		Version:  "",
+		TotalResources: resource.ResourceTuple{
+			Cores:  800,
+			Memory: 16384,
+			Disk:   512,
+		},
+		MaxUnits:  10,
+		MaxWeight: 100,
 	}
 	stacked := stackState(top, bottom)
 
@@ -46,6 +61,15 @@ func TestStackState(t *testing.T) {
 	if stacked.Version != "1" {
 		t.Errorf("Unexpected Version value %s", stacked.Version)
 	}
+
+	wantRes := resource.ResourceTuple{Cores: 800, Memory: 16384, Disk: 10240}
+	if stacked.TotalResources != wantRes {
+		t.Errorf("Unexpected TotalResources %v", stacked.TotalResources)
+	}
+
+	if stacked.MaxUnits != 20 || stacked.MaxWeight != 100 {
+		t.Errorf("Unexpected limits %d units, %d weight", stacked.MaxUnits, stacked.MaxWeight)
+	}
 }
 
 func TestStackStateEmptyTop(t *testing.T) {
@@ -91,6 +115,13 @@ var shortIDTests = []struct {
 			map[string]string{"foo": "bar"},
 			Capabilities{},
 			"",
+			resource.ResourceTuple{},
+			resource.ResourceTuple{},
+			"",
+			0,
+			0,
+			0,
+			0,
 		},
 		s: "595989bb",
 		l: "595989bb-cbb7-49ce-8726-722d6e157b4e",
@@ -23,6 +23,7 @@ import (
 
 type (
 	engineFailure string
+	engineSync    string
 	registryOp    string
 )
 
@@ -32,6 +33,10 @@ const (
 	MachineAway     engineFailure = "machine_away"
 	RunFailure      engineFailure = "run"
 	ScheduleFailure engineFailure = "schedule"
+	GangFailure     engineFailure = "gang"
+	UnitFailing     engineFailure = "unit_failing"
+	FullSync        engineSync    = "full"
+	IncrementalSync engineSync    = "incremental"
 	Get             registryOp    = "get"
 	Set             registryOp    = "set"
 	GetAll          registryOp    = "get_all"
@@ -80,6 +85,41 @@ var (
 		Help:      "Counter of scheduling failures.",
 	}, []string{"type"})
 
+	engineRebalanceMoveCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "engine",
+		Name:      "rebalance_move_count_total",
+		Help:      "Counter of units moved between machines to rebalance the cluster.",
+	}, []string{"from", "to"})
+
+	engineRescheduleWaitingGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "engine",
+		Name:      "reschedule_waiting_units",
+		Help:      "Number of units kept on lost machines until their reschedule delay elapses.",
+	})
+
+	engineLeaderHandoverGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "engine",
+		Name:      "leader_handover_seconds",
+		Help:      "Time (in seconds) the engine took from becoming leader to completing its first reconciliation.",
+	})
+
+	engineDeferredTasksGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "engine",
+		Name:      "deferred_tasks",
+		Help:      "Number of tasks deferred to the next reconciliation by the engine throttle during the last one.",
+	}, []string{"type"})
+
+	engineClusterSyncCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "engine",
+		Name:      "cluster_sync_count_total",
+		Help:      "Counter of cluster state reads from the Registry, either full or incremental.",
+	}, []string{"type"})
+
 	registryOpCount = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: Namespace,
 		Subsystem: "registry",
@@ -136,6 +176,13 @@ var (
 		Help:      "Agent job states",
 	}, []string{"job", "desired_state"} )
 
+	agentTaskFailureCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "agent",
+		Name:      "task_failure_count_total",
+		Help:      "Counter of agent task failures.",
+	}, []string{"type"})
+
 	healthyGauge = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: Namespace,
 		Subsystem: "agent",
@@ -143,6 +190,27 @@ var (
 		Help:      "Is the agent healthy",
 	})
 
+	agentUnitCPUGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "agent",
+		Name:      "unit_cpu_usage_seconds",
+		Help:      "CPU time (in seconds) consumed by a unit, as accounted by systemd.",
+	}, []string{"unit"})
+
+	agentUnitMemoryGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "agent",
+		Name:      "unit_memory_bytes",
+		Help:      "Memory (in bytes) currently used by a unit, as accounted by systemd.",
+	}, []string{"unit"})
+
+	agentUnitTasksGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "agent",
+		Name:      "unit_tasks",
+		Help:      "Number of tasks currently running in a unit, as accounted by systemd.",
+	}, []string{"unit"})
+
 )
 
 func init() {
@@ -150,6 +218,10 @@ func init() {
 	prometheus.MustRegister(agentStateGauge)
 	prometheus.MustRegister(agentLoadGauge)
 	prometheus.MustRegister(healthyGauge)
+	prometheus.MustRegister(agentTaskFailureCount)
+	prometheus.MustRegister(agentUnitCPUGauge)
+	prometheus.MustRegister(agentUnitMemoryGauge)
+	prometheus.MustRegister(agentUnitTasksGauge)
 	prometheus.MustRegister(clusterJobsGauge)
 	prometheus.MustRegister(isLeaderGauge)
 	prometheus.MustRegister(leaderGauge)
@@ -157,6 +229,11 @@ func init() {
 	prometheus.MustRegister(engineTaskFailureCount)
 	prometheus.MustRegister(engineReconcileCount)
 	prometheus.MustRegister(engineReconcileFailureCount)
+	prometheus.MustRegister(engineRebalanceMoveCount)
+	prometheus.MustRegister(engineRescheduleWaitingGauge)
+	prometheus.MustRegister(engineClusterSyncCount)
+	prometheus.MustRegister(engineLeaderHandoverGauge)
+	prometheus.MustRegister(engineDeferredTasksGauge)
 }
 
 func ReportHealth(healthy bool){
@@ -179,6 +256,20 @@ func ReportAgentState(job string, dstate string, nominal bool) {
 		agentStateGauge.WithLabelValues(job, dstate).Set(0)
 	}
 }
+func ReportAgentTaskFailure(task string) {
+	task = strings.ToLower(task)
+	agentTaskFailureCount.WithLabelValues(task).Inc()
+}
+func ReportAgentUnitUsage(unit string, cpu time.Duration, memory, tasks uint64) {
+	agentUnitCPUGauge.WithLabelValues(unit).Set(cpu.Seconds())
+	agentUnitMemoryGauge.WithLabelValues(unit).Set(float64(memory))
+	agentUnitTasksGauge.WithLabelValues(unit).Set(float64(tasks))
+}
+func ForgetAgentUnitUsage(unit string) {
+	agentUnitCPUGauge.DeleteLabelValues(unit)
+	agentUnitMemoryGauge.DeleteLabelValues(unit)
+	agentUnitTasksGauge.DeleteLabelValues(unit)
+}
 func ReportClusterJob(job string, mach_id *string, scheduled bool) {
 	agentStateGauge.DeleteLabelValues(job)
 	if scheduled {
@@ -228,6 +319,21 @@ func ReportEngineReconcileSuccess(start time.Time) {
 func ReportEngineReconcileFailure(reason engineFailure) {
 	engineReconcileFailureCount.WithLabelValues(string(reason)).Inc()
 }
+func ReportEngineRebalanceMove(from, to string) {
+	engineRebalanceMoveCount.WithLabelValues(from, to).Inc()
+}
+func ReportEngineRescheduleWaiting(units int) {
+	engineRescheduleWaitingGauge.Set(float64(units))
+}
+func ReportEngineDeferredTasks(task string, n int) {
+	engineDeferredTasksGauge.WithLabelValues(task).Set(float64(n))
+}
+func ReportEngineLeaderHandover(d time.Duration) {
+	engineLeaderHandoverGauge.Set(float64(d) / float64(time.Second))
+}
+func ReportEngineClusterSync(typ engineSync) {
+	engineClusterSyncCount.WithLabelValues(string(typ)).Inc()
+}
 func ReportRegistryOpSuccess(op registryOp, start time.Time) {
 	registryOpCount.WithLabelValues(string(op)).Inc()
 	registryOpDuration.WithLabelValues(string(op)).Observe(float64(time.Since(start)) / float64(time.Second))
@@ -32,6 +32,7 @@
 		NotFound
 		UnitFile
 		UnitOption
+		ScaleUnitRequest
 */
 package rpc
 
@@ -261,12 +262,26 @@ func (m *UnitStates) GetUnitStates() []*UnitState {
 }
 
 type UnitState struct {
-	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Hash        string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
-	LoadState   string `protobuf:"bytes,3,opt,name=load_state,json=loadState,proto3" json:"load_state,omitempty"`
-	ActiveState string `protobuf:"bytes,4,opt,name=active_state,json=activeState,proto3" json:"active_state,omitempty"`
-	SubState    string `protobuf:"bytes,5,opt,name=sub_state,json=subState,proto3" json:"sub_state,omitempty"`
-	MachineID   string `protobuf:"bytes,6,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	Name                   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Hash                   string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	LoadState              string `protobuf:"bytes,3,opt,name=load_state,json=loadState,proto3" json:"load_state,omitempty"`
+	ActiveState            string `protobuf:"bytes,4,opt,name=active_state,json=activeState,proto3" json:"active_state,omitempty"`
+	SubState               string `protobuf:"bytes,5,opt,name=sub_state,json=subState,proto3" json:"sub_state,omitempty"`
+	MachineID              string `protobuf:"bytes,6,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	// healthy is only meaningful when health_checked is set
+	HealthChecked          bool   `protobuf:"varint,7,opt,name=health_checked,json=healthChecked,proto3" json:"health_checked,omitempty"`
+	Healthy                bool   `protobuf:"varint,8,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// failures counts the times the unit entered the failed state
+	Failures               int32  `protobuf:"varint,9,opt,name=failures,proto3" json:"failures,omitempty"`
+	ExecMainStatus         int32  `protobuf:"varint,10,opt,name=exec_main_status,json=execMainStatus,proto3" json:"exec_main_status,omitempty"`
+	NRestarts              uint32 `protobuf:"varint,11,opt,name=n_restarts,json=nRestarts,proto3" json:"n_restarts,omitempty"`
+	// timestamps are in microseconds since the epoch
+	ActiveEnterTimestamp   uint64 `protobuf:"varint,12,opt,name=active_enter_timestamp,json=activeEnterTimestamp,proto3" json:"active_enter_timestamp,omitempty"`
+	InactiveEnterTimestamp uint64 `protobuf:"varint,13,opt,name=inactive_enter_timestamp,json=inactiveEnterTimestamp,proto3" json:"inactive_enter_timestamp,omitempty"`
+	// resource usage as accounted by systemd, zero if not accounted
+	CPUUsageNSec           uint64 `protobuf:"varint,14,opt,name=cpu_usage_nsec,json=cpuUsageNsec,proto3" json:"cpu_usage_nsec,omitempty"`
+	MemoryCurrent          uint64 `protobuf:"varint,15,opt,name=memory_current,json=memoryCurrent,proto3" json:"memory_current,omitempty"`
+	TasksCurrent           uint64 `protobuf:"varint,16,opt,name=tasks_current,json=tasksCurrent,proto3" json:"tasks_current,omitempty"`
 }
 
 func (m *UnitState) Reset()                    { *m = UnitState{} }
@@ -315,6 +330,7 @@ type Unit struct {
 	Unit         UnitFile    `protobuf:"bytes,2,opt,name=unit" json:"unit"`
 	DesiredState TargetState `protobuf:"varint,3,opt,name=desired_state,json=desiredState,proto3,enum=rpc.TargetState" json:"desired_state,omitempty"`
 	Weight       uint16      `protobuf:"varint,4,opt,name=weight,proto3" json:"name,omitempty"`
+	Replicas     int32       `protobuf:"varint,5,opt,name=replicas,proto3" json:"replicas,omitempty"`
 }
 
 func (m *Unit) Reset()                    { *m = Unit{} }
@@ -610,6 +626,16 @@ func (m *UnitOption) String() string            { return proto.CompactTextString
 func (*UnitOption) ProtoMessage()               {}
 func (*UnitOption) Descriptor() ([]byte, []int) { return fileDescriptorFleet, []int{22} }
 
+type ScaleUnitRequest struct {
+	Name     string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Replicas int32  `protobuf:"varint,2,opt,name=replicas,proto3" json:"replicas,omitempty"`
+}
+
+func (m *ScaleUnitRequest) Reset()                    { *m = ScaleUnitRequest{} }
+func (m *ScaleUnitRequest) String() string            { return proto.CompactTextString(m) }
+func (*ScaleUnitRequest) ProtoMessage()               {}
+func (*ScaleUnitRequest) Descriptor() ([]byte, []int) { return fileDescriptorFleet, []int{23} }
+
 func init() {
 	proto.RegisterType((*HealthCheckRequest)(nil), "rpc.HealthCheckRequest")
 	proto.RegisterType((*HealthCheckResponse)(nil), "rpc.HealthCheckResponse")
@@ -634,6 +660,7 @@ func init() {
 	proto.RegisterType((*NotFound)(nil), "rpc.NotFound")
 	proto.RegisterType((*UnitFile)(nil), "rpc.UnitFile")
 	proto.RegisterType((*UnitOption)(nil), "rpc.UnitOption")
+	proto.RegisterType((*ScaleUnitRequest)(nil), "rpc.ScaleUnitRequest")
 	proto.RegisterEnum("rpc.TargetState", TargetState_name, TargetState_value)
 	proto.RegisterEnum("rpc.HealthCheckResponse_ServingStatus", HealthCheckResponse_ServingStatus_name, HealthCheckResponse_ServingStatus_value)
 }
@@ -672,6 +699,7 @@ type RegistryClient interface {
 	AgentEvents(ctx context.Context, in *MachineProperties, opts ...grpc.CallOption) (Registry_AgentEventsClient, error)
 	// Health check
 	Status(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	ScaleUnit(ctx context.Context, in *ScaleUnitRequest, opts ...grpc.CallOption) (*GenericReply, error)
 }
 
 type registryClient struct {
@@ -858,6 +886,15 @@ func (c *registryClient) Status(ctx context.Context, in *HealthCheckRequest, opt
 	return out, nil
 }
 
+func (c *registryClient) ScaleUnit(ctx context.Context, in *ScaleUnitRequest, opts ...grpc.CallOption) (*GenericReply, error) {
+	out := new(GenericReply)
+	err := grpc.Invoke(ctx, "/rpc.Registry/ScaleUnit", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Registry service
 
 type RegistryServer interface {
@@ -884,6 +921,7 @@ type RegistryServer interface {
 	AgentEvents(*MachineProperties, Registry_AgentEventsServer) error
 	// Health check
 	Status(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	ScaleUnit(context.Context, *ScaleUnitRequest) (*GenericReply, error)
 }
 
 func RegisterRegistryServer(s *grpc.Server, srv RegistryServer) {
@@ -1199,6 +1237,24 @@ func _Registry_Status_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Registry_ScaleUnit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScaleUnitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).ScaleUnit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Registry/ScaleUnit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).ScaleUnit(ctx, req.(*ScaleUnitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Registry_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.Registry",
 	HandlerType: (*RegistryServer)(nil),
@@ -1267,6 +1323,10 @@ var _Registry_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Status",
 			Handler:    _Registry_Status_Handler,
 		},
+		{
+			MethodName: "ScaleUnit",
+			Handler:    _Registry_ScaleUnit_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1723,6 +1783,68 @@ func (m *UnitState) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintFleet(dAtA, i, uint64(len(m.MachineID)))
 		i += copy(dAtA[i:], m.MachineID)
 	}
+	if m.HealthChecked {
+		dAtA[i] = 0x38
+		i++
+		if m.HealthChecked {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Healthy {
+		dAtA[i] = 0x40
+		i++
+		if m.Healthy {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Failures != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.Failures))
+	}
+	if m.ExecMainStatus != 0 {
+		dAtA[i] = 0x50
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.ExecMainStatus))
+	}
+	if m.NRestarts != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.NRestarts))
+	}
+	if m.ActiveEnterTimestamp != 0 {
+		dAtA[i] = 0x60
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.ActiveEnterTimestamp))
+	}
+	if m.InactiveEnterTimestamp != 0 {
+		dAtA[i] = 0x68
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.InactiveEnterTimestamp))
+	}
+	if m.CPUUsageNSec != 0 {
+		dAtA[i] = 0x70
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.CPUUsageNSec))
+	}
+	if m.MemoryCurrent != 0 {
+		dAtA[i] = 0x78
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.MemoryCurrent))
+	}
+	if m.TasksCurrent != 0 {
+		dAtA[i] = 0x80
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.TasksCurrent))
+	}
 	return i, nil
 }
 
@@ -1849,6 +1971,11 @@ func (m *Unit) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintFleet(dAtA, i, uint64(m.DesiredState))
 	}
+	if m.Replicas != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.Replicas))
+	}
 	return i, nil
 }
 
@@ -2042,6 +2169,35 @@ func (m *UnitOption) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *ScaleUnitRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ScaleUnitRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if m.Replicas != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.Replicas))
+	}
+	return i, nil
+}
+
 func encodeFixed64Fleet(dAtA []byte, offset int, v uint64) int {
 	dAtA[offset] = uint8(v)
 	dAtA[offset+1] = uint8(v >> 8)
@@ -2269,6 +2425,36 @@ func (m *UnitState) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovFleet(uint64(l))
 	}
+	if m.HealthChecked {
+		n += 2
+	}
+	if m.Healthy {
+		n += 2
+	}
+	if m.Failures != 0 {
+		n += 1 + sovFleet(uint64(m.Failures))
+	}
+	if m.ExecMainStatus != 0 {
+		n += 1 + sovFleet(uint64(m.ExecMainStatus))
+	}
+	if m.NRestarts != 0 {
+		n += 1 + sovFleet(uint64(m.NRestarts))
+	}
+	if m.ActiveEnterTimestamp != 0 {
+		n += 1 + sovFleet(uint64(m.ActiveEnterTimestamp))
+	}
+	if m.InactiveEnterTimestamp != 0 {
+		n += 1 + sovFleet(uint64(m.InactiveEnterTimestamp))
+	}
+	if m.CPUUsageNSec != 0 {
+		n += 1 + sovFleet(uint64(m.CPUUsageNSec))
+	}
+	if m.MemoryCurrent != 0 {
+		n += 1 + sovFleet(uint64(m.MemoryCurrent))
+	}
+	if m.TasksCurrent != 0 {
+		n += 2 + sovFleet(uint64(m.TasksCurrent))
+	}
 	return n
 }
 
@@ -2323,6 +2509,9 @@ func (m *Unit) Size() (n int) {
 	if m.DesiredState != 0 {
 		n += 1 + sovFleet(uint64(m.DesiredState))
 	}
+	if m.Replicas != 0 {
+		n += 1 + sovFleet(uint64(m.Replicas))
+	}
 	return n
 }
 
@@ -2416,6 +2605,19 @@ func (m *UnitOption) Size() (n int) {
 	return n
 }
 
+func (m *ScaleUnitRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovFleet(uint64(l))
+	}
+	if m.Replicas != 0 {
+		n += 1 + sovFleet(uint64(m.Replicas))
+	}
+	return n
+}
+
 func sovFleet(x uint64) (n int) {
 	for {
 		n++
@@ -3927,6 +4129,198 @@ func (m *UnitState) Unmarshal(dAtA []byte) error {
 			}
 			m.MachineID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HealthChecked", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.HealthChecked = bool(v != 0)
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Healthy", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Healthy = bool(v != 0)
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Failures", wireType)
+			}
+			m.Failures = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Failures |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExecMainStatus", wireType)
+			}
+			m.ExecMainStatus = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExecMainStatus |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NRestarts", wireType)
+			}
+			m.NRestarts = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NRestarts |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ActiveEnterTimestamp", wireType)
+			}
+			m.ActiveEnterTimestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ActiveEnterTimestamp |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InactiveEnterTimestamp", wireType)
+			}
+			m.InactiveEnterTimestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.InactiveEnterTimestamp |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CPUUsageNSec", wireType)
+			}
+			m.CPUUsageNSec = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CPUUsageNSec |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryCurrent", wireType)
+			}
+			m.MemoryCurrent = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MemoryCurrent |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 16:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TasksCurrent", wireType)
+			}
+			m.TasksCurrent = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TasksCurrent |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipFleet(dAtA[iNdEx:])
@@ -4342,6 +4736,25 @@ func (m *Unit) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
+			}
+			m.Replicas = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Replicas |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipFleet(dAtA[iNdEx:])
@@ -4859,6 +5272,104 @@ func (m *UnitOption) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *ScaleUnitRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFleet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ScaleUnitRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ScaleUnitRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFleet
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
+			}
+			m.Replicas = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Replicas |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFleet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthFleet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipFleet(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -4967,75 +5478,90 @@ var (
 func init() { proto.RegisterFile("fleet.proto", fileDescriptorFleet) }
 
 var fileDescriptorFleet = []byte{
-	// 1106 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xd4, 0x56, 0xdb, 0x72, 0xdb, 0x44,
-	0x18, 0x8e, 0xec, 0x38, 0xb1, 0x7f, 0x1f, 0xbb, 0x09, 0xd4, 0x09, 0x43, 0x02, 0xe2, 0xd0, 0x52,
-	0xa8, 0xc3, 0xb8, 0xd3, 0x0e, 0xa4, 0x53, 0x20, 0x89, 0x73, 0xf0, 0x90, 0x3a, 0x1d, 0x39, 0x69,
-	0x87, 0x2b, 0x8f, 0x2c, 0xfd, 0xb1, 0x35, 0x55, 0x24, 0xa3, 0x5d, 0x65, 0x26, 0xf0, 0x02, 0xdc,
-	0xf2, 0x1e, 0x3c, 0x04, 0x57, 0x4c, 0x2f, 0xfb, 0x04, 0x19, 0xc8, 0x93, 0x30, 0x7b, 0x90, 0x2c,
-	0xb9, 0x6a, 0xd3, 0x61, 0xe0, 0x82, 0x3b, 0xfd, 0x87, 0x6f, 0xff, 0xd3, 0xb7, 0xda, 0x1f, 0xca,
-	0xa7, 0x2e, 0x22, 0x6b, 0x4d, 0x02, 0x9f, 0xf9, 0x24, 0x1f, 0x4c, 0xac, 0xd5, 0xbb, 0x23, 0x87,
-	0x8d, 0xc3, 0x61, 0xcb, 0xf2, 0xcf, 0x36, 0x46, 0xfe, 0xc8, 0xdf, 0x10, 0xb6, 0x61, 0x78, 0x2a,
-	0x24, 0x21, 0x88, 0x2f, 0x89, 0xd1, 0x5b, 0x40, 0x0e, 0xd0, 0x74, 0xd9, 0x78, 0x67, 0x8c, 0xd6,
-	0x73, 0x03, 0x7f, 0x0c, 0x91, 0x32, 0xd2, 0x84, 0x45, 0x8a, 0xc1, 0xb9, 0x63, 0x61, 0x53, 0xfb,
-	0x40, 0xbb, 0x5d, 0x32, 0x22, 0x51, 0xff, 0x55, 0x83, 0xa5, 0x14, 0x80, 0x4e, 0x7c, 0x8f, 0x22,
-	0xf9, 0x06, 0x16, 0x28, 0x33, 0x59, 0x48, 0x05, 0xa0, 0xd6, 0xfe, 0xb4, 0x15, 0x4c, 0xac, 0x56,
-	0x86, 0x67, 0xab, 0xcf, 0x4f, 0xf2, 0x46, 0x7d, 0xe1, 0x6d, 0x28, 0x94, 0xbe, 0x09, 0xd5, 0x94,
-	0x81, 0x94, 0x61, 0xf1, 0xa4, 0xf7, 0x7d, 0xef, 0xe8, 0x59, 0xaf, 0x31, 0xc7, 0x85, 0xfe, 0xae,
-	0xf1, 0xb4, 0xdb, 0xdb, 0x6f, 0x68, 0xa4, 0x0e, 0xe5, 0xde, 0xd1, 0xf1, 0x20, 0x52, 0xe4, 0xf4,
-	0x8f, 0xe0, 0xc6, 0x63, 0xd3, 0x1a, 0x3b, 0x1e, 0x3e, 0x09, 0xfc, 0x09, 0x06, 0xcc, 0x41, 0x4a,
-	0x6a, 0x90, 0x73, 0x6c, 0x95, 0x7d, 0xce, 0xb1, 0xf5, 0xcf, 0xa0, 0x72, 0x32, 0xb1, 0x4d, 0x86,
-	0x36, 0x0f, 0x80, 0x64, 0x05, 0x8a, 0xa1, 0xe7, 0xb0, 0x81, 0x63, 0xf3, 0x94, 0xf3, 0xbc, 0x46,
-	0x2e, 0x77, 0x6d, 0xaa, 0xff, 0xa1, 0x41, 0xfd, 0xc4, 0x73, 0x98, 0x70, 0xdc, 0x73, 0x5c, 0x86,
-	0x01, 0x21, 0x30, 0xef, 0x99, 0x67, 0x51, 0x3b, 0xc4, 0x37, 0xd7, 0x8d, 0x4d, 0x3a, 0x6e, 0xe6,
-	0xa4, 0x8e, 0x7f, 0x93, 0xf7, 0x01, 0x5c, 0xdf, 0xb4, 0x07, 0xbc, 0x2c, 0x6c, 0xe6, 0x85, 0xa5,
-	0xc4, 0x35, 0x32, 0xea, 0x87, 0x50, 0x31, 0x2d, 0xe6, 0x9c, 0xa3, 0x72, 0x98, 0x17, 0x0e, 0x65,
-	0xa9, 0x93, 0x2e, 0xef, 0x41, 0x89, 0x86, 0x43, 0x65, 0x2f, 0x08, 0x7b, 0x91, 0x86, 0x43, 0x69,
-	0xfc, 0x02, 0xe0, 0x4c, 0x96, 0x3a, 0x70, 0xec, 0xe6, 0x02, 0xb7, 0x6e, 0x57, 0xaf, 0x2e, 0xd7,
-	0x4b, 0xaa, 0x01, 0xdd, 0x8e, 0x51, 0x52, 0x0e, 0x5d, 0x5b, 0xdf, 0x04, 0xe0, 0x75, 0xa8, 0x12,
-	0xd2, 0x58, 0xed, 0x1a, 0xec, 0x33, 0x58, 0xea, 0x5b, 0x63, 0xb4, 0x43, 0x17, 0xf9, 0x19, 0x11,
-	0x33, 0xb2, 0xfa, 0x90, 0x3e, 0x38, 0x77, 0xcd, 0xc1, 0x3f, 0xc0, 0x3b, 0x27, 0x1e, 0xfd, 0x4f,
-	0x8e, 0x7e, 0x0e, 0xcb, 0x7d, 0xf3, 0x1c, 0xe3, 0xd9, 0xbd, 0xe9, 0xe4, 0x8f, 0xa1, 0x20, 0x5b,
-	0xcc, 0x0f, 0x2d, 0xb7, 0x6b, 0x82, 0xaf, 0x53, 0xa4, 0x34, 0x92, 0x15, 0xc8, 0x33, 0xe6, 0x8a,
-	0x39, 0x16, 0xb6, 0x17, 0xaf, 0x2e, 0xd7, 0xf3, 0xc7, 0xc7, 0x87, 0x06, 0xd7, 0xe9, 0x63, 0x28,
-	0x1d, 0xa0, 0x19, 0xb0, 0x21, 0x9a, 0xff, 0x42, 0xee, 0x6f, 0x8a, 0x54, 0x83, 0xca, 0x3e, 0x7a,
-	0x18, 0x38, 0x96, 0x81, 0x13, 0xf7, 0x42, 0x6f, 0x41, 0x81, 0x27, 0x4a, 0xc9, 0x27, 0x50, 0xe0,
-	0x9c, 0x95, 0x04, 0x2e, 0xb7, 0x4b, 0x71, 0x0d, 0xdb, 0xf3, 0x2f, 0x2e, 0xd7, 0xe7, 0x0c, 0x69,
-	0xd5, 0x1f, 0x49, 0x1a, 0x88, 0xc2, 0x28, 0xd9, 0x80, 0xb2, 0x20, 0xbe, 0x28, 0x30, 0x82, 0xce,
-	0x96, 0x0f, 0x61, 0x0c, 0xd0, 0x7f, 0xd7, 0xa0, 0x14, 0x5b, 0xfe, 0x9f, 0x17, 0xe1, 0x3b, 0xa8,
-	0x45, 0x64, 0xb6, 0x65, 0xeb, 0x5a, 0xe9, 0xd6, 0x11, 0x51, 0x7f, 0xca, 0x27, 0xdd, 0xc3, 0x5f,
-	0x34, 0xa8, 0xa6, 0xcc, 0x99, 0x8d, 0xb8, 0x0f, 0x55, 0x2b, 0x0c, 0x02, 0xf4, 0x54, 0x7b, 0x45,
-	0x47, 0x6a, 0xed, 0x86, 0x38, 0xfd, 0xd8, 0x0c, 0x46, 0xa8, 0xfa, 0x5b, 0x51, 0x6e, 0x59, 0xc5,
-	0xe4, 0xaf, 0x29, 0x66, 0x0d, 0x8a, 0x3c, 0x81, 0x9e, 0xea, 0xfc, 0x6c, 0x12, 0xfa, 0x4f, 0x30,
-	0xff, 0xda, 0x04, 0x6f, 0xc1, 0x3c, 0xaf, 0x47, 0x91, 0xbe, 0x1a, 0x4f, 0x7d, 0xcf, 0x71, 0x51,
-	0x15, 0x2c, 0x1c, 0x78, 0x25, 0x36, 0x52, 0x27, 0xc0, 0xe4, 0x04, 0x33, 0x2b, 0x51, 0x6e, 0x42,
-	0xd2, 0x7f, 0x06, 0xf2, 0xd8, 0xbc, 0x18, 0x62, 0xba, 0x55, 0xb7, 0x55, 0x54, 0x4d, 0x44, 0xcd,
-	0xe8, 0xf5, 0x41, 0x14, 0xf6, 0x73, 0x28, 0x7a, 0x3e, 0x3b, 0xf5, 0x43, 0xcf, 0x4e, 0xe5, 0xd8,
-	0xf3, 0xd9, 0x1e, 0x57, 0x1e, 0xcc, 0x19, 0xb1, 0xc3, 0x76, 0x0d, 0x2a, 0x0e, 0x1d, 0x44, 0xbf,
-	0x12, 0x5b, 0x47, 0x28, 0x89, 0xe0, 0x22, 0xe6, 0x7a, 0x2a, 0xe6, 0xf4, 0x6a, 0xfc, 0xb3, 0x50,
-	0x00, 0xc5, 0xb1, 0x49, 0x07, 0x1c, 0xa8, 0x03, 0x14, 0x23, 0x1f, 0xbd, 0x23, 0x67, 0xc1, 0xdb,
-	0x47, 0xbe, 0x82, 0x8a, 0xb8, 0x58, 0xfe, 0x84, 0x39, 0xbe, 0x17, 0x31, 0xab, 0x1e, 0x47, 0x3e,
-	0x12, 0x7a, 0xd5, 0x65, 0x71, 0x07, 0xa5, 0x86, 0xea, 0x4f, 0xe4, 0x05, 0x95, 0xa2, 0x7c, 0x7c,
-	0x2d, 0xfe, 0x39, 0x7d, 0x7c, 0x85, 0x18, 0x4f, 0x34, 0x97, 0x98, 0xe8, 0x32, 0x14, 0xce, 0x4d,
-	0x37, 0x8c, 0xae, 0x98, 0x14, 0xee, 0xdc, 0x87, 0x72, 0x62, 0x48, 0xa4, 0x02, 0xc5, 0x6e, 0x6f,
-	0x6b, 0xe7, 0xb8, 0xfb, 0x74, 0xb7, 0x31, 0x47, 0x00, 0x16, 0x0e, 0x8f, 0xb6, 0x3a, 0xbb, 0x9d,
-	0x86, 0xc6, 0x2d, 0x87, 0x5b, 0x27, 0xbd, 0x9d, 0x83, 0xdd, 0x4e, 0x23, 0xd7, 0xfe, 0x6d, 0x11,
-	0x8a, 0x06, 0x8e, 0x1c, 0xca, 0x82, 0x0b, 0xf2, 0x35, 0xdc, 0xd8, 0x47, 0x36, 0x73, 0x6f, 0xea,
-	0x49, 0xca, 0x30, 0x0c, 0x56, 0x97, 0x5e, 0x9d, 0x26, 0x25, 0x9b, 0xd0, 0x98, 0x85, 0x92, 0x29,
-	0xd9, 0x38, 0x73, 0x57, 0x6f, 0x0a, 0x31, 0x93, 0x2c, 0x8b, 0xfb, 0xc8, 0xb2, 0x20, 0xb5, 0x29,
-	0x44, 0x98, 0x6f, 0x41, 0x51, 0x79, 0x66, 0xe4, 0x05, 0xb1, 0x82, 0x92, 0xbb, 0xfc, 0x07, 0xca,
-	0xa6, 0xff, 0xb0, 0xcc, 0x73, 0xa7, 0xe6, 0x07, 0x50, 0x4d, 0xba, 0x53, 0xb2, 0x9c, 0x76, 0x50,
-	0x11, 0xea, 0x69, 0x2d, 0x25, 0x0f, 0x80, 0xec, 0xb8, 0x68, 0x06, 0x82, 0x66, 0xf1, 0xd3, 0x30,
-	0x13, 0xec, 0x86, 0x10, 0x93, 0xff, 0x73, 0x72, 0x07, 0x60, 0x27, 0x40, 0x93, 0xc9, 0xaa, 0xa6,
-	0x54, 0xcd, 0xf2, 0xdd, 0x80, 0x72, 0x07, 0x29, 0x0b, 0xfc, 0x8b, 0xac, 0x0e, 0x65, 0x00, 0xda,
-	0x50, 0x4d, 0xe7, 0x53, 0x8b, 0x36, 0x33, 0x29, 0x67, 0x61, 0xee, 0x41, 0xdd, 0xc0, 0x33, 0x3f,
-	0xf1, 0x92, 0xbe, 0x45, 0xa0, 0x47, 0x50, 0x4d, 0x3d, 0xbe, 0x64, 0x45, 0x32, 0x23, 0xe3, 0x41,
-	0xce, 0x82, 0x3f, 0x84, 0x4a, 0x72, 0xdf, 0x20, 0xcd, 0x14, 0xaf, 0x12, 0x7b, 0x42, 0x36, 0x98,
-	0xf4, 0xe5, 0xc4, 0x92, 0xac, 0xcf, 0xf8, 0xd1, 0x64, 0x81, 0xbf, 0x85, 0x5a, 0x7a, 0x21, 0x21,
-	0xab, 0xaa, 0x58, 0xfa, 0x76, 0xd1, 0x37, 0xa1, 0xbc, 0x35, 0x42, 0x8f, 0xed, 0x9e, 0xa3, 0xc7,
-	0x28, 0x79, 0x57, 0xd1, 0x74, 0x66, 0x23, 0x55, 0xc8, 0xe4, 0x12, 0xfa, 0xa5, 0x46, 0x1e, 0xc2,
-	0x82, 0x5a, 0x78, 0x6f, 0xbe, 0xba, 0x31, 0xcb, 0x88, 0xcd, 0xd7, 0xad, 0xd2, 0xdb, 0x8d, 0x97,
-	0x7f, 0xad, 0x69, 0x2f, 0xae, 0xd6, 0xb4, 0x97, 0x57, 0x6b, 0xda, 0x9f, 0x57, 0x6b, 0xda, 0x70,
-	0x41, 0x6c, 0xf5, 0xf7, 0xfe, 0x0e, 0x00, 0x00, 0xff, 0xff, 0x2d, 0x64, 0x9f, 0x8f, 0x18, 0x0c,
-	0x00, 0x00,
+	// 1354 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x57, 0x4d, 0x6f, 0xdb, 0x46,
+	0x13, 0x36, 0xe5, 0x2f, 0x6a, 0x24, 0xd1, 0xca, 0xc6, 0x49, 0x18, 0xbf, 0x88, 0xed, 0x97, 0x69,
+	0x1a, 0x37, 0x6d, 0xe4, 0xc2, 0x69, 0x82, 0xd4, 0x41, 0xda, 0xfa, 0x2b, 0xb6, 0xd1, 0x44, 0x0e,
+	0x28, 0x2b, 0x41, 0x4f, 0x04, 0x45, 0x8e, 0x25, 0x22, 0x12, 0xa9, 0x72, 0x97, 0x46, 0x8d, 0xfe,
+	0x81, 0x5e, 0x7b, 0xe9, 0x4f, 0x2a, 0x72, 0xcc, 0xb9, 0x07, 0xa3, 0x75, 0xff, 0x48, 0xb1, 0x1f,
+	0xa4, 0x44, 0x87, 0x89, 0x83, 0xa2, 0x3d, 0xf4, 0xa6, 0x99, 0x79, 0x9e, 0x9d, 0x99, 0xdd, 0x67,
+	0x47, 0x4b, 0xa8, 0x1c, 0xf5, 0x11, 0x59, 0x63, 0x18, 0x47, 0x2c, 0x22, 0x93, 0xf1, 0xd0, 0x5b,
+	0xb8, 0xdb, 0x0d, 0x58, 0x2f, 0xe9, 0x34, 0xbc, 0x68, 0xb0, 0xda, 0x8d, 0xba, 0xd1, 0xaa, 0x88,
+	0x75, 0x92, 0x23, 0x61, 0x09, 0x43, 0xfc, 0x92, 0x1c, 0xab, 0x01, 0x64, 0x0f, 0xdd, 0x3e, 0xeb,
+	0x6d, 0xf5, 0xd0, 0x7b, 0x65, 0xe3, 0xf7, 0x09, 0x52, 0x46, 0x4c, 0x98, 0xa5, 0x18, 0x1f, 0x07,
+	0x1e, 0x9a, 0xda, 0xb2, 0xb6, 0x52, 0xb6, 0x53, 0xd3, 0xfa, 0x59, 0x83, 0xcb, 0x39, 0x02, 0x1d,
+	0x46, 0x21, 0x45, 0xf2, 0x15, 0xcc, 0x50, 0xe6, 0xb2, 0x84, 0x0a, 0x82, 0xb1, 0xf6, 0x71, 0x23,
+	0x1e, 0x7a, 0x8d, 0x02, 0x64, 0xa3, 0xc5, 0x57, 0x0a, 0xbb, 0x2d, 0x81, 0xb6, 0x15, 0xcb, 0x5a,
+	0x87, 0x5a, 0x2e, 0x40, 0x2a, 0x30, 0xdb, 0x6e, 0x7e, 0xdb, 0x3c, 0x78, 0xd9, 0xac, 0x4f, 0x70,
+	0xa3, 0xb5, 0x63, 0xbf, 0xd8, 0x6f, 0xee, 0xd6, 0x35, 0x32, 0x07, 0x95, 0xe6, 0xc1, 0xa1, 0x93,
+	0x3a, 0x4a, 0xd6, 0x4d, 0xb8, 0xf4, 0xcc, 0xf5, 0x7a, 0x41, 0x88, 0xcf, 0xe3, 0x68, 0x88, 0x31,
+	0x0b, 0x90, 0x12, 0x03, 0x4a, 0x81, 0xaf, 0xaa, 0x2f, 0x05, 0xbe, 0xf5, 0x09, 0x54, 0xdb, 0x43,
+	0xdf, 0x65, 0xe8, 0xf3, 0x04, 0x48, 0xae, 0x83, 0x9e, 0x84, 0x01, 0x73, 0x02, 0x9f, 0x97, 0x3c,
+	0xc9, 0x7b, 0xe4, 0xf6, 0xbe, 0x4f, 0xad, 0x5f, 0x35, 0x98, 0x6b, 0x87, 0x01, 0x13, 0xc0, 0x27,
+	0x41, 0x9f, 0x61, 0x4c, 0x08, 0x4c, 0x85, 0xee, 0x20, 0xdd, 0x0e, 0xf1, 0x9b, 0xfb, 0x7a, 0x2e,
+	0xed, 0x99, 0x25, 0xe9, 0xe3, 0xbf, 0xc9, 0x0d, 0x80, 0x7e, 0xe4, 0xfa, 0x0e, 0x6f, 0x0b, 0xcd,
+	0x49, 0x11, 0x29, 0x73, 0x8f, 0xcc, 0xfa, 0x7f, 0xa8, 0xba, 0x1e, 0x0b, 0x8e, 0x51, 0x01, 0xa6,
+	0x04, 0xa0, 0x22, 0x7d, 0x12, 0xf2, 0x3f, 0x28, 0xd3, 0xa4, 0xa3, 0xe2, 0xd3, 0x22, 0xae, 0xd3,
+	0xa4, 0x23, 0x83, 0x9f, 0x01, 0x0c, 0x64, 0xab, 0x4e, 0xe0, 0x9b, 0x33, 0x3c, 0xba, 0x59, 0x3b,
+	0x3b, 0x5d, 0x2a, 0xab, 0x0d, 0xd8, 0xdf, 0xb6, 0xcb, 0x0a, 0xb0, 0xef, 0x5b, 0xeb, 0x00, 0xbc,
+	0x0f, 0xd5, 0x42, 0x9e, 0xab, 0x5d, 0xc0, 0x7d, 0x09, 0x97, 0x5b, 0x5e, 0x0f, 0xfd, 0xa4, 0x8f,
+	0x7c, 0x8d, 0x54, 0x19, 0x45, 0xfb, 0x90, 0x5f, 0xb8, 0x74, 0xc1, 0xc2, 0xdf, 0xc1, 0x95, 0x76,
+	0x48, 0xff, 0x95, 0xa5, 0x5f, 0xc1, 0x7c, 0xcb, 0x3d, 0xc6, 0xec, 0xec, 0xde, 0xb7, 0xf2, 0x47,
+	0x30, 0x2d, 0xb7, 0x98, 0x2f, 0x5a, 0x59, 0x33, 0x84, 0x5e, 0x47, 0x4c, 0x19, 0x24, 0xd7, 0x61,
+	0x92, 0xb1, 0xbe, 0x38, 0xc7, 0xe9, 0xcd, 0xd9, 0xb3, 0xd3, 0xa5, 0xc9, 0xc3, 0xc3, 0xa7, 0x36,
+	0xf7, 0x59, 0x3d, 0x28, 0xef, 0xa1, 0x1b, 0xb3, 0x0e, 0xba, 0xff, 0x40, 0xed, 0xef, 0xcb, 0x64,
+	0x40, 0x75, 0x17, 0x43, 0x8c, 0x03, 0xcf, 0xc6, 0x61, 0xff, 0xc4, 0x6a, 0xc0, 0x34, 0x2f, 0x94,
+	0x92, 0x5b, 0x30, 0xcd, 0x35, 0x2b, 0x05, 0x5c, 0x59, 0x2b, 0x67, 0x3d, 0x6c, 0x4e, 0xbd, 0x3e,
+	0x5d, 0x9a, 0xb0, 0x65, 0xd4, 0x7a, 0x0c, 0x90, 0x35, 0x46, 0xc9, 0x2a, 0x54, 0x84, 0xf0, 0x45,
+	0x83, 0x29, 0xf5, 0x7c, 0xfb, 0x90, 0x64, 0x04, 0xeb, 0xb7, 0x29, 0x28, 0x67, 0x91, 0xff, 0xe4,
+	0x45, 0x20, 0xb7, 0xc0, 0xe8, 0x89, 0x51, 0xe4, 0x78, 0x7c, 0x16, 0xa1, 0x6f, 0xce, 0x2e, 0x6b,
+	0x2b, 0xba, 0x5d, 0xeb, 0x8d, 0x06, 0x14, 0xfa, 0x7c, 0xec, 0x49, 0xc7, 0x89, 0xa9, 0x8b, 0x78,
+	0x6a, 0x92, 0x05, 0xd0, 0x8f, 0xdc, 0xa0, 0x9f, 0xc4, 0x48, 0xcd, 0x32, 0x3f, 0x22, 0x3b, 0xb3,
+	0xc9, 0x0a, 0xd4, 0xf1, 0x07, 0xf4, 0x9c, 0x81, 0x1b, 0x84, 0x8e, 0x1a, 0x82, 0x20, 0x30, 0x06,
+	0xf7, 0x3f, 0x73, 0x83, 0x50, 0xcd, 0xb4, 0x1b, 0x00, 0xa1, 0x13, 0x23, 0x65, 0x6e, 0xcc, 0xa8,
+	0x59, 0x59, 0xd6, 0x56, 0x6a, 0x76, 0x39, 0xb4, 0x95, 0x83, 0x7c, 0x01, 0x57, 0xd5, 0x9e, 0x60,
+	0xc8, 0x30, 0x76, 0x58, 0x30, 0xe0, 0xa1, 0xc1, 0xd0, 0xac, 0x2e, 0x6b, 0x2b, 0x53, 0xf6, 0xbc,
+	0x8c, 0xee, 0xf0, 0xe0, 0x61, 0x1a, 0x23, 0x0f, 0xc1, 0x0c, 0xc2, 0x77, 0xf0, 0x6a, 0x82, 0x77,
+	0x35, 0x08, 0x0b, 0x99, 0x0f, 0xc0, 0xf0, 0x86, 0x89, 0x93, 0x50, 0xb7, 0x8b, 0x4e, 0x48, 0xd1,
+	0x33, 0x0d, 0x8e, 0xdf, 0xac, 0x9f, 0x9d, 0x2e, 0x55, 0xb7, 0x9e, 0xb7, 0xdb, 0x3c, 0xd0, 0x6c,
+	0xa1, 0x67, 0x57, 0xbd, 0x61, 0x22, 0x2d, 0x8a, 0x1e, 0xdf, 0xcd, 0x01, 0x0e, 0xa2, 0xf8, 0xc4,
+	0xf1, 0x92, 0x38, 0xc6, 0x90, 0x99, 0x73, 0x22, 0x4f, 0x4d, 0x7a, 0xb7, 0xa4, 0x93, 0xdc, 0x84,
+	0x1a, 0x73, 0xe9, 0x2b, 0x9a, 0xa1, 0xea, 0x02, 0x55, 0x15, 0x4e, 0x05, 0xb2, 0xbe, 0x01, 0x23,
+	0x1d, 0x33, 0xbe, 0x14, 0x75, 0x23, 0x2f, 0x6a, 0x22, 0x94, 0x99, 0xc3, 0xe4, 0xd5, 0xfd, 0x93,
+	0x06, 0xb5, 0x5c, 0xb8, 0x50, 0xa2, 0xf7, 0xa1, 0xa6, 0xca, 0x70, 0x46, 0xd7, 0xde, 0x58, 0xab,
+	0x8b, 0xd5, 0x0f, 0xdd, 0xb8, 0x8b, 0x4a, 0xf9, 0x55, 0x05, 0x2b, 0x92, 0xd9, 0xe4, 0x05, 0xf3,
+	0x67, 0x11, 0x74, 0x5e, 0x40, 0x53, 0xdd, 0x89, 0xf3, 0x45, 0x58, 0xbf, 0x68, 0x30, 0xf5, 0xce,
+	0x0a, 0x6f, 0xc3, 0x14, 0x6f, 0x48, 0xcd, 0xa3, 0x5a, 0x76, 0x21, 0x9f, 0x04, 0x7d, 0x54, 0x1d,
+	0x0b, 0x00, 0x6f, 0xc5, 0x47, 0x1a, 0xc4, 0x38, 0x7e, 0xb9, 0x0a, 0x5b, 0x51, 0x30, 0xd9, 0xca,
+	0x02, 0xe8, 0x31, 0x0e, 0xfb, 0x81, 0xe7, 0x52, 0x71, 0x9b, 0xa6, 0xed, 0xcc, 0xb6, 0x7e, 0x04,
+	0xf2, 0xcc, 0x3d, 0xe9, 0x60, 0x7e, 0x1f, 0x57, 0x54, 0x45, 0xda, 0xb2, 0x56, 0x7c, 0x10, 0x7b,
+	0x69, 0x49, 0x9f, 0x82, 0x1e, 0x46, 0xec, 0x28, 0x4a, 0x42, 0x3f, 0x57, 0x7f, 0x33, 0x62, 0x4f,
+	0xb8, 0x73, 0x6f, 0xc2, 0xce, 0x00, 0x9b, 0x06, 0x54, 0x03, 0xea, 0xa4, 0xff, 0x00, 0xbe, 0x85,
+	0x50, 0x16, 0xc9, 0x45, 0xce, 0xa5, 0x5c, 0xce, 0xd1, 0x44, 0xfb, 0x7b, 0xa9, 0x00, 0xf4, 0x9e,
+	0x4b, 0x1d, 0x4e, 0xb4, 0x00, 0xf4, 0x14, 0x63, 0x6d, 0x83, 0x9e, 0x6e, 0x2d, 0x79, 0x08, 0x55,
+	0x31, 0x0f, 0xa3, 0x21, 0x0b, 0xa2, 0x30, 0x95, 0xdd, 0x5c, 0x96, 0xf9, 0x40, 0xf8, 0xd5, 0x09,
+	0x54, 0x92, 0xcc, 0x43, 0xad, 0xe7, 0x72, 0xae, 0x4a, 0x53, 0xbe, 0x99, 0x3c, 0xfe, 0x73, 0xf4,
+	0x66, 0x12, 0x66, 0x76, 0xda, 0xa5, 0xb1, 0xd3, 0x9e, 0x87, 0xe9, 0x63, 0xb7, 0x9f, 0xa4, 0x93,
+	0x51, 0x1a, 0xd6, 0x26, 0xd4, 0x5b, 0x9e, 0x7b, 0xf1, 0xdf, 0xe2, 0xf8, 0x59, 0x96, 0xf2, 0x67,
+	0x79, 0xe7, 0x3e, 0x54, 0xc6, 0x44, 0x40, 0xaa, 0xa0, 0xef, 0x37, 0x37, 0xb6, 0x0e, 0xf7, 0x5f,
+	0xec, 0xd4, 0x27, 0x08, 0xc0, 0xcc, 0xd3, 0x83, 0x8d, 0xed, 0x9d, 0xed, 0xba, 0xc6, 0x23, 0x4f,
+	0x37, 0xda, 0xcd, 0xad, 0xbd, 0x9d, 0xed, 0x7a, 0x69, 0xed, 0xcf, 0x59, 0xd0, 0x6d, 0xec, 0x06,
+	0x94, 0xc5, 0x27, 0xe4, 0x4b, 0xb8, 0xb4, 0x8b, 0xec, 0xdc, 0xc5, 0x9c, 0x1b, 0x97, 0x24, 0xc3,
+	0x78, 0xe1, 0xf2, 0xdb, 0x8a, 0xa0, 0x64, 0x1d, 0xea, 0xe7, 0xa9, 0x64, 0x24, 0x66, 0x7e, 0x35,
+	0x16, 0xae, 0x09, 0xb3, 0x50, 0x70, 0xb3, 0xbb, 0xc8, 0x8a, 0x28, 0xc6, 0x88, 0x22, 0xc2, 0xb7,
+	0x41, 0x57, 0xc8, 0x82, 0xba, 0x20, 0x73, 0x50, 0x72, 0x17, 0xaa, 0x0a, 0x28, 0xb7, 0xa3, 0x70,
+	0xdd, 0x51, 0xf8, 0x01, 0xd4, 0xc6, 0xe1, 0x94, 0xcc, 0xe7, 0x01, 0x2a, 0xc3, 0x5c, 0xde, 0x4b,
+	0xc9, 0x03, 0x20, 0x5b, 0x7d, 0x74, 0x63, 0x21, 0xd5, 0xec, 0x55, 0x70, 0x2e, 0xd9, 0x25, 0x61,
+	0x8e, 0xff, 0x95, 0x93, 0x3b, 0x00, 0x5b, 0x31, 0xba, 0x4c, 0x76, 0x35, 0x92, 0x7b, 0x11, 0x76,
+	0x15, 0x2a, 0xdb, 0x48, 0x59, 0x1c, 0x9d, 0x14, 0xed, 0x50, 0x01, 0x61, 0x0d, 0x6a, 0xf9, 0x7a,
+	0x8c, 0xf4, 0x51, 0x2e, 0xed, 0x22, 0xce, 0x3d, 0x98, 0xb3, 0x71, 0x10, 0x8d, 0x3d, 0xa2, 0x3e,
+	0x20, 0xd1, 0x63, 0xa8, 0xe5, 0xde, 0x5d, 0xe4, 0xba, 0x54, 0x46, 0xc1, 0x5b, 0xac, 0x88, 0xfe,
+	0x08, 0xaa, 0xe3, 0x4f, 0x4d, 0x62, 0xe6, 0x74, 0x35, 0x76, 0x17, 0x8a, 0xc9, 0xa4, 0x25, 0x4f,
+	0x6c, 0x5c, 0xf5, 0x05, 0xc3, 0xaa, 0x88, 0xfc, 0x35, 0x18, 0xf9, 0xb7, 0x28, 0x59, 0x50, 0xcd,
+	0xd2, 0x0f, 0xcb, 0xbe, 0x0e, 0x95, 0x8d, 0x2e, 0x86, 0x6c, 0xe7, 0x18, 0x43, 0x46, 0xc9, 0x55,
+	0x25, 0xd3, 0x73, 0x1f, 0x23, 0x8a, 0x39, 0xfe, 0xfd, 0xf1, 0xb9, 0x46, 0x1e, 0xc1, 0x8c, 0x7a,
+	0x17, 0x5c, 0x7b, 0xfb, 0x63, 0x49, 0x66, 0x34, 0xdf, 0xf5, 0x15, 0x45, 0xee, 0x43, 0x39, 0x9b,
+	0x14, 0xe4, 0x8a, 0xea, 0xd6, 0xbd, 0xa8, 0xde, 0xcd, 0xfa, 0x9b, 0x3f, 0x16, 0xb5, 0xd7, 0x67,
+	0x8b, 0xda, 0x9b, 0xb3, 0x45, 0xed, 0xf7, 0xb3, 0x45, 0xad, 0x33, 0x23, 0xbe, 0x03, 0xef, 0xfd,
+	0x35, 0x00, 0x89, 0xf3, 0x3b, 0x37, 0x4a, 0x0e, 0x00, 0x00,
 }
@@ -0,0 +1,136 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"time"
+
+	etcd "github.com/coreos/etcd/client"
+	"golang.org/x/net/context"
+)
+
+// EngineEvent is a scheduling decision taken by an engine leader.
+type EngineEvent struct {
+	Time time.Time
+	// LeaderID is the ID of the machine of the engine leader which
+	// took the decision.
+	LeaderID string
+	// Type is one of schedule, unschedule, reschedule, create and
+	// destroy.
+	Type     string
+	UnitName string
+	// MachineID is the machine the Unit was scheduled to or unscheduled
+	// from. PreviousMachineID is the machine a rescheduled Unit was
+	// unscheduled from.
+	MachineID         string
+	PreviousMachineID string
+	Reason            string
+}
+
+// EngineExclusion keeps a Unit away from a machine it failed too often on,
+// as decided by the engine leader.
+type EngineExclusion struct {
+	UnitName  string
+	MachineID string
+	Until     time.Time
+}
+
+// EngineScheduler implements the ClusterRegistry interface
+func (r *EtcdRegistry) EngineScheduler() (string, error) {
+	res, err := r.kAPI.Get(context.Background(), r.engineSchedulerPath(), nil)
+	if err != nil {
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return "", err
+	}
+
+	return res.Node.Value, nil
+}
+
+// SetEngineScheduler implements the ClusterRegistry interface
+func (r *EtcdRegistry) SetEngineScheduler(name string) error {
+	_, err := r.kAPI.Set(context.Background(), r.engineSchedulerPath(), name, nil)
+	return err
+}
+
+func (r *EtcdRegistry) engineSchedulerPath() string {
+	return r.prefixed("/engine/scheduler")
+}
+
+// EngineEvents implements the ClusterRegistry interface
+func (r *EtcdRegistry) EngineEvents() ([]EngineEvent, error) {
+	res, err := r.kAPI.Get(context.Background(), r.engineEventsPath(), nil)
+	if err != nil {
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	var events []EngineEvent
+	if err := json.Unmarshal([]byte(res.Node.Value), &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// SetEngineEvents implements the ClusterRegistry interface
+func (r *EtcdRegistry) SetEngineEvents(events []EngineEvent) error {
+	val, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.kAPI.Set(context.Background(), r.engineEventsPath(), string(val), nil)
+	return err
+}
+
+func (r *EtcdRegistry) engineEventsPath() string {
+	return r.prefixed("/engine/events")
+}
+
+// EngineExclusions implements the ClusterRegistry interface
+func (r *EtcdRegistry) EngineExclusions() ([]EngineExclusion, error) {
+	res, err := r.kAPI.Get(context.Background(), r.engineExclusionsPath(), nil)
+	if err != nil {
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	var exclusions []EngineExclusion
+	if err := json.Unmarshal([]byte(res.Node.Value), &exclusions); err != nil {
+		return nil, err
+	}
+	return exclusions, nil
+}
+
+// SetEngineExclusions implements the ClusterRegistry interface
+func (r *EtcdRegistry) SetEngineExclusions(exclusions []EngineExclusion) error {
+	val, err := json.Marshal(exclusions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.kAPI.Set(context.Background(), r.engineExclusionsPath(), string(val), nil)
+	return err
+}
+
+func (r *EtcdRegistry) engineExclusionsPath() string {
+	return r.prefixed("/engine/exclusions")
+}
@@ -65,6 +65,98 @@ func (es *etcdEventStream) Next(stop chan struct{}) chan pkg.Event {
 	return evchan
 }
 
+// UnitChangeStream emits the name of every Unit whose unit file, target
+// state, target machine or number of replicas changes in the Registry. An
+// empty name is emitted when changes may have been missed, e.g. because the
+// watch was interrupted, in which case every Unit is to be considered
+// changed. The channel is closed once stop is closed.
+type UnitChangeStream interface {
+	Changes(stop <-chan struct{}) <-chan string
+}
+
+type etcdUnitChangeStream struct {
+	kAPI       etcd.KeysAPI
+	rootPrefix string
+}
+
+func NewEtcdUnitChangeStream(kAPI etcd.KeysAPI, rootPrefix string) UnitChangeStream {
+	return &etcdUnitChangeStream{kAPI, rootPrefix}
+}
+
+func (cs *etcdUnitChangeStream) Changes(stop <-chan struct{}) <-chan string {
+	changes := make(chan string)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	emit := func(name string) bool {
+		select {
+		case changes <- name:
+			return true
+		case <-stop:
+			return false
+		}
+	}
+
+	go func() {
+		defer close(changes)
+
+		key := path.Join(cs.rootPrefix, jobPrefix)
+		for {
+			watcher := cs.kAPI.Watcher(key, &etcd.WatcherOptions{Recursive: true})
+			log.Debugf("Creating etcd watcher: %s", key)
+
+			for {
+				res, err := watcher.Next(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Errorf("etcd watcher %v returned error: %v", key, err)
+					}
+					break
+				}
+				if name, ok := parseUnitName(res, cs.rootPrefix); ok && !emit(name) {
+					return
+				}
+			}
+
+			// Let's not slam the etcd server in the event that we know
+			// an unexpected error occurred.
+			select {
+			case <-stop:
+				log.Debugf("Gracefully closing etcd watch loop: key=%s", key)
+				return
+			case <-time.After(time.Second):
+			}
+
+			// changes between the error and the new watcher are lost
+			if !emit("") {
+				return
+			}
+		}
+	}()
+
+	return changes
+}
+
+// parseUnitName returns the name of the Unit a change in the Registry
+// applies to.
+func parseUnitName(res *etcd.Response, prefix string) (name string, ok bool) {
+	if res == nil || res.Node == nil {
+		return
+	}
+
+	dir := path.Join(prefix, jobPrefix) + "/"
+	if !strings.HasPrefix(res.Node.Key, dir) {
+		return
+	}
+
+	name = strings.SplitN(strings.TrimPrefix(res.Node.Key, dir), "/", 2)[0]
+	return name, name != ""
+}
+
 func parse(res *etcd.Response, prefix string) (ev pkg.Event, ok bool) {
 	if res == nil || res.Node == nil {
 		return
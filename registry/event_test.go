@@ -92,3 +92,34 @@ func TestFilterEtcdEvents(t *testing.T) {
 		}
 	}
 }
+
+func TestParseUnitName(t *testing.T) {
+	tests := []struct {
+		in   string
+		name string
+		ok   bool
+	}{
+		{in: "", ok: false},
+		{in: "/fleet", ok: false},
+		{in: "/fleet/job", ok: false},
+		{in: "/fleet/job/", ok: false},
+		{in: "/fleet/jobs/foo.service", ok: false},
+		{in: "/fleet/machines/asdf/object", ok: false},
+		{in: "/fleet/job/foo.service", name: "foo.service", ok: true},
+		{in: "/fleet/job/foo.service/object", name: "foo.service", ok: true},
+		{in: "/fleet/job/foo.service/target", name: "foo.service", ok: true},
+		{in: "/fleet/job/web@.service/replicas", name: "web@.service", ok: true},
+	}
+
+	for i, tt := range tests {
+		res := &etcd.Response{
+			Node: &etcd.Node{
+				Key: tt.in,
+			},
+		}
+		name, ok := parseUnitName(res, "/fleet")
+		if ok != tt.ok || name != tt.name {
+			t.Errorf("case %d: expected (%q, %t), got (%q, %t)", i, tt.name, tt.ok, name, ok)
+		}
+	}
+}
@@ -47,6 +47,7 @@ type FakeRegistry struct {
 	machines      []machine.MachineState
 	jobStates     map[string]map[string]*unit.UnitState
 	jobs          map[string]job.Job
+	replicas      map[string]int
 	daemonVersion *semver.Version
 }
 
@@ -115,6 +116,7 @@ func (f *FakeRegistry) Units() ([]job.Unit, error) {
 			Unit:        j.Unit,
 			TargetState: j.TargetState,
 		}
+		f.unsafeSetReplicas(&u)
 		units[i] = u
 	}
 
@@ -159,9 +161,22 @@ func (f *FakeRegistry) Unit(name string) (*job.Unit, error) {
 		Unit:        j.Unit,
 		TargetState: j.TargetState,
 	}
+	f.unsafeSetReplicas(&u)
 	return &u, nil
 }
 
+// unsafeSetReplicas fills the Replicas of a replicated template unit
+func (f *FakeRegistry) unsafeSetReplicas(u *job.Unit) {
+	replicas, ok := u.RequiredReplicas()
+	if !ok {
+		return
+	}
+	if scaled, ok := f.replicas[u.Name]; ok {
+		replicas = scaled
+	}
+	u.Replicas = replicas
+}
+
 func (f *FakeRegistry) ScheduledUnit(name string) (*job.ScheduledUnit, error) {
 	f.RLock()
 	defer f.RUnlock()
@@ -202,6 +217,21 @@ func (f *FakeRegistry) DestroyUnit(name string) error {
 	defer f.Unlock()
 
 	delete(f.jobs, name)
+	delete(f.replicas, name)
+	return nil
+}
+
+func (f *FakeRegistry) ScaleUnit(name string, replicas int) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if _, ok := f.jobs[name]; !ok {
+		return errors.New("job does not exist")
+	}
+	if f.replicas == nil {
+		f.replicas = make(map[string]int)
+	}
+	f.replicas[name] = replicas
 	return nil
 }
 
@@ -241,6 +271,21 @@ func (f *FakeRegistry) ScheduleUnit(name string, machID string) error {
 	return nil
 }
 
+func (f *FakeRegistry) UnscheduleUnit(name string, machID string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	j, ok := f.jobs[name]
+	if !ok || j.TargetMachineID != machID {
+		return nil
+	}
+
+	j.TargetMachineID = ""
+	f.jobs[name] = j
+
+	return nil
+}
+
 func (f *FakeRegistry) SaveUnitState(jobName string, unitState *unit.UnitState, ttl time.Duration) {
 	f.Lock()
 	defer f.Unlock()
@@ -332,6 +377,18 @@ func (f *FakeRegistry) DeleteMachineMetadata(machID string, key string) error {
 	return nil
 }
 
+func (f *FakeRegistry) SetMachineMaintenance(machID string, mode string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	for i := range f.machines {
+		if f.machines[i].ID == machID {
+			f.machines[i].Maintenance = mode
+		}
+	}
+	return nil
+}
+
 func (f *FakeRegistry) MachineState(machID string) (machine.MachineState, error) {
 	f.RLock()
 	defer f.RUnlock()
@@ -355,6 +412,9 @@ func NewFakeClusterRegistry(dVersion *semver.Version, eVersion int) *FakeCluster
 type FakeClusterRegistry struct {
 	dVersion *semver.Version
 	eVersion int
+	eSched   string
+	eEvents  []EngineEvent
+	eExcl    []EngineExclusion
 }
 
 func (fc *FakeClusterRegistry) LatestDaemonVersion() (*semver.Version, error) {
@@ -374,6 +434,33 @@ func (fc *FakeClusterRegistry) UpdateEngineVersion(from, to int) error {
 	return nil
 }
 
+func (fc *FakeClusterRegistry) EngineScheduler() (string, error) {
+	return fc.eSched, nil
+}
+
+func (fc *FakeClusterRegistry) SetEngineScheduler(name string) error {
+	fc.eSched = name
+	return nil
+}
+
+func (fc *FakeClusterRegistry) EngineEvents() ([]EngineEvent, error) {
+	return fc.eEvents, nil
+}
+
+func (fc *FakeClusterRegistry) SetEngineEvents(events []EngineEvent) error {
+	fc.eEvents = events
+	return nil
+}
+
+func (fc *FakeClusterRegistry) EngineExclusions() ([]EngineExclusion, error) {
+	return fc.eExcl, nil
+}
+
+func (fc *FakeClusterRegistry) SetEngineExclusions(exclusions []EngineExclusion) error {
+	fc.eExcl = exclusions
+	return nil
+}
+
 func (fl *FakeLeaseRegistry) SetLease(name, machID string, ver int, ttl time.Duration) *fakeLease {
 	l := &fakeLease{
 		name:   name,
@@ -36,11 +36,17 @@ type Registry interface {
 	SaveUnitState(jobName string, unitState *unit.UnitState, ttl time.Duration)
 	ScheduleUnit(name, machID string) error
 	SetUnitTargetState(name string, state job.JobState) error
+	// ScaleUnit sets the number of instances the engine keeps of a
+	// replicated template unit.
+	ScaleUnit(name string, replicas int) error
 	SetMachineState(ms machine.MachineState, ttl time.Duration) (uint64, error)
 	MachineState(machID string) (machine.MachineState, error)
 	UnscheduleUnit(name, machID string) error
 	SetMachineMetadata(machID string, key string, value string) error
 	DeleteMachineMetadata(machID string, key string) error
+	// SetMachineMaintenance puts a machine in the given maintenance mode,
+	// or takes it out of maintenance if the mode is empty.
+	SetMachineMaintenance(machID string, mode string) error
 
 	IsRegistryReady() bool
 	UseEtcdRegistry() bool
@@ -70,4 +76,29 @@ type ClusterRegistry interface {
 	// indicated by the returned error object. A nil value will be returned
 	// on success.
 	UpdateEngineVersion(from, to int) error
+
+	// EngineScheduler returns the name of the scheduling strategy used by
+	// the engine leader. If no engine has reported its strategy yet, an
+	// empty string will be returned.
+	EngineScheduler() (string, error)
+
+	// SetEngineScheduler records the name of the scheduling strategy used
+	// by the engine leader.
+	SetEngineScheduler(name string) error
+
+	// EngineEvents returns the most recent scheduling decisions of the
+	// engine leaders, oldest first.
+	EngineEvents() ([]EngineEvent, error)
+
+	// SetEngineEvents replaces the recorded scheduling decisions of the
+	// engine leaders.
+	SetEngineEvents(events []EngineEvent) error
+
+	// EngineExclusions returns the machines the engine leader keeps Units
+	// away from after they failed too often on them.
+	EngineExclusions() ([]EngineExclusion, error)
+
+	// SetEngineExclusions replaces the recorded exclusions of the engine
+	// leader.
+	SetEngineExclusions(exclusions []EngineExclusion) error
 }
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"path"
 	"sort"
+	"strconv"
 
 	etcd "github.com/coreos/etcd/client"
 	"golang.org/x/net/context"
@@ -182,6 +183,15 @@ func (r *EtcdRegistry) dirToUnit(dir *etcd.Node, unitHashLookupFunc func(unit.Ha
 		}
 		u.TargetState = ts
 	}
+	if replicas, ok := u.RequiredReplicas(); ok {
+		u.Replicas = replicas
+		if scaled := dirToReplicas(dir); scaled != "" {
+			u.Replicas, err = strconv.Atoi(scaled)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse Unit(%s) replicas: %v", u.Name, err)
+			}
+		}
+	}
 
 	return u, nil
 }
@@ -255,6 +265,10 @@ func dirToTargetState(dir *etcd.Node) (tgtState string) {
 	return getValueInDir(dir, "target-state")
 }
 
+func dirToReplicas(dir *etcd.Node) (replicas string) {
+	return getValueInDir(dir, "replicas")
+}
+
 func dirToHeartbeat(dir *etcd.Node) (heartbeat string) {
 	return getValueInDir(dir, "job-state")
 }
@@ -347,6 +361,14 @@ func (r *EtcdRegistry) SetUnitTargetState(name string, state job.JobState) error
 	return err
 }
 
+// ScaleUnit records the number of instances the engine keeps of a
+// replicated template unit, overriding its Replicas requirement.
+func (r *EtcdRegistry) ScaleUnit(name string, replicas int) error {
+	key := r.prefixed(jobPrefix, name, "replicas")
+	_, err := r.kAPI.Set(context.Background(), key, strconv.Itoa(replicas), nil)
+	return err
+}
+
 func (r *EtcdRegistry) ScheduleUnit(name string, machID string) error {
 	key := r.jobTargetAgentPath(name)
 	opts := &etcd.SetOptions{
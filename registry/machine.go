@@ -135,6 +135,19 @@ func (r *EtcdRegistry) DeleteMachineMetadata(machID string, key string) error {
 	return r.SetMachineMetadata(machID, key, "")
 }
 
+func (r *EtcdRegistry) SetMachineMaintenance(machID string, mode string) error {
+	key := path.Join(r.keyPrefix, machinePrefix, machID, "maintenance")
+	if mode == "" {
+		_, err := r.kAPI.Delete(context.Background(), key, nil)
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return err
+	}
+	_, err := r.kAPI.Set(context.Background(), key, mode, &etcd.SetOptions{})
+	return err
+}
+
 func (r *EtcdRegistry) RemoveMachineState(machID string) error {
 	key := r.prefixed(machinePrefix, machID, "object")
 	_, err := r.kAPI.Delete(context.Background(), key, nil)
@@ -172,6 +185,7 @@ func mergeMetadata(machineMetadata, dynamicMetadata map[string]string) map[strin
 // readMachineState reads machine state from an etcd node
 func readMachineState(node *etcd.Node) (mach machine.MachineState, err error) {
 	var metadata map[string]string
+	var maintenance string
 
 	for _, obj := range node.Nodes {
 		if strings.HasSuffix(obj.Key, "/object") {
@@ -185,9 +199,12 @@ func readMachineState(node *etcd.Node) (mach machine.MachineState, err error) {
 			for _, mdnode := range obj.Nodes {
 				metadata[path.Base(mdnode.Key)] = mdnode.Value
 			}
+		} else if strings.HasSuffix(obj.Key, "/maintenance") {
+			maintenance = obj.Value
 		}
 	}
 
 	mach.Metadata = mergeMetadata(mach.Metadata, metadata)
+	mach.Maintenance = maintenance
 	return
 }
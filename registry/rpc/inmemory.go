@@ -60,29 +60,76 @@ func init() {
 
 var currentReg *inmemoryRegistry
 
+// LoadFrom replaces the Units and the schedule of the registry with the ones
+// of the given UnitRegistry.
 func (r *inmemoryRegistry) LoadFrom(reg registry.UnitRegistry) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
 	units, err := reg.Units()
 	if err != nil {
 		return err
 	}
+	unitsCache := make(map[string]pb.Unit, len(units))
 	for _, u := range units {
-		r.unitsCache[u.Name] = u.ToPB()
+		unitsCache[u.Name] = u.ToPB()
 	}
 
 	schedule, err := reg.Schedule()
 	if err != nil {
 		return err
 	}
-
+	scheduledUnits := make(map[string]pb.ScheduledUnit, len(schedule))
 	for _, scheduledUnit := range schedule {
-		r.scheduledUnits[scheduledUnit.Name] = scheduledUnit.ToPB()
+		scheduledUnits[scheduledUnit.Name] = scheduledUnit.ToPB()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unitsCache = unitsCache
+	r.scheduledUnits = scheduledUnits
+
+	return nil
+}
+
+// UpdateFrom replaces the Unit of the given name and its schedule with the
+// ones of the given UnitRegistry, or removes them if they do not exist
+// there.
+func (r *inmemoryRegistry) UpdateFrom(reg registry.UnitRegistry, name string) error {
+	u, err := reg.Unit(name)
+	if err != nil {
+		return err
+	}
+	su, err := reg.ScheduledUnit(name)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u != nil {
+		r.unitsCache[name] = u.ToPB()
+	} else {
+		delete(r.unitsCache, name)
+	}
+	if su != nil {
+		r.scheduledUnits[name] = su.ToPB()
+	} else {
+		delete(r.scheduledUnits, name)
 	}
 
 	return nil
 }
 
+// ResetUnitStates forgets the states and heartbeats reported by the agents,
+// which are not replicated in etcd.
+func (r *inmemoryRegistry) ResetUnitStates() {
+	r.unitStatesMu.Lock()
+	defer r.unitStatesMu.Unlock()
+	r.heartbeatsMu.Lock()
+	defer r.heartbeatsMu.Unlock()
+
+	r.unitStates = map[string]map[string]*unitStateHeartbeat{}
+	r.unitHeartbeats = map[string]map[string]time.Time{}
+}
+
 func (r *inmemoryRegistry) Schedule() (units []pb.ScheduledUnit, err error) {
 	if DebugInmemoryRegistry {
 		defer debug.Exit_(debug.Enter_())
@@ -313,6 +360,23 @@ func (r *inmemoryRegistry) SetUnitTargetState(unitName string, targetState pb.Ta
 	return false
 }
 
+// ScaleUnit sets the number of instances of a replicated template unit,
+// reporting whether the unit exists.
+func (r *inmemoryRegistry) ScaleUnit(unitName string, replicas int32) bool {
+	if DebugInmemoryRegistry {
+		defer debug.Exit_(debug.Enter_(unitName, replicas))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if u, exists := r.unitsCache[unitName]; exists {
+		u.Replicas = replicas
+		r.unitsCache[unitName] = u
+		return true
+	}
+	return false
+}
+
 func (r *inmemoryRegistry) CreateUnit(u *pb.Unit) {
 	if DebugInmemoryRegistry {
 		defer debug.Exit_(debug.Enter_(u))
@@ -73,6 +73,28 @@ Description = Foo
 	}
 }
 
+func TestInMemoryScaleUnit(t *testing.T) {
+	inmemoryRegistry := newInmemoryRegistry()
+
+	if inmemoryRegistry.ScaleUnit("web@.service", 2) {
+		t.Fatalf("unexpected scaling of a unit missing from the in-memory registry")
+	}
+
+	inmemoryRegistry.CreateUnit(&pb.Unit{
+		Name:         "web@.service",
+		DesiredState: pb.TargetState_LAUNCHED,
+		Replicas:     3,
+	})
+	if !inmemoryRegistry.ScaleUnit("web@.service", 0) {
+		t.Fatalf("unexpected error scaling unit web@.service")
+	}
+
+	u, ok := inmemoryRegistry.Unit("web@.service")
+	if !ok || u.Replicas != 0 {
+		t.Fatalf("expected web@.service to be scaled to 0 replicas, got %v", u)
+	}
+}
+
 func TestInMemoryUnitStates(t *testing.T) {
 	inmemoryRegistry := newInmemoryRegistry()
 
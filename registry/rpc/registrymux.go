@@ -41,6 +41,10 @@ type RegistryMux struct {
 	currentEngine   machine.MachineState
 	leaseManager    lease.Manager
 
+	// replica, when set, keeps a copy of the registry of the engine
+	// leader up to date for the local engine to serve once leader
+	replica *registryReplica
+
 	handlingEngineChange *sync.RWMutex
 }
 
@@ -59,6 +63,23 @@ func NewRegistryMux(etcdRegistry *registry.EtcdRegistry, localMachine machine.Ma
 	}
 }
 
+// SetWarmStandby has the local engine keep a copy of the in-memory registry
+// of the engine leader, replicated from etcd from the given changes and
+// reloaded every resyncInterval, so that it serves it at once when it
+// becomes leader. Replication runs in Replicate.
+func (r *RegistryMux) SetWarmStandby(changes registry.UnitChangeStream, resyncInterval time.Duration) {
+	r.replica = newRegistryReplica(r.etcdRegistry, changes, resyncInterval)
+}
+
+// Replicate keeps the copy of the in-memory registry of the engine leader up
+// to date until stop is closed, if warm standby is set.
+func (r *RegistryMux) Replicate(stop <-chan struct{}) {
+	if r.replica == nil {
+		return
+	}
+	r.replica.run(stop)
+}
+
 // ConnectToRegistry allows to disable_engine fleet agents to adapt its Registry
 // to fleet leader changes regardless of whether is etcd or gRPC based.
 func (r *RegistryMux) ConnectToRegistry(e *engine.Engine) {
@@ -190,8 +211,14 @@ func (r *RegistryMux) EngineChanged(newEngine machine.MachineState) {
 			if r.rpcserver == nil {
 				// start rpc server
 				log.Infof("Starting rpc server...\n")
+				var local *inmemoryRegistry
+				if r.replica != nil {
+					if local = r.replica.registry(); local == nil {
+						log.Infof("Replicated registry not up to date, loading it from etcd")
+					}
+				}
 				var err error
-				r.rpcserver, err = NewRPCServer(r.etcdRegistry, newEngine.PublicIP)
+				r.rpcserver, err = NewRPCServer(r.etcdRegistry, local, newEngine.PublicIP)
 				if err != nil {
 					log.Fatalf("Unable to create rpc server %+v", err)
 				}
@@ -292,6 +319,10 @@ func (r *RegistryMux) SetUnitTargetState(name string, state job.JobState) error
 	return r.getRegistry().SetUnitTargetState(name, state)
 }
 
+func (r *RegistryMux) ScaleUnit(name string, replicas int) error {
+	return r.getRegistry().ScaleUnit(name, replicas)
+}
+
 func (r *RegistryMux) MachineState(machID string) (machine.MachineState, error) {
 	return r.etcdRegistry.MachineState(machID)
 }
@@ -340,6 +371,22 @@ func (r *RegistryMux) UpdateEngineVersion(from int, to int) error {
 	return r.etcdRegistry.UpdateEngineVersion(from, to)
 }
 
+func (r *RegistryMux) EngineScheduler() (string, error) {
+	return r.etcdRegistry.EngineScheduler()
+}
+
+func (r *RegistryMux) SetEngineScheduler(name string) error {
+	return r.etcdRegistry.SetEngineScheduler(name)
+}
+
+func (r *RegistryMux) EngineEvents() ([]registry.EngineEvent, error) {
+	return r.etcdRegistry.EngineEvents()
+}
+
+func (r *RegistryMux) SetEngineEvents(events []registry.EngineEvent) error {
+	return r.etcdRegistry.SetEngineEvents(events)
+}
+
 func (r *RegistryMux) SetMachineMetadata(machID string, key string, value string) error {
 	return r.etcdRegistry.SetMachineMetadata(machID, key, value)
 }
@@ -347,3 +394,7 @@ func (r *RegistryMux) SetMachineMetadata(machID string, key string, value string
 func (r *RegistryMux) DeleteMachineMetadata(machID string, key string) error {
 	return r.etcdRegistry.DeleteMachineMetadata(machID, key)
 }
+
+func (r *RegistryMux) SetMachineMaintenance(machID string, mode string) error {
+	return r.etcdRegistry.SetMachineMaintenance(machID, mode)
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/cea-hpc/fleet/log"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+// registryReplica keeps an inmemoryRegistry up to date with the Units and
+// the schedule stored in etcd, so that an engine becoming leader in gRPC
+// mode serves it at once instead of loading it from etcd first. As the
+// engine leader writes every change to etcd before applying it to its own
+// inmemoryRegistry, the replica only reads back the Units reported as
+// changed, and reloads everything every resyncInterval.
+type registryReplica struct {
+	etcdRegistry   registry.UnitRegistry
+	changes        registry.UnitChangeStream
+	resyncInterval time.Duration
+	clock          clockwork.Clock
+
+	reg *inmemoryRegistry
+
+	// mu guards loaded, which is set once the registry was loaded in
+	// full from etcd
+	mu     sync.Mutex
+	loaded bool
+}
+
+func newRegistryReplica(etcdRegistry registry.UnitRegistry, changes registry.UnitChangeStream, resyncInterval time.Duration) *registryReplica {
+	return &registryReplica{
+		etcdRegistry:   etcdRegistry,
+		changes:        changes,
+		resyncInterval: resyncInterval,
+		clock:          clockwork.NewRealClock(),
+		reg:            newInmemoryRegistry(),
+	}
+}
+
+// run keeps the replicated registry up to date until stop is closed.
+func (rr *registryReplica) run(stop <-chan struct{}) {
+	changes := rr.changes.Changes(stop)
+	rr.resync()
+
+	resync := rr.clock.After(rr.resyncInterval)
+	for {
+		select {
+		case <-stop:
+			return
+		case name, ok := <-changes:
+			if !ok {
+				return
+			}
+			// an empty name means that any Unit may have changed
+			if name == "" {
+				rr.resync()
+				continue
+			}
+			if err := rr.reg.UpdateFrom(rr.etcdRegistry, name); err != nil {
+				log.Errorf("Failed replicating Unit(%s) from etcd: %v", name, err)
+				rr.setLoaded(false)
+			}
+		case <-resync:
+			rr.resync()
+			resync = rr.clock.After(rr.resyncInterval)
+		}
+	}
+}
+
+// resync reloads the whole replicated registry from etcd.
+func (rr *registryReplica) resync() {
+	err := rr.reg.LoadFrom(rr.etcdRegistry)
+	if err != nil {
+		log.Errorf("Failed replicating registry from etcd: %v", err)
+	} else {
+		log.Debugf("Replicated registry from etcd")
+	}
+	rr.setLoaded(err == nil)
+}
+
+func (rr *registryReplica) setLoaded(loaded bool) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.loaded = loaded
+}
+
+// registry returns the replicated registry, without the unit states
+// reported to a previous leadership of the local engine, or nil if it is
+// not up to date.
+func (rr *registryReplica) registry() *inmemoryRegistry {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if !rr.loaded {
+		return nil
+	}
+
+	rr.reg.ResetUnitStates()
+	return rr.reg
+}
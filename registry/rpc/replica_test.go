@@ -0,0 +1,108 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/cea-hpc/fleet/job"
+	pb "github.com/cea-hpc/fleet/protobuf"
+	"github.com/cea-hpc/fleet/registry"
+)
+
+// chanChangeStream emits the names sent on its channel.
+type chanChangeStream chan string
+
+func (cs chanChangeStream) Changes(stop <-chan struct{}) <-chan string {
+	return cs
+}
+
+func TestRegistryReplica(t *testing.T) {
+	// the etcd registry always reports the state of scheduled units
+	jsInactive := job.JobStateInactive
+	reg := registry.NewFakeRegistry()
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", TargetState: job.JobStateLaunched, TargetMachineID: "XXX", State: &jsInactive},
+		{Name: "bar.service", TargetState: job.JobStateLaunched, State: &jsInactive},
+	})
+
+	changes := make(chanChangeStream)
+	rr := newRegistryReplica(reg, changes, time.Hour)
+	clock := clockwork.NewFakeClock()
+	rr.clock = clock
+	if rr.registry() != nil {
+		t.Fatalf("Expected no replicated registry before it is loaded")
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go rr.run(stop)
+
+	// changes are handled one at a time, so a change is applied once the
+	// next one is received
+	notify := func(names ...string) {
+		for _, name := range append(names, "sync.service") {
+			changes <- name
+		}
+	}
+
+	notify()
+	local := rr.registry()
+	if local == nil {
+		t.Fatalf("Expected the replicated registry to be loaded")
+	}
+	if n := len(local.Units()); n != 2 {
+		t.Fatalf("Expected 2 replicated units, got %d", n)
+	}
+	if su := local.ScheduledUnit("foo.service"); su.MachineID != "XXX" {
+		t.Fatalf("Expected foo.service to be replicated as scheduled to XXX, got %v", su)
+	}
+
+	// changed units are read back, and removed once destroyed
+	reg.ScheduleUnit("bar.service", "YYY")
+	reg.DestroyUnit("foo.service")
+	notify("bar.service", "foo.service")
+	if _, ok := local.Unit("foo.service"); ok {
+		t.Errorf("Expected foo.service to be removed from the replicated registry")
+	}
+	if su := local.ScheduledUnit("bar.service"); su.MachineID != "YYY" {
+		t.Errorf("Expected bar.service to be replicated as scheduled to YYY, got %v", su)
+	}
+
+	// states reported to a previous leadership are not served again
+	local.SaveUnitState("bar.service", &pb.UnitState{Name: "bar.service", MachineID: "YYY"}, time.Hour)
+	if states := rr.registry().UnitStates(); len(states) != 0 {
+		t.Errorf("Expected unit states to be reset when the replicated registry is served")
+	}
+
+	// changes missed by the watch are caught up by the periodic resync
+	reg.SetJobs([]job.Job{
+		{Name: "baz.service", TargetState: job.JobStateLaunched, State: &jsInactive},
+	})
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	var units []pb.Unit
+	for i := 0; i < 100; i++ {
+		// the resync competes with the changes received meanwhile
+		notify()
+		if units = local.Units(); len(units) == 1 && units[0].Name == "baz.service" {
+			return
+		}
+	}
+	t.Errorf("Expected only baz.service to be replicated after the resync, got %v", units)
+}
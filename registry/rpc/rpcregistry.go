@@ -29,6 +29,7 @@ import (
 	"github.com/cea-hpc/fleet/log"
 	"github.com/cea-hpc/fleet/machine"
 	pb "github.com/cea-hpc/fleet/protobuf"
+	"github.com/cea-hpc/fleet/registry"
 	"github.com/cea-hpc/fleet/unit"
 )
 
@@ -204,6 +205,18 @@ func (r *RPCRegistry) SetUnitTargetState(unitName string, state job.JobState) er
 	return err
 }
 
+func (r *RPCRegistry) ScaleUnit(unitName string, replicas int) error {
+	if DebugRPCRegistry {
+		defer debug.Exit_(debug.Enter_(unitName, replicas))
+	}
+
+	_, err := r.getClient().ScaleUnit(r.ctx(), &pb.ScaleUnitRequest{
+		Name:     unitName,
+		Replicas: int32(replicas),
+	})
+	return err
+}
+
 func (r *RPCRegistry) UnscheduleUnit(unitName, machID string) error {
 	if DebugRPCRegistry {
 		defer debug.Exit_(debug.Enter_(unitName, machID))
@@ -224,6 +237,10 @@ func (r *RPCRegistry) DeleteMachineMetadata(machID string, key string) error {
 	panic("Delete machine metadata function not implemented")
 }
 
+func (r *RPCRegistry) SetMachineMaintenance(machID string, mode string) error {
+	panic("Set machine maintenance function not implemented")
+}
+
 func (r *RPCRegistry) Machines() ([]machine.MachineState, error) {
 	panic("Machines function not implemented")
 }
@@ -333,12 +350,21 @@ func (r *RPCRegistry) UnitState(unitName string) (*unit.UnitState, error) {
 	}
 
 	return &unit.UnitState{
-		UnitName:    state.Name,
-		MachineID:   state.MachineID,
-		UnitHash:    state.Hash,
-		LoadState:   state.LoadState,
-		ActiveState: state.ActiveState,
-		SubState:    state.SubState,
+		UnitName:               state.Name,
+		MachineID:              state.MachineID,
+		UnitHash:               state.Hash,
+		LoadState:              state.LoadState,
+		ActiveState:            state.ActiveState,
+		SubState:               state.SubState,
+		Healthy:                healthFromPB(state),
+		Failures:               int(state.Failures),
+		ExecMainStatus:         int(state.ExecMainStatus),
+		NRestarts:              int(state.NRestarts),
+		ActiveEnterTimestamp:   state.ActiveEnterTimestamp,
+		InactiveEnterTimestamp: state.InactiveEnterTimestamp,
+		CPUUsageNSec:           state.CPUUsageNSec,
+		MemoryCurrent:          state.MemoryCurrent,
+		TasksCurrent:           state.TasksCurrent,
 	}, nil
 }
 
@@ -356,12 +382,21 @@ func (r *RPCRegistry) UnitStates() ([]*unit.UnitState, error) {
 
 	for i, state := range unitStates.UnitStates {
 		nUnitStates[i] = &unit.UnitState{
-			UnitName:    state.Name,
-			MachineID:   state.MachineID,
-			UnitHash:    state.Hash,
-			LoadState:   state.LoadState,
-			ActiveState: state.ActiveState,
-			SubState:    state.SubState,
+			UnitName:               state.Name,
+			MachineID:              state.MachineID,
+			UnitHash:               state.Hash,
+			LoadState:              state.LoadState,
+			ActiveState:            state.ActiveState,
+			SubState:               state.SubState,
+			Healthy:                healthFromPB(state),
+			Failures:               int(state.Failures),
+			ExecMainStatus:         int(state.ExecMainStatus),
+			NRestarts:              int(state.NRestarts),
+			ActiveEnterTimestamp:   state.ActiveEnterTimestamp,
+			InactiveEnterTimestamp: state.InactiveEnterTimestamp,
+			CPUUsageNSec:           state.CPUUsageNSec,
+			MemoryCurrent:          state.MemoryCurrent,
+			TasksCurrent:           state.TasksCurrent,
 		}
 	}
 	return nUnitStates, nil
@@ -375,6 +410,40 @@ func (r *RPCRegistry) UpdateEngineVersion(from, to int) error {
 	return errors.New("Update engine version function not implemented")
 }
 
+func (r *RPCRegistry) EngineScheduler() (string, error) {
+	return "", errors.New("Engine scheduler function not implemented")
+}
+
+func (r *RPCRegistry) SetEngineScheduler(name string) error {
+	return errors.New("Set engine scheduler function not implemented")
+}
+
+func (r *RPCRegistry) EngineEvents() ([]registry.EngineEvent, error) {
+	return nil, errors.New("Engine events function not implemented")
+}
+
+func (r *RPCRegistry) SetEngineEvents(events []registry.EngineEvent) error {
+	return errors.New("Set engine events function not implemented")
+}
+
+func (r *RPCRegistry) EngineExclusions() ([]registry.EngineExclusion, error) {
+	return nil, errors.New("Engine exclusions function not implemented")
+}
+
+func (r *RPCRegistry) SetEngineExclusions(exclusions []registry.EngineExclusion) error {
+	return errors.New("Set engine exclusions function not implemented")
+}
+
 func (r *RPCRegistry) LatestDaemonVersion() (*semver.Version, error) {
 	return nil, errors.New("Latest daemon version function not implemented")
 }
+
+// healthFromPB returns the health carried by the given UnitState, nil if it
+// is not known.
+func healthFromPB(state *pb.UnitState) *bool {
+	if !state.HealthChecked {
+		return nil
+	}
+	healthy := state.Healthy
+	return &healthy
+}
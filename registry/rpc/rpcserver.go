@@ -57,11 +57,13 @@ type rpcserver struct {
 	hasNonGRPCAgents bool
 }
 
-func NewRPCServer(reg registry.Registry, addr string) (*rpcserver, error) {
+// NewRPCServer returns an rpcserver serving the given inmemoryRegistry,
+// already up to date with etcd, or a new one loaded from etcd if it is nil.
+func NewRPCServer(reg registry.Registry, local *inmemoryRegistry, addr string) (*rpcserver, error) {
 	s := &rpcserver{
 		etcdRegistry:  reg,
 		mu:            new(sync.Mutex),
-		localRegistry: newInmemoryRegistry(),
+		localRegistry: local,
 		stop:          make(chan struct{}),
 	}
 	var err error
@@ -82,7 +84,10 @@ func NewRPCServer(reg registry.Registry, addr string) (*rpcserver, error) {
 	}
 
 	s.grpcserver = grpc.NewServer()
-	s.localRegistry.LoadFrom(s.etcdRegistry)
+	if s.localRegistry == nil {
+		s.localRegistry = newInmemoryRegistry()
+		s.localRegistry.LoadFrom(s.etcdRegistry)
+	}
 	pb.RegisterRegistryServer(s.grpcserver, s)
 
 	s.SetServingStatus(pb.HealthCheckResponse_NOT_SERVING)
@@ -248,8 +253,13 @@ func (s *rpcserver) CreateUnit(ctx context.Context, u *pb.Unit) (*pb.GenericRepl
 		defer debug.Exit_(debug.Enter_(u.Name))
 	}
 
-	err := s.etcdRegistry.CreateUnit(rpcUnitToJobUnit(u))
+	ju := rpcUnitToJobUnit(u)
+	err := s.etcdRegistry.CreateUnit(ju)
 	if err == nil {
+		// a new replicated template starts from its Replicas requirement
+		if replicas, ok := ju.RequiredReplicas(); ok {
+			u.Replicas = int32(replicas)
+		}
 		s.localRegistry.CreateUnit(u)
 	}
 	return &pb.GenericReply{}, err
@@ -330,6 +340,18 @@ func (s *rpcserver) SetUnitTargetState(ctx context.Context, unit *pb.ScheduledUn
 	return &pb.GenericReply{}, err
 }
 
+func (s *rpcserver) ScaleUnit(ctx context.Context, req *pb.ScaleUnitRequest) (*pb.GenericReply, error) {
+	if debugRPCServer {
+		defer debug.Exit_(debug.Enter_(req.Name, req.Replicas))
+	}
+
+	err := s.etcdRegistry.ScaleUnit(req.Name, int(req.Replicas))
+	if err == nil {
+		s.localRegistry.ScaleUnit(req.Name, req.Replicas)
+	}
+	return &pb.GenericReply{}, err
+}
+
 func (s *rpcserver) UnscheduleUnit(ctx context.Context, unit *pb.UnscheduleUnitRequest) (*pb.GenericReply, error) {
 	if debugRPCServer {
 		defer debug.Exit_(debug.Enter_(unit.Name, unit.MachineID))
@@ -60,5 +60,6 @@ func rpcUnitToJobUnit(u *pb.Unit) *job.Unit {
 		Name:        u.Name,
 		Unit:        *unit.NewUnitFromOptions(unitOptions),
 		TargetState: rpcUnitStateToJobState(u.DesiredState),
+		Replicas:    int(u.Replicas),
 	}
 }
@@ -92,11 +92,23 @@ Description = Foo
 		Name:         "foo",
 		Unit:         unitFile.ToPB(),
 		DesiredState: pb.TargetState_LOADED,
+		Replicas:     3,
 	}
 	expect := &job.Unit{
 		Name:        "foo",
 		Unit:        *unitFile,
 		TargetState: job.JobStateLoaded,
+		Replicas:    3,
+	}
+
+	// the replica count goes over the wire
+	data, err := want.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error marshalling unit: %v", err)
+	}
+	want = &pb.Unit{}
+	if err := want.Unmarshal(data); err != nil {
+		t.Fatalf("unexpected error unmarshalling unit: %v", err)
 	}
 
 	got := rpcUnitToJobUnit(want)
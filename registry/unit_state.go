@@ -239,11 +239,20 @@ func (r *EtcdRegistry) RemoveUnitState(jobName string) error {
 }
 
 type unitStateModel struct {
-	LoadState    string                `json:"loadState"`
-	ActiveState  string                `json:"activeState"`
-	SubState     string                `json:"subState"`
-	MachineState *machine.MachineState `json:"machineState"`
-	UnitHash     string                `json:"unitHash"`
+	LoadState              string                `json:"loadState"`
+	ActiveState            string                `json:"activeState"`
+	SubState               string                `json:"subState"`
+	MachineState           *machine.MachineState `json:"machineState"`
+	UnitHash               string                `json:"unitHash"`
+	Healthy                *bool                 `json:"healthy,omitempty"`
+	Failures               int                   `json:"failures,omitempty"`
+	ExecMainStatus         int                   `json:"execMainStatus,omitempty"`
+	NRestarts              int                   `json:"nRestarts,omitempty"`
+	ActiveEnterTimestamp   uint64                `json:"activeEnterTimestamp,omitempty"`
+	InactiveEnterTimestamp uint64                `json:"inactiveEnterTimestamp,omitempty"`
+	CPUUsageNSec           uint64                `json:"cpuUsageNSec,omitempty"`
+	MemoryCurrent          uint64                `json:"memoryCurrent,omitempty"`
+	TasksCurrent           uint64                `json:"tasksCurrent,omitempty"`
 }
 
 func modelToUnitState(usm *unitStateModel, name string) *unit.UnitState {
@@ -252,11 +261,20 @@ func modelToUnitState(usm *unitStateModel, name string) *unit.UnitState {
 	}
 
 	us := unit.UnitState{
-		LoadState:   usm.LoadState,
-		ActiveState: usm.ActiveState,
-		SubState:    usm.SubState,
-		UnitHash:    usm.UnitHash,
-		UnitName:    name,
+		LoadState:              usm.LoadState,
+		ActiveState:            usm.ActiveState,
+		SubState:               usm.SubState,
+		UnitHash:               usm.UnitHash,
+		UnitName:               name,
+		Healthy:                usm.Healthy,
+		Failures:               usm.Failures,
+		ExecMainStatus:         usm.ExecMainStatus,
+		NRestarts:              usm.NRestarts,
+		ActiveEnterTimestamp:   usm.ActiveEnterTimestamp,
+		InactiveEnterTimestamp: usm.InactiveEnterTimestamp,
+		CPUUsageNSec:           usm.CPUUsageNSec,
+		MemoryCurrent:          usm.MemoryCurrent,
+		TasksCurrent:           usm.TasksCurrent,
 	}
 
 	if usm.MachineState != nil {
@@ -277,10 +295,19 @@ func unitStateToModel(us *unit.UnitState) *unitStateModel {
 	}
 
 	usm := unitStateModel{
-		LoadState:   us.LoadState,
-		ActiveState: us.ActiveState,
-		SubState:    us.SubState,
-		UnitHash:    us.UnitHash,
+		LoadState:              us.LoadState,
+		ActiveState:            us.ActiveState,
+		SubState:               us.SubState,
+		UnitHash:               us.UnitHash,
+		Healthy:                us.Healthy,
+		Failures:               us.Failures,
+		ExecMainStatus:         us.ExecMainStatus,
+		NRestarts:              us.NRestarts,
+		ActiveEnterTimestamp:   us.ActiveEnterTimestamp,
+		InactiveEnterTimestamp: us.InactiveEnterTimestamp,
+		CPUUsageNSec:           us.CPUUsageNSec,
+		MemoryCurrent:          us.MemoryCurrent,
+		TasksCurrent:           us.TasksCurrent,
 	}
 
 	if us.MachineID != "" {
@@ -122,7 +122,7 @@ func TestSaveUnitState(t *testing.T) {
 	us.UnitHash = "quickbrownfox"
 	r.SaveUnitState(j, us, time.Second)
 
-	json := `{"loadState":"abc","activeState":"def","subState":"ghi","machineState":{"ID":"mymachine","PublicIP":"","Metadata":null,"Capabilities":null,"Version":""},"unitHash":"quickbrownfox"}`
+	json := `{"loadState":"abc","activeState":"def","subState":"ghi","machineState":{"ID":"mymachine","PublicIP":"","Metadata":null,"Capabilities":null,"Version":"","TotalResources":{"Cores":0,"Memory":0,"Disk":0},"FreeResources":{"Cores":0,"Memory":0,"Disk":0},"Maintenance":"","MaxUnits":0,"MaxWeight":0,"UsedUnits":0,"UsedWeight":0},"unitHash":"quickbrownfox"}`
 	p1 := "/fleet/state/foo.service"
 	p2 := "/fleet/states/foo.service/mymachine"
 	want := []action{
@@ -185,6 +185,7 @@ func TestRemoveUnitState(t *testing.T) {
 }
 
 func TestUnitStateToModel(t *testing.T) {
+	unhealthy := false
 	for i, tt := range []struct {
 		in   *unit.UnitState
 		want *unitStateModel
@@ -240,6 +241,41 @@ func TestUnitStateToModel(t *testing.T) {
 				UnitHash:     "miaow",
 			},
 		},
+		{
+			in: &unit.UnitState{
+				LoadState:              "foo",
+				ActiveState:            "bar",
+				SubState:               "baz",
+				MachineID:              "woof",
+				UnitHash:               "miaow",
+				UnitName:               "name",
+				Healthy:                &unhealthy,
+				Failures:               2,
+				ExecMainStatus:         1,
+				NRestarts:              3,
+				ActiveEnterTimestamp:   1500000000000000,
+				InactiveEnterTimestamp: 1500000060000000,
+				CPUUsageNSec:           1500000000,
+				MemoryCurrent:          4096,
+				TasksCurrent:           2,
+			},
+			want: &unitStateModel{
+				LoadState:              "foo",
+				ActiveState:            "bar",
+				SubState:               "baz",
+				MachineState:           &machine.MachineState{ID: "woof"},
+				UnitHash:               "miaow",
+				Healthy:                &unhealthy,
+				Failures:               2,
+				ExecMainStatus:         1,
+				NRestarts:              3,
+				ActiveEnterTimestamp:   1500000000000000,
+				InactiveEnterTimestamp: 1500000060000000,
+				CPUUsageNSec:           1500000000,
+				MemoryCurrent:          4096,
+				TasksCurrent:           2,
+			},
+		},
 	} {
 		got := unitStateToModel(tt.in)
 		if !reflect.DeepEqual(got, tt.want) {
@@ -249,6 +285,7 @@ func TestUnitStateToModel(t *testing.T) {
 }
 
 func TestModelToUnitState(t *testing.T) {
+	healthy := true
 	for i, tt := range []struct {
 		in   *unitStateModel
 		want *unit.UnitState
@@ -258,7 +295,7 @@ func TestModelToUnitState(t *testing.T) {
 			want: nil,
 		},
 		{
-			in: &unitStateModel{"foo", "bar", "baz", nil, ""},
+			in: &unitStateModel{"foo", "bar", "baz", nil, "", nil, 0, 0, 0, 0, 0, 0, 0, 0},
 			want: &unit.UnitState{
 				LoadState:   "foo",
 				ActiveState: "bar",
@@ -269,7 +306,7 @@ func TestModelToUnitState(t *testing.T) {
 			},
 		},
 		{
-			in: &unitStateModel{"z", "x", "y", &machine.MachineState{ID: "abcd"}, ""},
+			in: &unitStateModel{"z", "x", "y", &machine.MachineState{ID: "abcd"}, "", nil, 0, 0, 0, 0, 0, 0, 0, 0},
 			want: &unit.UnitState{
 				LoadState:   "z",
 				ActiveState: "x",
@@ -279,6 +316,33 @@ func TestModelToUnitState(t *testing.T) {
 				UnitName:    "name",
 			},
 		},
+		{
+			in: &unitStateModel{"z", "x", "y", nil, "", &healthy, 0, 0, 0, 0, 0, 0, 0, 0},
+			want: &unit.UnitState{
+				LoadState:   "z",
+				ActiveState: "x",
+				SubState:    "y",
+				UnitName:    "name",
+				Healthy:     &healthy,
+			},
+		},
+		{
+			in: &unitStateModel{"z", "failed", "y", nil, "", nil, 3, 203, 4, 1500000000000000, 1500000060000000, 1500000000, 4096, 2},
+			want: &unit.UnitState{
+				LoadState:              "z",
+				ActiveState:            "failed",
+				SubState:               "y",
+				UnitName:               "name",
+				Failures:               3,
+				ExecMainStatus:         203,
+				NRestarts:              4,
+				ActiveEnterTimestamp:   1500000000000000,
+				InactiveEnterTimestamp: 1500000060000000,
+				CPUUsageNSec:           1500000000,
+				MemoryCurrent:          4096,
+				TasksCurrent:           2,
+			},
+		},
 	} {
 		got := modelToUnitState(tt.in, "name")
 		if !reflect.DeepEqual(got, tt.want) {
@@ -304,7 +368,7 @@ func TestGetUnitState(t *testing.T) {
 	}{
 		{
 			// Unit state with no UnitHash should be OK
-			res: makeResponse(`{"loadState":"abc","activeState":"def","subState":"ghi","machineState":{"ID":"mymachine","PublicIP":"","Metadata":null,"Capabilities":null,"Version":"","TotalResources":{"Cores":0,"Memory":0,"Disk":0},"FreeResources":{"Cores":0,"Memory":0,"Disk":0}}}`),
+			res: makeResponse(`{"loadState":"abc","activeState":"def","subState":"ghi","machineState":{"ID":"mymachine","PublicIP":"","Metadata":null,"Capabilities":null,"Version":"","TotalResources":{"Cores":0,"Memory":0,"Disk":0},"FreeResources":{"Cores":0,"Memory":0,"Disk":0},"Maintenance":""}}`),
 			err: nil,
 			wantUS: &unit.UnitState{
 				LoadState:   "abc",
@@ -317,7 +381,7 @@ func TestGetUnitState(t *testing.T) {
 		},
 		{
 			// Unit state with UnitHash should be OK
-			res: makeResponse(`{"loadState":"abc","activeState":"def","subState":"ghi","machineState":{"ID":"mymachine","PublicIP":"","Metadata":null,"Capabilities":null,"Version":"","TotalResources":{"Cores":0,"Memory":0,"Disk":0},"FreeResources":{"Cores":0,"Memory":0,"Disk":0}},"unitHash":"quickbrownfox"}`),
+			res: makeResponse(`{"loadState":"abc","activeState":"def","subState":"ghi","machineState":{"ID":"mymachine","PublicIP":"","Metadata":null,"Capabilities":null,"Version":"","TotalResources":{"Cores":0,"Memory":0,"Disk":0},"FreeResources":{"Cores":0,"Memory":0,"Disk":0},"Maintenance":""},"unitHash":"quickbrownfox"}`),
 			err: nil,
 			wantUS: &unit.UnitState{
 				LoadState:   "abc",
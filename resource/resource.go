@@ -14,6 +14,11 @@
 
 package resource
 
+import (
+	"fmt"
+	"strconv"
+)
+
 // ResourceTuple groups together CPU, memory and disk space. This could be
 // total, available or consumed. It could also be used by job resource requirements.
 type ResourceTuple struct {
@@ -30,6 +35,13 @@ func (rt ResourceTuple) Empty() bool {
 	return rt.Cores == 0 && rt.Memory == 0 && rt.Disk == 0
 }
 
+// String returns a human-readable form of the ResourceTuple, e.g.
+// "cores=1.5 memory=512M disk=0M".
+func (rt ResourceTuple) String() string {
+	cores := strconv.FormatFloat(float64(rt.Cores)/100, 'f', -1, 64)
+	return fmt.Sprintf("cores=%s memory=%dM disk=%dM", cores, rt.Memory, rt.Disk)
+}
+
 const (
 	// TODO(jonboulle): make these configurable
 	HostCores  = 100
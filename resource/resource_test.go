@@ -70,3 +70,27 @@ func TestSub(t *testing.T) {
 		}
 	}
 }
+
+func TestString(t *testing.T) {
+	for i, tt := range []struct {
+		in   ResourceTuple
+		want string
+	}{
+		{
+			ResourceTuple{150, 512, 0},
+			"cores=1.5 memory=512M disk=0M",
+		},
+		{
+			ResourceTuple{200, 1024, 4096},
+			"cores=2 memory=1024M disk=4096M",
+		},
+		{
+			ResourceTuple{-100, -256, 0},
+			"cores=-1 memory=-256M disk=0M",
+		},
+	} {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("case %d: got %q, want %q", i, got, tt.want)
+		}
+	}
+}
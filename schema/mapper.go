@@ -15,13 +15,21 @@
 package schema
 
 import (
+	"time"
+
 	gsunit "github.com/coreos/go-systemd/unit"
 
 	"github.com/cea-hpc/fleet/job"
 	"github.com/cea-hpc/fleet/machine"
+	"github.com/cea-hpc/fleet/resource"
 	"github.com/cea-hpc/fleet/unit"
 )
 
+// MachineOperationList is the JSONPatch document sent to modify Machines.
+// The generator does not emit types for array schemas, so it is declared
+// here.
+type MachineOperationList []*MachineOperation
+
 func MapUnitFileToSchemaUnitOptions(uf *unit.UnitFile) []*UnitOption {
 	sopts := make([]*UnitOption, len(uf.Options))
 	for i, opt := range uf.Options {
@@ -63,6 +71,12 @@ func MapUnitToSchemaUnit(u *job.Unit, su *job.ScheduledUnit) *Unit {
 		DesiredState: string(u.TargetState),
 	}
 
+	if _, ok := u.RequiredReplicas(); ok {
+		s.Replicas = int64(u.Replicas)
+		// report templates scaled down to zero instances too
+		s.ForceSendFields = []string{"Replicas"}
+	}
+
 	if su != nil {
 		s.MachineID = su.TargetMachineID
 		if su.State != nil {
@@ -85,8 +99,9 @@ func MapSchemaUnitsToUnits(entities []*Unit) []job.Unit {
 
 func MapMachineStateToSchema(ms *machine.MachineState) *Machine {
 	sm := Machine{
-		Id:        ms.ID,
-		PrimaryIP: ms.PublicIP,
+		Id:          ms.ID,
+		PrimaryIP:   ms.PublicIP,
+		Maintenance: ms.Maintenance,
 	}
 
 	sm.Metadata = make(map[string]string, len(ms.Metadata))
@@ -94,6 +109,22 @@ func MapMachineStateToSchema(ms *machine.MachineState) *Machine {
 		sm.Metadata[k] = v
 	}
 
+	if !ms.TotalResources.Empty() {
+		sm.TotalResources = MapResourceTupleToSchema(ms.TotalResources)
+		sm.FreeResources = MapResourceTupleToSchema(ms.FreeResources)
+	}
+
+	if ms.Limited() {
+		sm.MaxCapacity = &Capacity{
+			Units:  int64(ms.MaxUnits),
+			Weight: int64(ms.MaxWeight),
+		}
+		sm.UsedCapacity = &Capacity{
+			Units:  int64(ms.UsedUnits),
+			Weight: int64(ms.UsedWeight),
+		}
+	}
+
 	return &sm
 }
 
@@ -103,8 +134,9 @@ func MapSchemaToMachineStates(entities []*Machine) []machine.MachineState {
 		me := entities[i]
 
 		ms := machine.MachineState{
-			ID:       me.Id,
-			PublicIP: me.PrimaryIP,
+			ID:          me.Id,
+			PublicIP:    me.PrimaryIP,
+			Maintenance: me.Maintenance,
 		}
 
 		ms.Metadata = make(map[string]string, len(me.Metadata))
@@ -112,12 +144,43 @@ func MapSchemaToMachineStates(entities []*Machine) []machine.MachineState {
 			ms.Metadata[k] = v
 		}
 
+		if me.TotalResources != nil {
+			ms.TotalResources = MapSchemaToResourceTuple(me.TotalResources)
+		}
+		if me.FreeResources != nil {
+			ms.FreeResources = MapSchemaToResourceTuple(me.FreeResources)
+		}
+		if me.MaxCapacity != nil {
+			ms.MaxUnits = int(me.MaxCapacity.Units)
+			ms.MaxWeight = int(me.MaxCapacity.Weight)
+		}
+		if me.UsedCapacity != nil {
+			ms.UsedUnits = int(me.UsedCapacity.Units)
+			ms.UsedWeight = int(me.UsedCapacity.Weight)
+		}
+
 		machines[i] = ms
 	}
 
 	return machines
 }
 
+func MapResourceTupleToSchema(rt resource.ResourceTuple) *Resources {
+	return &Resources{
+		Cores:  int64(rt.Cores),
+		Memory: int64(rt.Memory),
+		Disk:   int64(rt.Disk),
+	}
+}
+
+func MapSchemaToResourceTuple(res *Resources) resource.ResourceTuple {
+	return resource.ResourceTuple{
+		Cores:  int(res.Cores),
+		Memory: int(res.Memory),
+		Disk:   int(res.Disk),
+	}
+}
+
 func MapUnitStatesToSchemaUnitStates(entities []*unit.UnitState) []*UnitState {
 	sus := make([]*UnitState, len(entities))
 	for i, e := range entities {
@@ -129,12 +192,21 @@ func MapUnitStatesToSchemaUnitStates(entities []*unit.UnitState) []*UnitState {
 
 func MapUnitStateToSchemaUnitState(entity *unit.UnitState) *UnitState {
 	us := UnitState{
-		Name:               entity.UnitName,
-		Hash:               entity.UnitHash,
-		MachineID:          entity.MachineID,
-		SystemdLoadState:   entity.LoadState,
-		SystemdActiveState: entity.ActiveState,
-		SystemdSubState:    entity.SubState,
+		Name:                   entity.UnitName,
+		Hash:                   entity.UnitHash,
+		MachineID:              entity.MachineID,
+		SystemdLoadState:       entity.LoadState,
+		SystemdActiveState:     entity.ActiveState,
+		SystemdSubState:        entity.SubState,
+		Healthy:                entity.Healthy,
+		Failures:               int64(entity.Failures),
+		ExecMainStatus:         int64(entity.ExecMainStatus),
+		NRestarts:              int64(entity.NRestarts),
+		ActiveEnterTimestamp:   mapTimestampToSchema(entity.ActiveEnterTimestamp),
+		InactiveEnterTimestamp: mapTimestampToSchema(entity.InactiveEnterTimestamp),
+		CpuUsageNSec:           int64(entity.CPUUsageNSec),
+		MemoryCurrent:          int64(entity.MemoryCurrent),
+		TasksCurrent:           int64(entity.TasksCurrent),
 	}
 
 	return &us
@@ -144,18 +216,46 @@ func MapSchemaUnitStatesToUnitStates(entities []*UnitState) []*unit.UnitState {
 	us := make([]*unit.UnitState, len(entities))
 	for i, e := range entities {
 		us[i] = &unit.UnitState{
-			UnitName:    e.Name,
-			UnitHash:    e.Hash,
-			MachineID:   e.MachineID,
-			LoadState:   e.SystemdLoadState,
-			ActiveState: e.SystemdActiveState,
-			SubState:    e.SystemdSubState,
+			UnitName:               e.Name,
+			UnitHash:               e.Hash,
+			MachineID:              e.MachineID,
+			LoadState:              e.SystemdLoadState,
+			ActiveState:            e.SystemdActiveState,
+			SubState:               e.SystemdSubState,
+			Healthy:                e.Healthy,
+			Failures:               int(e.Failures),
+			ExecMainStatus:         int(e.ExecMainStatus),
+			NRestarts:              int(e.NRestarts),
+			ActiveEnterTimestamp:   mapSchemaToTimestamp(e.ActiveEnterTimestamp),
+			InactiveEnterTimestamp: mapSchemaToTimestamp(e.InactiveEnterTimestamp),
+			CPUUsageNSec:           uint64(e.CpuUsageNSec),
+			MemoryCurrent:          uint64(e.MemoryCurrent),
+			TasksCurrent:           uint64(e.TasksCurrent),
 		}
 	}
 
 	return us
 }
 
+// mapTimestampToSchema formats a systemd timestamp, in microseconds since
+// the epoch, in RFC3339 format. Zero timestamps are left empty.
+func mapTimestampToSchema(usec uint64) string {
+	if usec == 0 {
+		return ""
+	}
+	return time.Unix(0, int64(usec)*int64(time.Microsecond)).UTC().Format(time.RFC3339)
+}
+
+// mapSchemaToTimestamp parses a timestamp in RFC3339 format into
+// microseconds since the epoch, zero if it is empty or invalid.
+func mapSchemaToTimestamp(s string) uint64 {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return uint64(t.UnixNano() / int64(time.Microsecond))
+}
+
 func MapSchemaUnitToScheduledUnit(entity *Unit) *job.ScheduledUnit {
 	cs := job.JobState(entity.CurrentState)
 	return &job.ScheduledUnit{
@@ -65,6 +65,8 @@ func New(client *http.Client) (*Service, error) {
 		return nil, errors.New("client is nil")
 	}
 	s := &Service{client: client, BasePath: basePath}
+	s.Engine = NewEngineService(s)
+	s.Events = NewEventsService(s)
 	s.Machines = NewMachinesService(s)
 	s.UnitState = NewUnitStateService(s)
 	s.Units = NewUnitsService(s)
@@ -76,6 +78,10 @@ type Service struct {
 	BasePath  string // API endpoint base URL
 	UserAgent string // optional additional User-Agent fragment
 
+	Engine *EngineService
+
+	Events *EventsService
+
 	Machines *MachinesService
 
 	UnitState *UnitStateService
@@ -90,6 +96,24 @@ func (s *Service) userAgent() string {
 	return googleapi.UserAgent + " " + s.UserAgent
 }
 
+func NewEngineService(s *Service) *EngineService {
+	rs := &EngineService{s: s}
+	return rs
+}
+
+type EngineService struct {
+	s *Service
+}
+
+func NewEventsService(s *Service) *EventsService {
+	rs := &EventsService{s: s}
+	return rs
+}
+
+type EventsService struct {
+	s *Service
+}
+
 func NewMachinesService(s *Service) *MachinesService {
 	rs := &MachinesService{s: s}
 	return rs
@@ -117,14 +141,184 @@ type UnitsService struct {
 	s *Service
 }
 
+type Capacity struct {
+	// Units: Number of units.
+	Units int64 `json:"units,omitempty"`
+
+	// Weight: Total Weight of the units.
+	Weight int64 `json:"weight,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "Units") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Units") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *Capacity) MarshalJSON() ([]byte, error) {
+	type noMethod Capacity
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type Engine struct {
+	Scheduler string `json:"scheduler,omitempty"`
+
+	// ServerResponse contains the HTTP response code and headers from the
+	// server.
+	googleapi.ServerResponse `json:"-"`
+
+	// ForceSendFields is a list of field names (e.g. "Scheduler") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Scheduler") to include in
+	// API requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *Engine) MarshalJSON() ([]byte, error) {
+	type noMethod Engine
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type EngineEvent struct {
+	LeaderID string `json:"leaderID,omitempty"`
+
+	MachineID string `json:"machineID,omitempty"`
+
+	PreviousMachineID string `json:"previousMachineID,omitempty"`
+
+	Reason string `json:"reason,omitempty"`
+
+	Time string `json:"time,omitempty"`
+
+	Type string `json:"type,omitempty"`
+
+	UnitName string `json:"unitName,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "LeaderID") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "LeaderID") to include in
+	// API requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *EngineEvent) MarshalJSON() ([]byte, error) {
+	type noMethod EngineEvent
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type EngineEventPage struct {
+	Events []*EngineEvent `json:"events,omitempty"`
+
+	// ServerResponse contains the HTTP response code and headers from the
+	// server.
+	googleapi.ServerResponse `json:"-"`
+
+	// ForceSendFields is a list of field names (e.g. "Events") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Events") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *EngineEventPage) MarshalJSON() ([]byte, error) {
+	type noMethod EngineEventPage
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
 type Machine struct {
+	FreeResources *Resources `json:"freeResources,omitempty"`
+
 	Id string `json:"id,omitempty"`
 
+	Maintenance string `json:"maintenance,omitempty"`
+
+	MaxCapacity *Capacity `json:"maxCapacity,omitempty"`
+
 	Metadata map[string]string `json:"metadata,omitempty"`
 
 	PrimaryIP string `json:"primaryIP,omitempty"`
 
-	// ForceSendFields is a list of field names (e.g. "Id") to
+	TotalResources *Resources `json:"totalResources,omitempty"`
+
+	UsedCapacity *Capacity `json:"usedCapacity,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "FreeResources") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "FreeResources") to include
+	// in API requests with the JSON null value. By default, fields with
+	// empty values are omitted from API requests. However, any field with
+	// an empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *Machine) MarshalJSON() ([]byte, error) {
+	type noMethod Machine
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type MachineExplanation struct {
+	Able bool `json:"able,omitempty"`
+
+	MachineID string `json:"machineID,omitempty"`
+
+	PrimaryIP string `json:"primaryIP,omitempty"`
+
+	Reason string `json:"reason,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "Able") to
 	// unconditionally include in API requests. By default, fields with
 	// empty values are omitted from API requests. However, any non-pointer,
 	// non-interface field appearing in ForceSendFields will be sent to the
@@ -132,7 +326,7 @@ type Machine struct {
 	// used to include empty fields in Patch requests.
 	ForceSendFields []string `json:"-"`
 
-	// NullFields is a list of field names (e.g. "Id") to include in API
+	// NullFields is a list of field names (e.g. "Able") to include in API
 	// requests with the JSON null value. By default, fields with empty
 	// values are omitted from API requests. However, any field with an
 	// empty value appearing in NullFields will be sent to the server as
@@ -141,8 +335,64 @@ type Machine struct {
 	NullFields []string `json:"-"`
 }
 
-func (s *Machine) MarshalJSON() ([]byte, error) {
-	type noMethod Machine
+func (s *MachineExplanation) MarshalJSON() ([]byte, error) {
+	type noMethod MachineExplanation
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type MachineOperation struct {
+	Op string `json:"op,omitempty"`
+
+	Path string `json:"path,omitempty"`
+
+	Value *MachineOperationValue `json:"value,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "Op") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Op") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *MachineOperation) MarshalJSON() ([]byte, error) {
+	type noMethod MachineOperation
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type MachineOperationValue struct {
+	Value string `json:"value,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "Value") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Value") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *MachineOperationValue) MarshalJSON() ([]byte, error) {
+	type noMethod MachineOperationValue
 	raw := noMethod(*s)
 	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
@@ -179,6 +429,39 @@ func (s *MachinePage) MarshalJSON() ([]byte, error) {
 	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
 
+type Resources struct {
+	// Cores: CPU cores in hundredths of a core.
+	Cores int64 `json:"cores,omitempty"`
+
+	// Disk: Disk space in MB.
+	Disk int64 `json:"disk,omitempty"`
+
+	// Memory: Memory in MB.
+	Memory int64 `json:"memory,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "Cores") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Cores") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *Resources) MarshalJSON() ([]byte, error) {
+	type noMethod Resources
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
 type Unit struct {
 	// Possible values:
 	//   "inactive"
@@ -198,6 +481,10 @@ type Unit struct {
 
 	Options []*UnitOption `json:"options,omitempty"`
 
+	// Replicas: Number of instances the engine keeps of a template unit
+	// setting the Replicas option.
+	Replicas int64 `json:"replicas,omitempty"`
+
 	// ServerResponse contains the HTTP response code and headers from the
 	// server.
 	googleapi.ServerResponse `json:"-"`
@@ -225,6 +512,42 @@ func (s *Unit) MarshalJSON() ([]byte, error) {
 	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
 
+type UnitExplanation struct {
+	MachineID string `json:"machineID,omitempty"`
+
+	Machines []*MachineExplanation `json:"machines,omitempty"`
+
+	Name string `json:"name,omitempty"`
+
+	Scheduler string `json:"scheduler,omitempty"`
+
+	// ServerResponse contains the HTTP response code and headers from the
+	// server.
+	googleapi.ServerResponse `json:"-"`
+
+	// ForceSendFields is a list of field names (e.g. "MachineID") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "MachineID") to include in
+	// API requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *UnitExplanation) MarshalJSON() ([]byte, error) {
+	type noMethod UnitExplanation
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
 type UnitOption struct {
 	Name string `json:"name,omitempty"`
 
@@ -288,10 +611,47 @@ func (s *UnitPage) MarshalJSON() ([]byte, error) {
 }
 
 type UnitState struct {
+	// ActiveEnterTimestamp: Time the unit last entered the active state, in
+	// RFC3339 format. Absent if it never did.
+	ActiveEnterTimestamp string `json:"activeEnterTimestamp,omitempty"`
+
+	// CpuUsageNSec: CPU time consumed by the unit in nanoseconds, as
+	// accounted by systemd. Absent if CPU accounting is not enabled for the
+	// unit.
+	CpuUsageNSec int64 `json:"cpuUsageNSec,omitempty"`
+
+	// ExecMainStatus: Exit status of the main process of the unit, as
+	// reported by systemd for services.
+	ExecMainStatus int64 `json:"execMainStatus,omitempty"`
+
+	// Failures: Number of times the unit entered the failed state since it
+	// was loaded on its machine.
+	Failures int64 `json:"failures,omitempty"`
+
 	Hash string `json:"hash,omitempty"`
 
+	// Healthy: Result of the health check of the unit. Absent if the unit
+	// has no health check, in which case it is considered healthy, or if
+	// the result is not known yet.
+	//
+	// Default: true
+	Healthy *bool `json:"healthy,omitempty"`
+
+	// InactiveEnterTimestamp: Time the unit last entered the inactive
+	// state, in RFC3339 format. Absent if it never did.
+	InactiveEnterTimestamp string `json:"inactiveEnterTimestamp,omitempty"`
+
 	MachineID string `json:"machineID,omitempty"`
 
+	// MemoryCurrent: Memory currently used by the unit in bytes, as
+	// accounted by systemd. Absent if memory accounting is not enabled for
+	// the unit.
+	MemoryCurrent int64 `json:"memoryCurrent,omitempty"`
+
+	// NRestarts: Number of automatic restarts of the unit, as reported by
+	// systemd for services.
+	NRestarts int64 `json:"nRestarts,omitempty"`
+
 	Name string `json:"name,omitempty"`
 
 	SystemdActiveState string `json:"systemdActiveState,omitempty"`
@@ -300,24 +660,31 @@ type UnitState struct {
 
 	SystemdSubState string `json:"systemdSubState,omitempty"`
 
+	// TasksCurrent: Number of tasks currently running in the unit, as
+	// accounted by systemd. Absent if tasks accounting is not enabled for
+	// the unit.
+	TasksCurrent int64 `json:"tasksCurrent,omitempty"`
+
 	// ServerResponse contains the HTTP response code and headers from the
 	// server.
 	googleapi.ServerResponse `json:"-"`
 
-	// ForceSendFields is a list of field names (e.g. "Hash") to
-	// unconditionally include in API requests. By default, fields with
-	// empty values are omitted from API requests. However, any non-pointer,
-	// non-interface field appearing in ForceSendFields will be sent to the
-	// server regardless of whether the field is empty or not. This may be
-	// used to include empty fields in Patch requests.
+	// ForceSendFields is a list of field names (e.g.
+	// "ActiveEnterTimestamp") to unconditionally include in API requests.
+	// By default, fields with empty values are omitted from API requests.
+	// However, any non-pointer, non-interface field appearing in
+	// ForceSendFields will be sent to the server regardless of whether the
+	// field is empty or not. This may be used to include empty fields in
+	// Patch requests.
 	ForceSendFields []string `json:"-"`
 
-	// NullFields is a list of field names (e.g. "Hash") to include in API
-	// requests with the JSON null value. By default, fields with empty
-	// values are omitted from API requests. However, any field with an
-	// empty value appearing in NullFields will be sent to the server as
-	// null. It is an error if a field in this list has a non-empty value.
-	// This may be used to include null fields in Patch requests.
+	// NullFields is a list of field names (e.g. "ActiveEnterTimestamp") to
+	// include in API requests with the JSON null value. By default, fields
+	// with empty values are omitted from API requests. However, any field
+	// with an empty value appearing in NullFields will be sent to the
+	// server as null. It is an error if a field in this list has a
+	// non-empty value. This may be used to include null fields in Patch
+	// requests.
 	NullFields []string `json:"-"`
 }
 
@@ -344,19 +711,278 @@ type UnitStatePage struct {
 	// used to include empty fields in Patch requests.
 	ForceSendFields []string `json:"-"`
 
-	// NullFields is a list of field names (e.g. "NextPageToken") to include
-	// in API requests with the JSON null value. By default, fields with
-	// empty values are omitted from API requests. However, any field with
-	// an empty value appearing in NullFields will be sent to the server as
-	// null. It is an error if a field in this list has a non-empty value.
-	// This may be used to include null fields in Patch requests.
-	NullFields []string `json:"-"`
+	// NullFields is a list of field names (e.g. "NextPageToken") to include
+	// in API requests with the JSON null value. By default, fields with
+	// empty values are omitted from API requests. However, any field with
+	// an empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *UnitStatePage) MarshalJSON() ([]byte, error) {
+	type noMethod UnitStatePage
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+// method id "fleet.Engine.Get":
+
+type EngineGetCall struct {
+	s            *Service
+	urlParams_   gensupport.URLParams
+	ifNoneMatch_ string
+	ctx_         context.Context
+	header_      http.Header
+}
+
+// Get: Retrieve the state of the engine leader.
+func (r *EngineService) Get() *EngineGetCall {
+	c := &EngineGetCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *EngineGetCall) Fields(s ...googleapi.Field) *EngineGetCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// IfNoneMatch sets the optional parameter which makes the operation
+// fail if the object's ETag matches the given value. This is useful for
+// getting updates only after the object has changed since the last
+// request. Use googleapi.IsNotModified to check whether the response
+// error from Do is the result of In-None-Match.
+func (c *EngineGetCall) IfNoneMatch(entityTag string) *EngineGetCall {
+	c.ifNoneMatch_ = entityTag
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *EngineGetCall) Context(ctx context.Context) *EngineGetCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *EngineGetCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *EngineGetCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	if c.ifNoneMatch_ != "" {
+		reqHeaders.Set("If-None-Match", c.ifNoneMatch_)
+	}
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "engine")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("GET", urls, body)
+	req.Header = reqHeaders
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Engine.Get" call.
+// Exactly one of *Engine or error will be non-nil. Any non-2xx status
+// code is an error. Response headers are in either
+// *Engine.ServerResponse.Header or (if a response was returned at all)
+// in error.(*googleapi.Error).Header. Use googleapi.IsNotModified to
+// check whether the returned error was because http.StatusNotModified
+// was returned.
+func (c *EngineGetCall) Do(opts ...googleapi.CallOption) (*Engine, error) {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if res != nil && res.StatusCode == http.StatusNotModified {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return nil, &googleapi.Error{
+			Code:   res.StatusCode,
+			Header: res.Header,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return nil, err
+	}
+	ret := &Engine{
+		ServerResponse: googleapi.ServerResponse{
+			Header:         res.Header,
+			HTTPStatusCode: res.StatusCode,
+		},
+	}
+	target := &ret
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return nil, err
+	}
+	return ret, nil
+	// {
+	//   "description": "Retrieve the state of the engine leader.",
+	//   "httpMethod": "GET",
+	//   "id": "fleet.Engine.Get",
+	//   "path": "engine",
+	//   "response": {
+	//     "$ref": "Engine"
+	//   }
+	// }
+
+}
+
+// method id "fleet.Events.List":
+
+type EventsListCall struct {
+	s            *Service
+	urlParams_   gensupport.URLParams
+	ifNoneMatch_ string
+	ctx_         context.Context
+	header_      http.Header
+}
+
+// List: Retrieve the most recent scheduling decisions of the engine
+// leaders, oldest first.
+func (r *EventsService) List() *EventsListCall {
+	c := &EventsListCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	return c
+}
+
+// MachineID sets the optional parameter "machineID":
+func (c *EventsListCall) MachineID(machineID string) *EventsListCall {
+	c.urlParams_.Set("machineID", machineID)
+	return c
+}
+
+// UnitName sets the optional parameter "unitName":
+func (c *EventsListCall) UnitName(unitName string) *EventsListCall {
+	c.urlParams_.Set("unitName", unitName)
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *EventsListCall) Fields(s ...googleapi.Field) *EventsListCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// IfNoneMatch sets the optional parameter which makes the operation
+// fail if the object's ETag matches the given value. This is useful for
+// getting updates only after the object has changed since the last
+// request. Use googleapi.IsNotModified to check whether the response
+// error from Do is the result of In-None-Match.
+func (c *EventsListCall) IfNoneMatch(entityTag string) *EventsListCall {
+	c.ifNoneMatch_ = entityTag
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *EventsListCall) Context(ctx context.Context) *EventsListCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *EventsListCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *EventsListCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	if c.ifNoneMatch_ != "" {
+		reqHeaders.Set("If-None-Match", c.ifNoneMatch_)
+	}
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "events")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("GET", urls, body)
+	req.Header = reqHeaders
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
 }
 
-func (s *UnitStatePage) MarshalJSON() ([]byte, error) {
-	type noMethod UnitStatePage
-	raw := noMethod(*s)
-	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+// Do executes the "fleet.Events.List" call.
+// Exactly one of *EngineEventPage or error will be non-nil. Any non-2xx
+// status code is an error. Response headers are in either
+// *EngineEventPage.ServerResponse.Header or (if a response was returned
+// at all) in error.(*googleapi.Error).Header. Use
+// googleapi.IsNotModified to check whether the returned error was
+// because http.StatusNotModified was returned.
+func (c *EventsListCall) Do(opts ...googleapi.CallOption) (*EngineEventPage, error) {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if res != nil && res.StatusCode == http.StatusNotModified {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return nil, &googleapi.Error{
+			Code:   res.StatusCode,
+			Header: res.Header,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return nil, err
+	}
+	ret := &EngineEventPage{
+		ServerResponse: googleapi.ServerResponse{
+			Header:         res.Header,
+			HTTPStatusCode: res.StatusCode,
+		},
+	}
+	target := &ret
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return nil, err
+	}
+	return ret, nil
+	// {
+	//   "description": "Retrieve the most recent scheduling decisions of the engine leaders, oldest first.",
+	//   "httpMethod": "GET",
+	//   "id": "fleet.Events.List",
+	//   "parameters": {
+	//     "machineID": {
+	//       "location": "query",
+	//       "type": "string"
+	//     },
+	//     "unitName": {
+	//       "location": "query",
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "events",
+	//   "response": {
+	//     "$ref": "EngineEventPage"
+	//   }
+	// }
+
 }
 
 // method id "fleet.Machine.List":
@@ -489,6 +1115,93 @@ func (c *MachinesListCall) Do(opts ...googleapi.CallOption) (*MachinePage, error
 
 }
 
+// method id "fleet.Machine.Patch":
+
+type MachinesPatchCall struct {
+	s                    *Service
+	machineoperationlist *MachineOperationList
+	urlParams_           gensupport.URLParams
+	ctx_                 context.Context
+	header_              http.Header
+}
+
+// Patch: Modify the metadata or the maintenance mode of Machines with a
+// JSONPatch document.
+func (r *MachinesService) Patch(machineoperationlist *MachineOperationList) *MachinesPatchCall {
+	c := &MachinesPatchCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.machineoperationlist = machineoperationlist
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *MachinesPatchCall) Fields(s ...googleapi.Field) *MachinesPatchCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *MachinesPatchCall) Context(ctx context.Context) *MachinesPatchCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *MachinesPatchCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *MachinesPatchCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	var body io.Reader = nil
+	body, err := googleapi.WithoutDataWrapper.JSONReader(c.machineoperationlist)
+	if err != nil {
+		return nil, err
+	}
+	reqHeaders.Set("Content-Type", "application/json")
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "machines")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("PATCH", urls, body)
+	req.Header = reqHeaders
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Machine.Patch" call.
+func (c *MachinesPatchCall) Do(opts ...googleapi.CallOption) error {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return err
+	}
+	return nil
+	// {
+	//   "description": "Modify the metadata or the maintenance mode of Machines with a JSONPatch document.",
+	//   "httpMethod": "PATCH",
+	//   "id": "fleet.Machine.Patch",
+	//   "path": "machines",
+	//   "request": {
+	//     "$ref": "MachineOperationList"
+	//   }
+	// }
+
+}
+
 // method id "fleet.UnitState.Get":
 
 type UnitStateGetCall struct {
@@ -863,6 +1576,139 @@ func (c *UnitsDeleteCall) Do(opts ...googleapi.CallOption) error {
 
 }
 
+// method id "fleet.Unit.Explain":
+
+type UnitsExplainCall struct {
+	s            *Service
+	unitName     string
+	urlParams_   gensupport.URLParams
+	ifNoneMatch_ string
+	ctx_         context.Context
+	header_      http.Header
+}
+
+// Explain: Explain which machines are able to run a Unit.
+func (r *UnitsService) Explain(unitName string) *UnitsExplainCall {
+	c := &UnitsExplainCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.unitName = unitName
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *UnitsExplainCall) Fields(s ...googleapi.Field) *UnitsExplainCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// IfNoneMatch sets the optional parameter which makes the operation
+// fail if the object's ETag matches the given value. This is useful for
+// getting updates only after the object has changed since the last
+// request. Use googleapi.IsNotModified to check whether the response
+// error from Do is the result of In-None-Match.
+func (c *UnitsExplainCall) IfNoneMatch(entityTag string) *UnitsExplainCall {
+	c.ifNoneMatch_ = entityTag
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *UnitsExplainCall) Context(ctx context.Context) *UnitsExplainCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *UnitsExplainCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *UnitsExplainCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	if c.ifNoneMatch_ != "" {
+		reqHeaders.Set("If-None-Match", c.ifNoneMatch_)
+	}
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "units/{unitName}/explain")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("GET", urls, body)
+	req.Header = reqHeaders
+	googleapi.Expand(req.URL, map[string]string{
+		"unitName": c.unitName,
+	})
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Unit.Explain" call.
+// Exactly one of *UnitExplanation or error will be non-nil. Any non-2xx
+// status code is an error. Response headers are in either
+// *UnitExplanation.ServerResponse.Header or (if a response was returned
+// at all) in error.(*googleapi.Error).Header. Use
+// googleapi.IsNotModified to check whether the returned error was
+// because http.StatusNotModified was returned.
+func (c *UnitsExplainCall) Do(opts ...googleapi.CallOption) (*UnitExplanation, error) {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if res != nil && res.StatusCode == http.StatusNotModified {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return nil, &googleapi.Error{
+			Code:   res.StatusCode,
+			Header: res.Header,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return nil, err
+	}
+	ret := &UnitExplanation{
+		ServerResponse: googleapi.ServerResponse{
+			Header:         res.Header,
+			HTTPStatusCode: res.StatusCode,
+		},
+	}
+	target := &ret
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return nil, err
+	}
+	return ret, nil
+	// {
+	//   "description": "Explain which machines are able to run a Unit.",
+	//   "httpMethod": "GET",
+	//   "id": "fleet.Unit.Explain",
+	//   "parameterOrder": [
+	//     "unitName"
+	//   ],
+	//   "parameters": {
+	//     "unitName": {
+	//       "location": "path",
+	//       "required": true,
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "units/{unitName}/explain",
+	//   "response": {
+	//     "$ref": "UnitExplanation"
+	//   }
+	// }
+
+}
+
 // method id "fleet.Unit.Get":
 
 type UnitsGetCall struct {
@@ -42,6 +42,54 @@ const DiscoveryJSON = `{
   "parameters": {},
   "auth": {},
   "schemas": {
+    "Engine": {
+      "id": "Engine",
+      "type": "object",
+      "properties": {
+        "scheduler": {
+          "type": "string"
+        }
+      }
+    },
+    "EngineEvent": {
+      "id": "EngineEvent",
+      "type": "object",
+      "properties": {
+        "time": {
+          "type": "string"
+        },
+        "leaderID": {
+          "type": "string"
+        },
+        "type": {
+          "type": "string"
+        },
+        "unitName": {
+          "type": "string"
+        },
+        "machineID": {
+          "type": "string"
+        },
+        "previousMachineID": {
+          "type": "string"
+        },
+        "reason": {
+          "type": "string"
+        }
+      }
+    },
+    "EngineEventPage": {
+      "id": "EngineEventPage",
+      "type": "object",
+      "properties": {
+        "events": {
+          "type": "array",
+          "items": {
+            "$ref": "EngineEvent"
+          }
+        }
+      }
+    },
     "Machine": {
       "id": "Machine",
       "type": "object",
@@ -58,6 +106,53 @@ const DiscoveryJSON = `{
           "additionalProperties": {
             "type": "string"
           }
+        },
+        "totalResources": {
+          "$ref": "Resources"
+        },
+        "freeResources": {
+          "$ref": "Resources"
+        },
+        "maintenance": {
+          "type": "string"
+        },
+        "maxCapacity": {
+          "$ref": "Capacity"
+        },
+        "usedCapacity": {
+          "$ref": "Capacity"
+        }
+      }
+    },
+    "Capacity": {
+      "id": "Capacity",
+      "type": "object",
+      "properties": {
+        "units": {
+          "type": "integer",
+          "description": "Number of units."
+        },
+        "weight": {
+          "type": "integer",
+          "description": "Total Weight of the units."
+        }
+      }
+    },
+    "Resources": {
+      "id": "Resources",
+      "type": "object",
+      "properties": {
+        "cores": {
+          "type": "integer",
+          "description": "CPU cores in hundredths of a core."
+        },
+        "memory": {
+          "type": "integer",
+          "description": "Memory in MB."
+        },
+        "disk": {
+          "type": "integer",
+          "description": "Disk space in MB."
         }
       }
     },
@@ -76,6 +171,33 @@ const DiscoveryJSON = `{
         }
       }
     },
+    "MachineOperation": {
+      "id": "MachineOperation",
+      "type": "object",
+      "properties": {
+        "op": {
+          "type": "string"
+        },
+        "path": {
+          "type": "string"
+        },
+        "value": {
+          "type": "object",
+          "properties": {
+            "value": {
+              "type": "string"
+            }
+          }
+        }
+      }
+    },
+    "MachineOperationList": {
+      "id": "MachineOperationList",
+      "type": "array",
+      "items": {
+        "$ref": "MachineOperation"
+      }
+    },
     "UnitOption": {
       "id": "UnitOption",
       "type": "object",
@@ -123,6 +245,10 @@ const DiscoveryJSON = `{
         "machineID": {
           "type": "string",
           "required": true
+        },
+        "replicas": {
+          "type": "integer",
+          "description": "Number of instances the engine keeps of a template unit setting the Replicas option."
         }
       }
     },
@@ -162,6 +288,43 @@ const DiscoveryJSON = `{
         },
         "systemdSubState": {
           "type": "string"
+        },
+        "healthy": {
+          "type": "boolean",
+          "default": "true",
+          "description": "Result of the health check of the unit. Absent if the unit has no health check, in which case it is considered healthy, or if the result is not known yet."
+        },
+        "failures": {
+          "type": "integer",
+          "description": "Number of times the unit entered the failed state since it was loaded on its machine."
+        },
+        "execMainStatus": {
+          "type": "integer",
+          "description": "Exit status of the main process of the unit, as reported by systemd for services."
+        },
+        "nRestarts": {
+          "type": "integer",
+          "description": "Number of automatic restarts of the unit, as reported by systemd for services."
+        },
+        "activeEnterTimestamp": {
+          "type": "string",
+          "description": "Time the unit last entered the active state, in RFC3339 format. Absent if it never did."
+        },
+        "inactiveEnterTimestamp": {
+          "type": "string",
+          "description": "Time the unit last entered the inactive state, in RFC3339 format. Absent if it never did."
+        },
+        "cpuUsageNSec": {
+          "type": "integer",
+          "description": "CPU time consumed by the unit in nanoseconds, as accounted by systemd. Absent if CPU accounting is not enabled for the unit."
+        },
+        "memoryCurrent": {
+          "type": "integer",
+          "description": "Memory currently used by the unit in bytes, as accounted by systemd. Absent if memory accounting is not enabled for the unit."
+        },
+        "tasksCurrent": {
+          "type": "integer",
+          "description": "Number of tasks currently running in the unit, as accounted by systemd. Absent if tasks accounting is not enabled for the unit."
         }
       }
     },
@@ -179,9 +342,84 @@ const DiscoveryJSON = `{
           "type": "string"
         }
       }
+    },
+    "MachineExplanation": {
+      "id": "MachineExplanation",
+      "type": "object",
+      "properties": {
+        "machineID": {
+          "type": "string"
+        },
+        "primaryIP": {
+          "type": "string"
+        },
+        "able": {
+          "type": "boolean"
+        },
+        "reason": {
+          "type": "string"
+        }
+      }
+    },
+    "UnitExplanation": {
+      "id": "UnitExplanation",
+      "type": "object",
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "machineID": {
+          "type": "string"
+        },
+        "scheduler": {
+          "type": "string"
+        },
+        "machines": {
+          "type": "array",
+          "items": {
+            "$ref": "MachineExplanation"
+          }
+        }
+      }
     }
   },
   "resources": {
+    "Engine": {
+      "methods": {
+        "Get": {
+          "id": "fleet.Engine.Get",
+          "description": "Retrieve the state of the engine leader.",
+          "httpMethod": "GET",
+          "path": "engine",
+          "response": {
+            "$ref": "Engine"
+          }
+        }
+      }
+    },
+    "Events": {
+      "methods": {
+        "List": {
+          "id": "fleet.Events.List",
+          "description": "Retrieve the most recent scheduling decisions of the engine leaders, oldest first.",
+          "httpMethod": "GET",
+          "path": "events",
+          "parameters": {
+            "unitName": {
+              "type": "string",
+              "location": "query"
+            },
+            "machineID": {
+              "type": "string",
+              "location": "query"
+            }
+          },
+          "response": {
+            "$ref": "EngineEventPage"
+          }
+        }
+      }
+    },
     "Machines": {
       "methods": {
         "List": {
@@ -198,6 +436,15 @@ const DiscoveryJSON = `{
           "response": {
             "$ref": "MachinePage"
           }
+        },
+        "Patch": {
+          "id": "fleet.Machine.Patch",
+          "description": "Modify the metadata or the maintenance mode of Machines with a JSONPatch document.",
+          "httpMethod": "PATCH",
+          "path": "machines",
+          "request": {
+            "$ref": "MachineOperationList"
+          }
         }
       }
     },
@@ -271,6 +518,25 @@ const DiscoveryJSON = `{
           "request": {
             "$ref": "Unit"
           }
+        },
+        "Explain": {
+          "id": "fleet.Unit.Explain",
+          "description": "Explain which machines are able to run a Unit.",
+          "httpMethod": "GET",
+          "path": "units/{unitName}/explain",
+          "parameters": {
+            "unitName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            }
+          },
+          "parameterOrder": [
+            "unitName"
+          ],
+          "response": {
+            "$ref": "UnitExplanation"
+          }
         }
       }
     },
@@ -64,6 +64,8 @@ type Server struct {
 	reconfigServer bool
 	restartServer  bool
 	eClient        etcd.Client
+	// regMux is the registry of a fleetd in gRPC mode
+	regMux *rpc.RegistryMux
 
 	engineReconcileInterval time.Duration
 
@@ -131,6 +133,7 @@ func New(cfg config.Config, listeners []net.Listener) (*Server, error) {
 	gen := unit.NewUnitStateGenerator(mgr)
 
 	a := agent.New(mgr, gen, reg, mach, agentTTL)
+	gen.SetHealthReporter(a)
 
 	var rStream pkg.EventStream
 	if !cfg.DisableWatches {
@@ -138,18 +141,75 @@ func New(cfg config.Config, listeners []net.Listener) (*Server, error) {
 	}
 
 	ar := agent.NewReconciler(reg, rStream)
+	ar.SetTaskParallelism(cfg.AgentTaskParallelism)
 
-	var e *engine.Engine
+	sched, err := engine.NewScheduler(cfg.EngineScheduler)
+	if err != nil {
+		return nil, err
+	}
+
+	var rebal *engine.Rebalancer
+	if cfg.EngineRebalanceThreshold > 0 {
+		rebal = &engine.Rebalancer{
+			Threshold: uint16(cfg.EngineRebalanceThreshold),
+			MaxMoves:  cfg.EngineRebalanceMaxMoves,
+		}
+	}
+
+	rescheduleDelay, err := time.ParseDuration(cfg.EngineRescheduleDelay)
+	if err != nil {
+		return nil, err
+	}
+
+	// Warm standby engines follow the unit changes the same way as the
+	// incremental reconciliation does.
+	if cfg.EngineWarmStandby {
+		switch {
+		case cfg.DisableWatches:
+			return nil, errors.New("engine_warm_standby is not supported with disable_watches")
+		case cfg.EngineResyncInterval <= 0:
+			return nil, errors.New("engine_warm_standby requires engine_resync_interval to be set")
+		}
+	}
+
+	var (
+		e      *engine.Engine
+		regMux *rpc.RegistryMux
+	)
 	if !cfg.EnableGRPC {
-		e = engine.New(reg, lManager, rStream, mach, nil)
+		e = engine.New(reg, lManager, rStream, mach, sched, rebal, rescheduleDelay, nil)
 	} else {
-		regMux := genericReg.(*rpc.RegistryMux)
-		e = engine.New(reg, lManager, rStream, mach, regMux.EngineChanged)
+		regMux = genericReg.(*rpc.RegistryMux)
+		e = engine.New(reg, lManager, rStream, mach, sched, rebal, rescheduleDelay, regMux.EngineChanged)
 		if cfg.DisableEngine {
 			go regMux.ConnectToRegistry(e)
 		}
 	}
 
+	th := engine.Throttle{
+		MaxSchedules:             cfg.EngineMaxSchedules,
+		MaxUnschedules:           cfg.EngineMaxUnschedules,
+		MaxSchedulesPerMachine:   cfg.EngineMaxSchedulesPerMachine,
+		MaxUnschedulesPerMachine: cfg.EngineMaxUnschedulesPerMachine,
+	}
+	if th != (engine.Throttle{}) {
+		e.SetThrottle(&th)
+	}
+
+	// Changes of units are only watched with the etcd registry, which
+	// gRPC mode bypasses. Warm standby engines replicate the in-memory
+	// registry of the leader from etcd instead.
+	if cfg.EngineResyncInterval > 0 && !cfg.DisableWatches {
+		resyncIval := time.Duration(cfg.EngineResyncInterval*1000) * time.Millisecond
+		changes := registry.NewEtcdUnitChangeStream(kAPI, cfg.EtcdKeyPrefix)
+		if !cfg.EnableGRPC {
+			e.SetIncremental(changes, resyncIval)
+			e.SetWarmStandby(cfg.EngineWarmStandby)
+		} else if cfg.EngineWarmStandby {
+			regMux.SetWarmStandby(changes, resyncIval)
+		}
+	}
+
 	if len(listeners) == 0 {
 		listeners, err = activation.Listeners(false)
 		if err != nil {
@@ -160,7 +220,7 @@ func New(cfg config.Config, listeners []net.Listener) (*Server, error) {
 	hrt := heart.New(reg, mach)
 	mon := NewMonitor(agentTTL)
 
-	apiServer := api.NewServer(listeners, api.NewServeMux(reg, cfg.TokenLimit))
+	apiServer := api.NewServer(listeners, api.NewServeMux(reg, reg, cfg.TokenLimit))
 	apiServer.Serve()
 
 	eIval := time.Duration(cfg.EngineReconcileInterval*1000) * time.Millisecond
@@ -182,6 +242,7 @@ func New(cfg config.Config, listeners []net.Listener) (*Server, error) {
 		reconfigServer:          false,
 		restartServer:           false,
 		eClient:                 eClient,
+		regMux:                  regMux,
 	}
 
 	return &srv, nil
@@ -189,10 +250,13 @@ func New(cfg config.Config, listeners []net.Listener) (*Server, error) {
 
 func newMachineFromConfig(cfg config.Config, mgr unit.UnitManager) (*machine.CoreOSMachine, error) {
 	state := machine.MachineState{
-		PublicIP:     cfg.PublicIP,
-		Metadata:     cfg.Metadata(),
-		Capabilities: cfg.Capabilities(),
-		Version:      version.Version,
+		PublicIP:       cfg.PublicIP,
+		Metadata:       cfg.Metadata(),
+		Capabilities:   cfg.Capabilities(),
+		Version:        version.Version,
+		TotalResources: cfg.TotalResources(),
+		MaxUnits:       cfg.MaxUnits,
+		MaxWeight:      cfg.MaxWeight,
 	}
 
 	mach := machine.NewCoreOSMachine(state, mgr)
@@ -240,6 +304,7 @@ func (s *Server) Run() {
 		func() { s.api.Available(s.stopc) },
 		func() { s.mach.PeriodicRefresh(machineStateRefreshInterval, s.stopc) },
 		func() { s.agent.Heartbeat(s.stopc) },
+		func() { s.agent.CheckHealth(s.stopc) },
 		func() { s.aReconciler.Run(s.agent, s.stopc) },
 		func() { s.usGen.Run(beatc, s.stopc) },
 		func() { s.usPub.Run(beatc, s.stopc) },
@@ -248,6 +313,9 @@ func (s *Server) Run() {
 		log.Info("Not starting engine; disable-engine is set")
 	} else {
 		components = append(components, func() { s.engine.Run(s.engineReconcileInterval, s.stopc) })
+		if s.regMux != nil {
+			components = append(components, func() { s.regMux.Replicate(s.stopc) })
+		}
 	}
 	for _, f := range components {
 		f := f
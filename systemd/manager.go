@@ -17,6 +17,7 @@ package systemd
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"path"
 	"sync"
@@ -181,6 +182,59 @@ func (m *systemdUnitManager) getUnitState(name string) (*unit.UnitState, error)
 	return &us, nil
 }
 
+// getUnitDetails completes the UnitState with the details systemd reports
+// about the unit. Failing to get them is not fatal, the UnitState is left
+// as it is then.
+func (m *systemdUnitManager) getUnitDetails(name string, us *unit.UnitState) {
+	unitProps, err := m.systemd.GetUnitProperties(name)
+	if err != nil {
+		log.Debugf("Failed fetching properties of unit %s: %v", name, err)
+		return
+	}
+
+	var serviceProps map[string]interface{}
+	if path.Ext(name) == ".service" {
+		serviceProps, err = m.systemd.GetUnitTypeProperties(name, "Service")
+		if err != nil {
+			log.Debugf("Failed fetching service properties of unit %s: %v", name, err)
+		}
+	}
+
+	setUnitDetails(us, unitProps, serviceProps)
+}
+
+// setUnitDetails fills the UnitState with the state change timestamps
+// found in the properties of the unit, and with the exit status, restart
+// count and resource usage found in the properties of its service, if any.
+// Properties missing, e.g. NRestarts with systemd older than 235, are left
+// zero, and so is the usage systemd does not account for.
+func setUnitDetails(us *unit.UnitState, unitProps, serviceProps map[string]interface{}) {
+	us.ActiveEnterTimestamp, _ = unitProps["ActiveEnterTimestamp"].(uint64)
+	us.InactiveEnterTimestamp, _ = unitProps["InactiveEnterTimestamp"].(uint64)
+
+	if status, ok := serviceProps["ExecMainStatus"].(int32); ok {
+		us.ExecMainStatus = int(status)
+	}
+	if restarts, ok := serviceProps["NRestarts"].(uint32); ok {
+		us.NRestarts = int(restarts)
+	}
+
+	us.CPUUsageNSec = accounted(serviceProps["CPUUsageNSec"])
+	us.MemoryCurrent = accounted(serviceProps["MemoryCurrent"])
+	us.TasksCurrent = accounted(serviceProps["TasksCurrent"])
+}
+
+// accounted returns the value of a resource usage property, zero if it is
+// missing or if systemd reports it as not set, which it does with the
+// highest uint64.
+func accounted(prop interface{}) uint64 {
+	v, ok := prop.(uint64)
+	if !ok || v == math.MaxUint64 {
+		return 0
+	}
+	return v
+}
+
 func (m *systemdUnitManager) readUnit(name string) (string, error) {
 	path := m.getUnitFilePath(name)
 	contents, err := ioutil.ReadFile(path)
@@ -238,6 +292,7 @@ func (m *systemdUnitManager) GetUnitStates(filter pkg.Set) (map[string]*unit.Uni
 		if h, ok := m.hashes[dus.Name]; ok {
 			us.UnitHash = h.String()
 		}
+		m.getUnitDetails(dus.Name, us)
 		states[dus.Name] = us
 	}
 
@@ -256,6 +311,7 @@ func (m *systemdUnitManager) GetUnitStates(filter pkg.Set) (map[string]*unit.Uni
 			if h, ok := m.hashes[name]; ok {
 				us.UnitHash = h.String()
 			}
+			m.getUnitDetails(name, us)
 			states[name] = us
 		}
 	}
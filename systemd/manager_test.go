@@ -16,10 +16,13 @@ package systemd
 
 import (
 	"io/ioutil"
+	"math"
 	"os"
 	"path"
 	"reflect"
 	"testing"
+
+	"github.com/cea-hpc/fleet/unit"
 )
 
 func TestHashUnitFile(t *testing.T) {
@@ -111,3 +114,73 @@ func TestHashUnitFileDirectory(t *testing.T) {
 		t.Fatalf("hashUnitFileDirectory returned unexpected values: want=%v, got=%v", want, got)
 	}
 }
+
+func TestSetUnitDetails(t *testing.T) {
+	tests := []struct {
+		unitProps    map[string]interface{}
+		serviceProps map[string]interface{}
+		want         unit.UnitState
+	}{
+		{
+			unitProps: map[string]interface{}{
+				"ActiveEnterTimestamp":   uint64(1500000000000000),
+				"InactiveEnterTimestamp": uint64(1500000060000000),
+			},
+			serviceProps: map[string]interface{}{
+				"ExecMainStatus": int32(203),
+				"NRestarts":      uint32(4),
+			},
+			want: unit.UnitState{
+				ActiveState:            "failed",
+				ExecMainStatus:         203,
+				NRestarts:              4,
+				ActiveEnterTimestamp:   1500000000000000,
+				InactiveEnterTimestamp: 1500000060000000,
+			},
+		},
+		// usage not accounted for is left zero
+		{
+			unitProps: map[string]interface{}{},
+			serviceProps: map[string]interface{}{
+				"CPUUsageNSec":  uint64(1500000000),
+				"MemoryCurrent": uint64(math.MaxUint64),
+				"TasksCurrent":  uint64(12),
+			},
+			want: unit.UnitState{
+				ActiveState:  "failed",
+				CPUUsageNSec: 1500000000,
+				TasksCurrent: 12,
+			},
+		},
+		// units other than services, and older versions of systemd
+		{
+			unitProps: map[string]interface{}{
+				"ActiveEnterTimestamp":   uint64(1500000000000000),
+				"InactiveEnterTimestamp": uint64(0),
+			},
+			serviceProps: nil,
+			want: unit.UnitState{
+				ActiveState:          "failed",
+				ActiveEnterTimestamp: 1500000000000000,
+			},
+		},
+		{
+			unitProps: map[string]interface{}{},
+			serviceProps: map[string]interface{}{
+				"ExecMainStatus": int32(1),
+			},
+			want: unit.UnitState{
+				ActiveState:    "failed",
+				ExecMainStatus: 1,
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		us := unit.UnitState{ActiveState: "failed"}
+		setUnitDetails(&us, tt.unitProps, tt.serviceProps)
+		if !reflect.DeepEqual(tt.want, us) {
+			t.Errorf("case %d: got %#v, want %#v", i, us, tt.want)
+		}
+	}
+}
@@ -34,8 +34,17 @@ func NewUnitStateGenerator(mgr UnitManager) *UnitStateGenerator {
 	}
 }
 
+// HealthReporter reports the health of units as determined by their
+// health checks.
+type HealthReporter interface {
+	// Healthy returns nil if the unit has no health check or if its
+	// result is not known yet.
+	Healthy(name string) *bool
+}
+
 type UnitStateGenerator struct {
-	mgr UnitManager
+	mgr    UnitManager
+	health HealthReporter
 
 	subscribed     pkg.Set
 	lastSubscribed pkg.Set
@@ -93,6 +102,9 @@ func (g *UnitStateGenerator) Generate() (<-chan *UnitStateHeartbeat, error) {
 	go func() {
 		for name, us := range reportable {
 			us := us
+			if g.health != nil {
+				us.Healthy = g.health.Healthy(name)
+			}
 			beatchan <- &UnitStateHeartbeat{
 				Name:  name,
 				State: us,
@@ -116,6 +128,12 @@ func (g *UnitStateGenerator) Generate() (<-chan *UnitStateHeartbeat, error) {
 	return beatchan, nil
 }
 
+// SetHealthReporter makes the generated UnitStates carry the health of
+// their units, as reported by h.
+func (g *UnitStateGenerator) SetHealthReporter(h HealthReporter) {
+	g.health = h
+}
+
 // Subscribe adds a unit to the internal state filter
 func (g *UnitStateGenerator) Subscribe(name string) {
 	g.subscribed.Add(name)
@@ -49,7 +49,7 @@ func TestUnitStateGeneratorSubscribeLifecycle(t *testing.T) {
 
 	// subscribed to foo.service so we should get a heartbeat
 	expect := []UnitStateHeartbeat{
-		UnitStateHeartbeat{Name: "foo.service", State: &UnitState{"loaded", "active", "running", "", "", "foo.service"}},
+		UnitStateHeartbeat{Name: "foo.service", State: &UnitState{"loaded", "active", "running", "", "", "foo.service", nil, 0, 0, 0, 0, 0, 0, 0, 0}},
 	}
 	assertGenerateUnitStateHeartbeats(t, um, gen, expect)
 
@@ -65,6 +65,42 @@ func TestUnitStateGeneratorSubscribeLifecycle(t *testing.T) {
 	assertGenerateUnitStateHeartbeats(t, um, gen, []UnitStateHeartbeat{})
 }
 
+type fakeHealthReporter map[string]bool
+
+func (f fakeHealthReporter) Healthy(name string) *bool {
+	if healthy, ok := f[name]; ok {
+		return &healthy
+	}
+	return nil
+}
+
+func TestUnitStateGeneratorHealth(t *testing.T) {
+	um := NewFakeUnitManager()
+	um.Load("foo.service", UnitFile{})
+	um.Load("bar.service", UnitFile{})
+
+	gen := NewUnitStateGenerator(um)
+	gen.SetHealthReporter(fakeHealthReporter{"foo.service": false})
+	gen.Subscribe("foo.service")
+	gen.Subscribe("bar.service")
+
+	beatchan, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate(): %v", err)
+	}
+	got := make(map[string]*bool)
+	for beat := range beatchan {
+		got[beat.Name] = beat.State.Healthy
+	}
+
+	if healthy := got["foo.service"]; healthy == nil || *healthy {
+		t.Errorf("Expected foo.service to be unhealthy, got %v", healthy)
+	}
+	if healthy := got["bar.service"]; healthy != nil {
+		t.Errorf("Expected no health for bar.service, got %v", *healthy)
+	}
+}
+
 func TestUnitStateGeneratorNoState(t *testing.T) {
 	um := NewFakeUnitManager()
 	gen := NewUnitStateGenerator(um)
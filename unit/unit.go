@@ -201,6 +201,32 @@ type UnitState struct {
 	MachineID   string
 	UnitHash    string
 	UnitName    string
+
+	// Healthy is the result of the health check of the unit, nil if it
+	// has none or if its result is not known yet.
+	Healthy *bool `json:",omitempty"`
+	// Failures is the number of times the unit entered the failed state
+	// since it was loaded on its machine.
+	Failures int `json:",omitempty"`
+
+	// ExecMainStatus is the exit status of the main process of the unit,
+	// and NRestarts the number of automatic restarts of the unit, as
+	// reported by systemd for services.
+	ExecMainStatus int `json:",omitempty"`
+	NRestarts      int `json:",omitempty"`
+	// ActiveEnterTimestamp and InactiveEnterTimestamp are the times the
+	// unit last entered the active and inactive states, in microseconds
+	// since the epoch as reported by systemd, zero if it never did.
+	ActiveEnterTimestamp   uint64 `json:",omitempty"`
+	InactiveEnterTimestamp uint64 `json:",omitempty"`
+
+	// CPUUsageNSec, MemoryCurrent and TasksCurrent are the CPU time in
+	// nanoseconds, the memory in bytes and the number of tasks used by
+	// the unit, as accounted by systemd in its control group. They are
+	// zero if accounting is not enabled for the unit.
+	CPUUsageNSec  uint64 `json:",omitempty"`
+	MemoryCurrent uint64 `json:",omitempty"`
+	TasksCurrent  uint64 `json:",omitempty"`
 }
 
 func NewUnitState(loadState, activeState, subState, mID string) *UnitState {
@@ -32,12 +32,25 @@ func (unitFile *UnitFile) ToPB() pb.UnitFile {
 }
 
 func (s UnitState) ToPB() *pb.UnitState {
-	return &pb.UnitState{
-		Name:        s.UnitName,
-		Hash:        s.UnitHash,
-		LoadState:   s.LoadState,
-		ActiveState: s.ActiveState,
-		SubState:    s.SubState,
-		MachineID:   s.MachineID,
+	us := &pb.UnitState{
+		Name:                   s.UnitName,
+		Hash:                   s.UnitHash,
+		LoadState:              s.LoadState,
+		ActiveState:            s.ActiveState,
+		SubState:               s.SubState,
+		MachineID:              s.MachineID,
+		Failures:               int32(s.Failures),
+		ExecMainStatus:         int32(s.ExecMainStatus),
+		NRestarts:              uint32(s.NRestarts),
+		ActiveEnterTimestamp:   s.ActiveEnterTimestamp,
+		InactiveEnterTimestamp: s.InactiveEnterTimestamp,
+		CPUUsageNSec:           s.CPUUsageNSec,
+		MemoryCurrent:          s.MemoryCurrent,
+		TasksCurrent:           s.TasksCurrent,
 	}
+	if s.Healthy != nil {
+		us.HealthChecked = true
+		us.Healthy = *s.Healthy
+	}
+	return us
 }
@@ -77,3 +77,69 @@ func TestUnitStateProtoBuf(t *testing.T) {
 		t.Fatalf("got %#v, expected %#v", got, expect)
 	}
 }
+
+func TestUnitStateHealthProtoBuf(t *testing.T) {
+	for _, healthy := range []bool{true, false} {
+		healthy := healthy
+		us := &UnitState{UnitName: "foo", Healthy: &healthy}
+
+		got := us.ToPB()
+		if !got.HealthChecked || got.Healthy != healthy {
+			t.Errorf("Unexpected health %t/%t, expected %t", got.HealthChecked, got.Healthy, healthy)
+		}
+
+		// the health survives the wire
+		data, err := got.Marshal()
+		if err != nil {
+			t.Fatalf("Unexpected error marshalling %#v: %v", got, err)
+		}
+		var back pb.UnitState
+		if err := back.Unmarshal(data); err != nil {
+			t.Fatalf("Unexpected error unmarshalling %#v: %v", got, err)
+		}
+		if !reflect.DeepEqual(&back, got) {
+			t.Errorf("got %#v, expected %#v", &back, got)
+		}
+	}
+
+	if got := (&UnitState{UnitName: "foo"}).ToPB(); got.HealthChecked {
+		t.Errorf("Unexpected health for a unit without health check: %#v", got)
+	}
+}
+
+func TestUnitStateDetailsProtoBuf(t *testing.T) {
+	us := &UnitState{
+		UnitName:               "foo",
+		ActiveState:            "failed",
+		Failures:               300,
+		ExecMainStatus:         -1,
+		NRestarts:              5,
+		ActiveEnterTimestamp:   1500000000000000,
+		InactiveEnterTimestamp: 1500000060000000,
+		CPUUsageNSec:           1500000000,
+		MemoryCurrent:          1 << 40,
+		TasksCurrent:           200,
+	}
+	got := us.ToPB()
+	if got.Failures != 300 || got.ExecMainStatus != -1 || got.NRestarts != 5 {
+		t.Errorf("Unexpected failures %d, exit status %d and restarts %d", got.Failures, got.ExecMainStatus, got.NRestarts)
+	}
+	if got.ActiveEnterTimestamp != us.ActiveEnterTimestamp || got.InactiveEnterTimestamp != us.InactiveEnterTimestamp {
+		t.Errorf("Unexpected timestamps %d and %d", got.ActiveEnterTimestamp, got.InactiveEnterTimestamp)
+	}
+	if got.CPUUsageNSec != us.CPUUsageNSec || got.MemoryCurrent != us.MemoryCurrent || got.TasksCurrent != us.TasksCurrent {
+		t.Errorf("Unexpected usage %d, %d and %d", got.CPUUsageNSec, got.MemoryCurrent, got.TasksCurrent)
+	}
+
+	data, err := got.Marshal()
+	if err != nil {
+		t.Fatalf("Unexpected error marshalling %#v: %v", got, err)
+	}
+	var back pb.UnitState
+	if err := back.Unmarshal(data); err != nil {
+		t.Fatalf("Unexpected error unmarshalling %#v: %v", got, err)
+	}
+	if !reflect.DeepEqual(&back, got) {
+		t.Errorf("got %#v, expected %#v", &back, got)
+	}
+}